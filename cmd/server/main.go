@@ -2,87 +2,104 @@ package main
 
 import (
 	"context"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
-	"strings"
 	"syscall"
 	"time"
 
+	"mockj-go/internal/callback"
 	"mockj-go/internal/config"
 	"mockj-go/internal/database"
+	"mockj-go/internal/events"
 	"mockj-go/internal/handlers"
+	"mockj-go/internal/logging"
 	"mockj-go/internal/middleware"
+	"mockj-go/internal/seed"
+	"mockj-go/internal/storage"
+	"mockj-go/internal/webhook"
 )
 
 func main() {
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
+		fatal("Failed to load configuration", "error", err)
 	}
 
+	// Structured logs; the standard logger writes through them as well
+	slog.SetDefault(logging.New(os.Stderr, cfg.Log))
+
 	// Initialize database
 	db, err := database.NewDatabase(cfg.Database.DataSourceName)
 	if err != nil {
-		log.Fatalf("Failed to initialize database: %s %v", cfg.Database.DataSourceName, err)
+		fatal("Failed to initialize database", "data_source", cfg.Database.DataSourceName, "error", err)
 	}
 	defer db.Close()
 
-	// Start cleanup routine
-	go startCleanupRoutine(db, cfg.Database.CleanupInterval)
-
-	// Initialize handlers
-	jsonHandler := handlers.NewJSONHandler(db)
+	// Mock lifecycle events, published by the handlers, the seeder and the cleanup routine
+	bus := events.NewBus()
 
-	// Setup router
-	mux := http.NewServeMux()
-
-	// API routes (must be registered before static files)
-	mux.HandleFunc("POST /api/json", jsonHandler.CreateJSON)
-	mux.HandleFunc("GET /api/json/{id}", jsonHandler.GetJSON)
-	mux.HandleFunc("GET /api/json/{id}/content", jsonHandler.GetJSONContent)
-	mux.HandleFunc("PUT /api/json/{id}", jsonHandler.UpdateJSON)
-	mux.HandleFunc("DELETE /api/json/{id}", jsonHandler.DeleteJSON)
-
-	// Health check
-	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte("OK"))
-	})
-
-	// SPA fallback handler
-	spaHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		path := "./web/dist" + r.URL.Path
-
-		// Check if the requested file exists
-		if _, err := os.Stat(path); os.IsNotExist(err) {
-			// For API routes or non-existent paths, serve index.html for SPA
-			if !strings.HasPrefix(r.URL.Path, "/api/") {
-				http.ServeFile(w, r, "./web/dist/index.html")
-				return
-			}
-			http.NotFound(w, r)
-			return
+	// Load fixtures; invalid ones fail startup so CI notices them
+	if cfg.Seed.Dir != "" {
+		seeder, err := seed.NewSeeder(db, bus, cfg.Seed)
+		if err != nil {
+			fatal("Failed to initialize seeding", "error", err)
+		}
+		if err := seeder.Sync(context.Background()); err != nil {
+			fatal("Failed to seed mocks", "dir", cfg.Seed.Dir, "error", err)
+		}
+		if cfg.Seed.Watch {
+			go startSeedWatchRoutine(seeder, cfg.Seed.PollInterval)
 		}
+	}
+
+	store, err := storage.New(db, cfg.Attachment)
+	if err != nil {
+		fatal("Failed to initialize attachment storage", "error", err)
+	}
+
+	// Start cleanup routine
+	go startCleanupRoutine(db, store, bus, cfg.Database.CleanupInterval)
+	go startJournalCleanupRoutine(db, cfg.Journal)
+	go startWebhookCleanupRoutine(db, cfg.Webhook.Retention, cfg.Database.CleanupInterval)
 
-		// Serve the requested file
-		http.FileServer(http.Dir("./web/dist")).ServeHTTP(w, r)
-	})
+	// Deliver lifecycle events to subscribed webhooks
+	go webhook.NewDispatcher(db, cfg.Webhook).Run(bus)
 
-	// Static files (web frontend) with SPA fallback
-	mux.Handle("/", spaHandler)
+	// Workers sending the callbacks of mock hits
+	callbacks := callback.NewPool(db, cfg.Callback)
+
+	// Setup router
+	mux, closeWebSockets, err := newRouter(cfg, db, store, bus, callbacks)
+	if err != nil {
+		fatal("Failed to setup router", "error", err)
+	}
 
 	// Apply middleware
 	handler := middleware.Logging(mux)
-	handler = middleware.CORS(handler)
-	handler = middleware.ContentType(handler)
+	cors, err := middleware.CORS(cfg.CORS, handlers.MockCORS(db))
+	if err != nil {
+		fatal("Failed to setup CORS", "error", err)
+	}
+	handler = cors(handler)
 
 	if cfg.RateLimit.Enabled {
-		handler = middleware.RateLimit(cfg.RateLimit.Requests, cfg.RateLimit.Window)(handler)
+		limiter, err := middleware.NewLimiter(db, cfg.RateLimit)
+		if err != nil {
+			fatal("Failed to setup rate limiting", "error", err)
+		}
+		rateLimit, err := middleware.RateLimit(cfg.RateLimit, limiter)
+		if err != nil {
+			fatal("Failed to setup rate limiting", "error", err)
+		}
+		handler = rateLimit(handler)
 	}
 
+	// Outermost, so every middleware logs with the request ID
+	handler = middleware.RequestID(handler)
+
 	// Create HTTP server
 	server := &http.Server{
 		Addr:         cfg.ServerAddr(),
@@ -91,12 +108,14 @@ func main() {
 		WriteTimeout: cfg.Server.WriteTimeout,
 		IdleTimeout:  cfg.Server.IdleTimeout,
 	}
+	// Shutdown does not wait for hijacked connections, so close them explicitly
+	server.RegisterOnShutdown(closeWebSockets)
 
 	// Start server in a goroutine
 	go func() {
-		log.Printf("Starting server on %s", cfg.ServerAddr())
+		slog.Info("Starting server", "addr", cfg.ServerAddr())
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Failed to start server: %v", err)
+			fatal("Failed to start server", "error", err)
 		}
 	}()
 
@@ -104,7 +123,7 @@ func main() {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
-	log.Println("Shutting down server...")
+	slog.Info("Shutting down server")
 
 	// Create a deadline for shutdown
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -112,19 +131,77 @@ func main() {
 
 	// Attempt graceful shutdown
 	if err := server.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+		fatal("Server forced to shutdown", "error", err)
 	}
+	callbacks.Close()
 
-	log.Println("Server exited")
+	slog.Info("Server exited")
 }
 
-func startCleanupRoutine(db *database.Database, interval time.Duration) {
+func startCleanupRoutine(db *database.Database, store storage.Store, bus *events.Bus, interval time.Duration) {
+	ctx := context.Background()
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	for range ticker.C {
-		if err := db.CleanupExpired(); err != nil {
-			log.Printf("Failed to cleanup expired records: %v", err)
+		expired, err := db.CleanupExpired(ctx)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to cleanup expired records", "error", err)
+		}
+		for _, id := range expired {
+			bus.Publish(events.Expired, id, nil)
+		}
+		// Files of attachments deleted with their mock are left for the sweep
+		if err := store.Sweep(ctx); err != nil {
+			slog.ErrorContext(ctx, "Failed to sweep attachment storage", "error", err)
 		}
 	}
 }
+
+func startJournalCleanupRoutine(db *database.Database, cfg config.JournalConfig) {
+	ctx := context.Background()
+	ticker := time.NewTicker(cfg.CleanupInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := db.CleanupJournal(ctx, cfg.Retention, cfg.MaxEntries); err != nil {
+			slog.ErrorContext(ctx, "Failed to cleanup request journal", "error", err)
+		}
+		// Callback results share the journal's retention
+		if cfg.Retention > 0 {
+			if err := db.CleanupCallbackResults(ctx, cfg.Retention); err != nil {
+				slog.ErrorContext(ctx, "Failed to cleanup callback results", "error", err)
+			}
+		}
+	}
+}
+
+func startWebhookCleanupRoutine(db *database.Database, retention, interval time.Duration) {
+	ctx := context.Background()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := db.CleanupDeliveries(ctx, retention); err != nil {
+			slog.ErrorContext(ctx, "Failed to cleanup webhook deliveries", "error", err)
+		}
+	}
+}
+
+func startSeedWatchRoutine(seeder *seed.Seeder, interval time.Duration) {
+	ctx := context.Background()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := seeder.Sync(ctx); err != nil {
+			slog.ErrorContext(ctx, "Failed to reload seeded mocks", "error", err)
+		}
+	}
+}
+
+// fatal logs a startup or serving failure and exits
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+
+	"mockj-go/internal/callback"
+	"mockj-go/internal/config"
+	"mockj-go/internal/database"
+	"mockj-go/internal/events"
+	"mockj-go/internal/handlers"
+	"mockj-go/internal/middleware"
+	"mockj-go/internal/storage"
+)
+
+// newRouter registers every route of the server. API routes must stay in sync
+// with handlers.APIOperations, which describes them in /api/openapi.json.
+// The returned function closes the WebSocket connections the server does not track.
+func newRouter(cfg *config.Config, db *database.Database, store storage.Store, bus *events.Bus, callbacks *callback.Pool) (*http.ServeMux, func(), error) {
+	// Initialize handlers
+	jsonHandler := handlers.NewJSONHandler(db, bus, callbacks)
+
+	mux := http.NewServeMux()
+
+	// Bulk export and import, and the changes of every mock, which reveal as much
+	var transferHandler *handlers.TransferHandler
+	if cfg.Transfer.Password != "" {
+		var err error
+		transferHandler, err = handlers.NewTransferHandler(jsonHandler, cfg.Transfer)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to initialize transfer: %w", err)
+		}
+	}
+
+	// Subscribing webhooks to every mock takes the transfer password
+	createWebhook := jsonHandler.CreateWebhook
+	if transferHandler != nil {
+		createWebhook = transferHandler.CreateWebhook
+	}
+
+	// API routes (must be registered before static files). Those decoding a JSON body
+	// require it to be declared as such.
+	mux.HandleFunc("POST /api/json", middleware.JSONBody(jsonHandler.CreateJSON))
+	mux.HandleFunc("GET /api/json/{id}", jsonHandler.GetJSON)
+	mux.HandleFunc("GET /api/json/{id}/content", jsonHandler.GetJSONContent)
+	mux.HandleFunc("PUT /api/json/{id}/content", jsonHandler.UploadJSONContent)
+	mux.HandleFunc("PUT /api/json/{id}", middleware.JSONBody(jsonHandler.UpdateJSON))
+	mux.HandleFunc("DELETE /api/json/{id}", jsonHandler.DeleteJSON)
+	mux.HandleFunc("GET /api/json/{id}/requests", jsonHandler.ListRequests)
+	mux.HandleFunc("DELETE /api/json/{id}/requests", jsonHandler.ClearRequests)
+	mux.HandleFunc("GET /api/json/{id}/callbacks", jsonHandler.ListCallbacks)
+	mux.HandleFunc("GET /api/json/{id}/schema", jsonHandler.GetJSONSchema)
+	mux.HandleFunc("GET /api/json/{id}/types", jsonHandler.GetJSONTypes)
+	mux.HandleFunc("GET /api/json/{id}/graphql", jsonHandler.ServeGraphQL)
+	mux.HandleFunc("POST /api/json/{id}/graphql", jsonHandler.ServeGraphQL)
+	mux.HandleFunc("GET /api/json/{id}/ws", jsonHandler.ServeWebSocket)
+	mux.HandleFunc("GET /api/websockets", jsonHandler.ListWebSocketConnections)
+	mux.HandleFunc("DELETE /api/websockets/{connectionId}", jsonHandler.CloseWebSocketConnection)
+	mux.HandleFunc("POST /api/webhooks", middleware.JSONBody(createWebhook))
+	mux.HandleFunc("GET /api/webhooks/{webhookId}", jsonHandler.GetWebhook)
+	mux.HandleFunc("DELETE /api/webhooks/{webhookId}", jsonHandler.DeleteWebhook)
+	mux.HandleFunc("GET /api/webhooks/{webhookId}/deliveries", jsonHandler.ListWebhookDeliveries)
+
+	// Streams extend their write deadline per event instead of using the server's
+	streamHandler := handlers.NewStreamHandler(jsonHandler, cfg.Server)
+	mux.HandleFunc("GET /api/json/{id}/stream", streamHandler.StreamJSON)
+	mux.HandleFunc("GET /api/json/{id}/watch", streamHandler.WatchJSON)
+
+	attachmentHandler := handlers.NewAttachmentHandler(jsonHandler, store, cfg.Attachment)
+	mux.HandleFunc("POST /api/json/{id}/attachments", attachmentHandler.UploadAttachment)
+	mux.HandleFunc("GET /api/json/{id}/attachments", attachmentHandler.ListAttachments)
+	mux.HandleFunc("GET /api/json/{id}/attachments/{attachmentId}", attachmentHandler.GetAttachment)
+	mux.HandleFunc("DELETE /api/json/{id}/attachments/{attachmentId}", attachmentHandler.DeleteAttachment)
+
+	mux.HandleFunc("POST /api/verify", middleware.JSONBody(jsonHandler.Verify))
+	mux.HandleFunc("POST /api/import/openapi", middleware.JSONBody(jsonHandler.ImportOpenAPI))
+	mux.HandleFunc("POST /api/generate", middleware.JSONBody(jsonHandler.Generate))
+	mux.HandleFunc("GET /api/openapi.json", jsonHandler.GetOpenAPI)
+
+	// Record-and-playback proxy
+	if cfg.Proxy.Target != "" {
+		proxyHandler, err := handlers.NewProxyHandler(jsonHandler, cfg.Proxy)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to initialize proxy: %w", err)
+		}
+		mux.HandleFunc("GET /api/proxy", proxyHandler.GetProxy)
+		mux.HandleFunc("PUT /api/proxy/mode", middleware.JSONBody(proxyHandler.SetProxyMode))
+		mux.Handle(cfg.Proxy.Prefix+"/", proxyHandler)
+		slog.Info("Proxying", "prefix", cfg.Proxy.Prefix+"/", "target", cfg.Proxy.Target, "mode", cfg.Proxy.Mode)
+	}
+
+	if transferHandler != nil {
+		mux.HandleFunc("GET /api/export", transferHandler.Export)
+		mux.HandleFunc("POST /api/import", transferHandler.Import)
+		mux.HandleFunc("GET /api/events", transferHandler.RequireTransferPassword(streamHandler.StreamEvents))
+	}
+
+	// Health check
+	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("OK"))
+	})
+
+	// Passthrough proxy for requests no mock handles
+	var fallbackHandler *handlers.FallbackHandler
+	if cfg.Fallback.Target != "" {
+		var err error
+		fallbackHandler, err = handlers.NewFallbackHandler(cfg.Fallback)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to initialize fallback proxy: %w", err)
+		}
+		slog.Info("Forwarding unmatched requests", "target", cfg.Fallback.Target)
+	}
+
+	// SPA fallback handler
+	spaHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Mocks bound to a route take precedence over static files
+		if jsonHandler.ServeRoute(w, r) {
+			return
+		}
+
+		path := "./web/dist" + r.URL.Path
+
+		// Check if the requested file exists
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			// Unmatched requests go to the upstream when one is configured
+			if fallbackHandler != nil {
+				fallbackHandler.ServeHTTP(w, r)
+				return
+			}
+
+			// For API routes or non-existent paths, serve index.html for SPA
+			if !strings.HasPrefix(r.URL.Path, "/api/") {
+				http.ServeFile(w, r, "./web/dist/index.html")
+				return
+			}
+			http.NotFound(w, r)
+			return
+		}
+
+		// Serve the requested file
+		http.FileServer(http.Dir("./web/dist")).ServeHTTP(w, r)
+	})
+
+	// Static files (web frontend) with SPA fallback
+	mux.Handle("/", spaHandler)
+
+	return mux, jsonHandler.CloseWebSockets, nil
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"mockj-go/internal/config"
+	"mockj-go/internal/database"
+	"mockj-go/internal/events"
+	"mockj-go/internal/handlers"
+	"mockj-go/internal/openapi"
+	"mockj-go/internal/storage"
+)
+
+// TestOpenAPIMatchesRouter fails when the routes registered by newRouter and the
+// operations described in /api/openapi.json drift apart
+func TestOpenAPIMatchesRouter(t *testing.T) {
+	db, err := database.NewDatabase(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	// Enable every optional route
+	cfg := &config.Config{
+		Proxy: config.ProxyConfig{
+			Target:   "http://127.0.0.1:1",
+			Mode:     config.ProxyModeRecord,
+			Prefix:   "/proxy",
+			Password: "test123",
+		},
+		Transfer: config.TransferConfig{Password: "test123"},
+	}
+
+	store, err := storage.New(db, cfg.Attachment)
+	if err != nil {
+		t.Fatalf("Failed to create attachment store: %v", err)
+	}
+
+	mux, _, err := newRouter(cfg, db, store, events.NewBus(), nil)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	documented := map[string]bool{}
+
+	t.Run("DocumentedOperationsAreRouted", func(t *testing.T) {
+		for _, op := range handlers.APIOperations() {
+			pattern := op.Method + " " + op.Path
+			documented[pattern] = true
+
+			path := op.Path
+			for _, segment := range strings.Split(op.Path, "/") {
+				if strings.HasPrefix(segment, "{") {
+					path = strings.Replace(path, segment, "test-id", 1)
+				}
+			}
+
+			_, matched := mux.Handler(httptest.NewRequest(op.Method, path, nil))
+			if matched != pattern {
+				t.Errorf("Documented operation %s is routed to %q", pattern, matched)
+			}
+		}
+	})
+
+	t.Run("RoutedOperationsAreDocumented", func(t *testing.T) {
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, "router.go", nil, 0)
+		if err != nil {
+			t.Fatalf("Failed to parse router.go: %v", err)
+		}
+
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) == 0 {
+				return true
+			}
+			selector, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok || (selector.Sel.Name != "HandleFunc" && selector.Sel.Name != "Handle") {
+				return true
+			}
+			literal, ok := call.Args[0].(*ast.BasicLit)
+			if !ok || literal.Kind != token.STRING {
+				return true
+			}
+
+			pattern, _ := strconv.Unquote(literal.Value)
+			if _, path, ok := strings.Cut(pattern, " "); ok && strings.HasPrefix(path, "/api/") && !documented[pattern] {
+				t.Errorf("Route %s at %s is missing from handlers.APIOperations", pattern, fset.Position(literal.Pos()))
+			}
+			return true
+		})
+	})
+
+	t.Run("JSONBodyPerRoute", func(t *testing.T) {
+		// Only routes decoding a JSON body reject other types; the rest check their own
+		tests := []struct {
+			method, path string
+			rejected     bool
+		}{
+			{"POST", "/api/json", true},
+			{"PUT", "/api/proxy/mode", true},
+			{"PUT", "/api/json/test-id/content", false},
+			{"POST", "/api/json/test-id/graphql", false},
+			{"POST", "/proxy/anything", false},
+		}
+		for _, tt := range tests {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader("plain"))
+			req.Header.Set("Content-Type", "text/plain")
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, req)
+			if rejected := w.Code == http.StatusUnsupportedMediaType; rejected != tt.rejected {
+				t.Errorf("%s %s: expected rejected=%v, got status %d", tt.method, tt.path, tt.rejected, w.Code)
+			}
+		}
+	})
+
+	t.Run("SchemaReferencesResolve", func(t *testing.T) {
+		doc := openapi.Document("test", "test", handlers.APIOperations())
+		schemas := doc["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+
+		var check func(v interface{})
+		check = func(v interface{}) {
+			switch value := v.(type) {
+			case map[string]interface{}:
+				if ref, ok := value["$ref"].(string); ok {
+					name := strings.TrimPrefix(ref, "#/components/schemas/")
+					if _, ok := schemas[name]; !ok {
+						t.Errorf("Unresolved schema reference %s", ref)
+					}
+				}
+				for _, item := range value {
+					check(item)
+				}
+			case []interface{}:
+				for _, item := range value {
+					check(item)
+				}
+			}
+		}
+		check(doc)
+
+		for _, name := range []string{"CreateJSONRequest", "UpdateJSONRequest", "SuccessResponse", "ErrorResponse", "JSON"} {
+			if _, ok := schemas[name]; !ok {
+				t.Errorf("Expected schema %s in the document", name)
+			}
+		}
+	})
+}
@@ -7,4 +7,6 @@ require (
 	github.com/mattn/go-sqlite3 v1.14.33
 )
 
-require golang.org/x/crypto v0.46.0 // indirect
+require golang.org/x/crypto v0.46.0
+
+require gopkg.in/yaml.v3 v3.0.1
@@ -0,0 +1,150 @@
+// Package callback sends the requests mocks make after serving a hit, from a fixed pool
+// of workers, and logs their outcome.
+package callback
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"mockj-go/internal/config"
+	"mockj-go/internal/database"
+	"mockj-go/internal/models"
+	"mockj-go/internal/outbound"
+)
+
+// Request is a callback with its templates filled in
+type Request struct {
+	MockID  string
+	Method  string
+	URL     string
+	Body    string
+	Headers map[string]string
+	Delay   time.Duration
+
+	ctx context.Context // Carries the values of the hit, such as its request ID, to the logs
+}
+
+// Pool runs callback requests on a fixed number of workers. Requests that arrive while
+// QueueSize others are queued or waiting for their delay are dropped and logged as such.
+type Pool struct {
+	db     *database.Database
+	client *http.Client
+	slots  chan struct{} // Holds a token per queued or delayed request
+	queue  chan Request
+	done   chan struct{}
+	wg     sync.WaitGroup
+	once   sync.Once
+}
+
+func NewPool(db *database.Database, cfg config.CallbackConfig) *Pool {
+	p := &Pool{
+		db:     db,
+		client: outbound.NewClient(cfg.Timeout, cfg.AllowPrivate),
+		slots:  make(chan struct{}, cfg.QueueSize),
+		queue:  make(chan Request, cfg.QueueSize),
+		done:   make(chan struct{}),
+	}
+	for i := 0; i < cfg.Workers; i++ {
+		p.wg.Add(1)
+		go p.work()
+	}
+	return p
+}
+
+// Submit queues a request once its delay has passed. It never blocks. The request outlives
+// ctx, of which it keeps only the values for logging.
+func (p *Pool) Submit(ctx context.Context, req Request) {
+	req.ctx = context.WithoutCancel(ctx)
+
+	select {
+	case p.slots <- struct{}{}:
+	default:
+		p.record(req, 0, "dropped: callback queue is full", 0)
+		return
+	}
+
+	if req.Delay > 0 {
+		time.AfterFunc(req.Delay, func() { p.enqueue(req) })
+		return
+	}
+	p.enqueue(req)
+}
+
+// Close stops the workers after their current requests. Queued and delayed requests are dropped.
+func (p *Pool) Close() {
+	p.once.Do(func() { close(p.done) })
+	p.wg.Wait()
+}
+
+// enqueue hands a request to the workers. Its slot guarantees room in the queue.
+func (p *Pool) enqueue(req Request) {
+	select {
+	case <-p.done:
+		return
+	default:
+	}
+	p.queue <- req
+}
+
+func (p *Pool) work() {
+	defer p.wg.Done()
+	for {
+		select {
+		case req := <-p.queue:
+			<-p.slots
+			p.send(req)
+		case <-p.done:
+			return
+		}
+	}
+}
+
+// send makes one callback request; callbacks are not retried
+func (p *Pool) send(req Request) {
+	start := time.Now()
+
+	httpReq, err := http.NewRequestWithContext(req.ctx, req.Method, req.URL, bytes.NewReader([]byte(req.Body)))
+	if err != nil {
+		p.record(req, 0, err.Error(), 0)
+		return
+	}
+	for name, value := range req.Headers {
+		httpReq.Header.Set(name, value)
+	}
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		p.record(req, 0, err.Error(), time.Since(start))
+		return
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	p.record(req, resp.StatusCode, "", time.Since(start))
+}
+
+func (p *Pool) record(req Request, status int, errorMessage string, duration time.Duration) {
+	if errorMessage != "" {
+		slog.WarnContext(req.ctx, "Callback failed", "mock_id", req.MockID, "method", req.Method, "url", req.URL, "error", errorMessage)
+	} else {
+		slog.InfoContext(req.ctx, "Callback sent", "mock_id", req.MockID, "method", req.Method, "url", req.URL, "status", status)
+	}
+
+	result := &models.CallbackResult{
+		MockID:     req.MockID,
+		Method:     req.Method,
+		URL:        req.URL,
+		Status:     status,
+		Error:      errorMessage,
+		DurationMs: duration.Milliseconds(),
+		Timestamp:  time.Now(),
+	}
+	if err := p.db.RecordCallback(req.ctx, result); err != nil {
+		slog.ErrorContext(req.ctx, "Failed to record callback", "mock_id", req.MockID, "error", err)
+	}
+}
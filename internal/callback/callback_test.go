@@ -0,0 +1,112 @@
+package callback
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"mockj-go/internal/config"
+	"mockj-go/internal/database"
+	"mockj-go/internal/models"
+	"mockj-go/internal/outbound"
+)
+
+func waitForResults(t *testing.T, db *database.Database, mockID string, count int) []*models.CallbackResult {
+	t.Helper()
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		results, err := db.ListCallbackResults(t.Context(), mockID, 100)
+		if err != nil {
+			t.Fatalf("Failed to list results: %v", err)
+		}
+		if len(results) >= count {
+			return results
+		}
+	}
+	t.Fatalf("Expected %d callback results", count)
+	return nil
+}
+
+func TestPool(t *testing.T) {
+	db, err := database.NewDatabase(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	t.Run("SendsAndLogs", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer t" {
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+		}))
+		defer server.Close()
+
+		pool := NewPool(db, config.CallbackConfig{Workers: 2, QueueSize: 10, Timeout: time.Second, AllowPrivate: true})
+		defer pool.Close()
+		pool.Submit(t.Context(), Request{MockID: "a", Method: "POST", URL: server.URL, Headers: map[string]string{"Authorization": "Bearer t"}})
+		pool.Submit(t.Context(), Request{MockID: "a", Method: "POST", URL: server.URL, Delay: 20 * time.Millisecond})
+		pool.Submit(t.Context(), Request{MockID: "a", Method: "POST", URL: "http://127.0.0.1:1"})
+
+		statuses := map[int]bool{}
+		for _, result := range waitForResults(t, db, "a", 3) {
+			statuses[result.Status] = true
+			if result.Status == 0 && result.Error == "" {
+				t.Errorf("Expected the connection error to be logged, got %+v", result)
+			}
+		}
+		if !statuses[http.StatusOK] || !statuses[http.StatusUnauthorized] || !statuses[0] {
+			t.Errorf("Expected a success, a rejection and an error, got %v", statuses)
+		}
+	})
+
+	t.Run("DropsWhenQueueIsFull", func(t *testing.T) {
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { <-release }))
+		defer server.Close()
+
+		pool := NewPool(db, config.CallbackConfig{Workers: 1, QueueSize: 1, Timeout: time.Second, AllowPrivate: true})
+		defer pool.Close()
+		defer close(release)
+		// One request busies the worker, one waits in the queue and the rest are dropped
+		for i := 0; i < 4; i++ {
+			pool.Submit(t.Context(), Request{MockID: "b", Method: "GET", URL: server.URL})
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		for _, result := range waitForResults(t, db, "b", 2) {
+			if result.Error != "dropped: callback queue is full" {
+				t.Errorf("Expected only dropped callbacks so far, got %+v", result)
+			}
+		}
+	})
+	t.Run("DelayedCountAgainstQueue", func(t *testing.T) {
+		pool := NewPool(db, config.CallbackConfig{Workers: 1, QueueSize: 2, Timeout: time.Second, AllowPrivate: true})
+		defer pool.Close()
+		for i := 0; i < 3; i++ {
+			pool.Submit(t.Context(), Request{MockID: "c", Method: "POST", URL: "http://127.0.0.1:1", Delay: time.Hour})
+		}
+
+		results := waitForResults(t, db, "c", 1)
+		if len(results) != 1 || results[0].Error != "dropped: callback queue is full" {
+			t.Errorf("Expected the third delayed callback to be dropped, got %+v", results)
+		}
+	})
+
+	t.Run("RefusesPrivateAddresses", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Error("Callback reached a loopback address")
+		}))
+		defer server.Close()
+
+		pool := NewPool(db, config.CallbackConfig{Workers: 1, QueueSize: 1, Timeout: time.Second})
+		defer pool.Close()
+		pool.Submit(t.Context(), Request{MockID: "d", Method: "POST", URL: server.URL})
+
+		results := waitForResults(t, db, "d", 1)
+		if results[0].Status != 0 || !strings.Contains(results[0].Error, outbound.ErrBlockedAddress.Error()) {
+			t.Errorf("Expected the callback to be refused, got %+v", results[0])
+		}
+	})
+}
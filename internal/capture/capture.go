@@ -0,0 +1,46 @@
+// Package capture wraps an http.ResponseWriter to record what a handler wrote, for the
+// access log and the request journal.
+package capture
+
+import "net/http"
+
+// Writer records the status and size of a response
+type Writer struct {
+	http.ResponseWriter
+	Status int   // The status sent, 0 until the header is written
+	Bytes  int64 // Body bytes written
+}
+
+func NewWriter(w http.ResponseWriter) *Writer {
+	return &Writer{ResponseWriter: w}
+}
+
+// StatusCode returns the status sent, 200 when the handler wrote nothing
+func (cw *Writer) StatusCode() int {
+	if cw.Status == 0 {
+		return http.StatusOK
+	}
+	return cw.Status
+}
+
+func (cw *Writer) WriteHeader(code int) {
+	// Only the first call reaches the client
+	if cw.Status == 0 {
+		cw.Status = code
+	}
+	cw.ResponseWriter.WriteHeader(code)
+}
+
+func (cw *Writer) Write(b []byte) (int, error) {
+	if cw.Status == 0 {
+		cw.Status = http.StatusOK
+	}
+	n, err := cw.ResponseWriter.Write(b)
+	cw.Bytes += int64(n)
+	return n, err
+}
+
+// Unwrap exposes the underlying writer so http.ResponseController can flush and hijack
+func (cw *Writer) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
@@ -0,0 +1,290 @@
+// Package codegen emits typed model definitions for JSON documents from their inferred JSON Schema.
+package codegen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Languages supported by Generate
+const (
+	Go         = "go"
+	TypeScript = "typescript"
+	Kotlin     = "kotlin"
+	Swift      = "swift"
+)
+
+// kind is the shape of a type reference
+type kind int
+
+const (
+	kindAny kind = iota
+	kindString
+	kindInteger
+	kindNumber
+	kindBoolean
+	kindArray
+	kindObject
+)
+
+// typeRef is a use of a type, e.g. a field's type
+type typeRef struct {
+	kind     kind
+	elem     *typeRef    // Element type of arrays
+	object   *objectType // Definition of objects
+	nullable bool
+}
+
+// objectType is a named record type
+type objectType struct {
+	name   string
+	fields []*field
+}
+
+type field struct {
+	jsonName string
+	typ      *typeRef
+	optional bool // Missing from some samples
+}
+
+// model holds every object type of a document, root first
+type model struct {
+	root    *typeRef
+	objects []*objectType
+	names   map[string]bool
+}
+
+// Generate emits type definitions in lang for a schema inferred by schema.Infer,
+// naming the root type rootName
+func Generate(lang, rootName string, s map[string]interface{}) (string, error) {
+	m := &model{names: map[string]bool{}}
+	rootName = typeName(rootName)
+	if rootName == "" {
+		rootName = "Root"
+	}
+
+	// Objects take the root name; any other root becomes an alias, e.g. to a list of its items
+	m.root = m.ref(s, rootName)
+
+	switch lang {
+	case Go:
+		return m.golang(rootName)
+	case TypeScript:
+		return m.typescript(rootName), nil
+	case Kotlin:
+		return m.kotlin(rootName), nil
+	case Swift:
+		return m.swift(rootName), nil
+	}
+	return "", fmt.Errorf("unsupported language %q: use go, typescript, kotlin or swift", lang)
+}
+
+// ref converts a schema into a type reference, registering objects under name
+func (m *model) ref(s map[string]interface{}, name string) *typeRef {
+	if s == nil {
+		return &typeRef{kind: kindAny}
+	}
+
+	var (
+		types    []string
+		nullable bool
+	)
+	switch t := s["type"].(type) {
+	case string:
+		types = []string{t}
+	case []interface{}:
+		for _, item := range t {
+			if item == "null" {
+				nullable = true
+			} else if name, ok := item.(string); ok {
+				types = append(types, name)
+			}
+		}
+	}
+	if len(types) != 1 {
+		// Unions and null-only values carry no usable static type
+		return &typeRef{kind: kindAny, nullable: nullable}
+	}
+
+	ref := &typeRef{nullable: nullable}
+	switch types[0] {
+	case "string":
+		ref.kind = kindString
+	case "integer":
+		ref.kind = kindInteger
+	case "number":
+		ref.kind = kindNumber
+	case "boolean":
+		ref.kind = kindBoolean
+	case "array":
+		ref.kind = kindArray
+		ref.elem = m.ref(arrayItems(s), singular(name))
+	case "object":
+		ref.kind = kindObject
+		ref.object = m.object(s, name)
+	default:
+		ref.kind = kindAny
+	}
+	return ref
+}
+
+// object registers an object type with a unique name and converts its fields
+func (m *model) object(s map[string]interface{}, name string) *objectType {
+	object := &objectType{name: uniqueName(name, m.names)}
+	m.objects = append(m.objects, object)
+
+	required := map[string]bool{}
+	if names, ok := s["required"].([]interface{}); ok {
+		for _, name := range names {
+			if name, ok := name.(string); ok {
+				required[name] = true
+			}
+		}
+	} else if names, ok := s["required"].([]string); ok {
+		for _, name := range names {
+			required[name] = true
+		}
+	}
+
+	properties, _ := s["properties"].(map[string]interface{})
+	jsonNames := make([]string, 0, len(properties))
+	for jsonName := range properties {
+		jsonNames = append(jsonNames, jsonName)
+	}
+	sort.Strings(jsonNames)
+
+	for _, jsonName := range jsonNames {
+		property, _ := properties[jsonName].(map[string]interface{})
+		fieldType := typeName(jsonName)
+		if fieldType == "" {
+			fieldType = "Field"
+		}
+		object.fields = append(object.fields, &field{
+			jsonName: jsonName,
+			typ:      m.ref(property, fieldType),
+			optional: !required[jsonName],
+		})
+	}
+
+	return object
+}
+
+// uniqueName takes name, or when it is taken name with the lowest free numeric suffix from 2
+func uniqueName(name string, taken map[string]bool) string {
+	unique := name
+	for i := 2; taken[unique]; i++ {
+		unique = fmt.Sprintf("%s%d", name, i)
+	}
+	taken[unique] = true
+	return unique
+}
+
+// fieldNames converts the JSON keys of an object's fields into identifiers with convert.
+// Keys converting to the same identifier, such as user_id and userId, are suffixed after the first.
+func fieldNames(object *objectType, convert func(string) string) []string {
+	taken := map[string]bool{}
+	names := make([]string, len(object.fields))
+	for i, f := range object.fields {
+		names[i] = uniqueName(convert(f.jsonName), taken)
+	}
+	return names
+}
+
+func arrayItems(s map[string]interface{}) map[string]interface{} {
+	items, _ := s["items"].(map[string]interface{})
+	return items
+}
+
+// words splits a JSON key like "created_at", "userID" or "first-name" into words
+func words(name string) []string {
+	var (
+		result  []string
+		current []rune
+	)
+	runes := []rune(name)
+	flush := func() {
+		if len(current) > 0 {
+			result = append(result, string(current))
+			current = nil
+		}
+	}
+
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+			continue
+		case unicode.IsUpper(r) && len(current) > 0:
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			// Split "userId" before I and "URLValue" before V
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				flush()
+			}
+		}
+		current = append(current, r)
+	}
+	flush()
+
+	return result
+}
+
+// typeName converts a JSON key into a PascalCase type name
+func typeName(name string) string {
+	var b strings.Builder
+	for _, word := range words(name) {
+		b.WriteString(capitalize(strings.ToLower(word)))
+	}
+
+	result := b.String()
+	if result != "" && unicode.IsDigit(rune(result[0])) {
+		result = "T" + result
+	}
+	return result
+}
+
+// camelName converts a JSON key into a camelCase property name
+func camelName(name string) string {
+	parts := words(name)
+	if len(parts) == 0 {
+		return "field"
+	}
+
+	var b strings.Builder
+	b.WriteString(strings.ToLower(parts[0]))
+	for _, word := range parts[1:] {
+		b.WriteString(capitalize(strings.ToLower(word)))
+	}
+
+	result := b.String()
+	if unicode.IsDigit(rune(result[0])) {
+		result = "_" + result
+	}
+	return result
+}
+
+func capitalize(word string) string {
+	if word == "" {
+		return word
+	}
+	runes := []rune(word)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+// singular guesses the singular of an English plural type name
+func singular(name string) string {
+	switch {
+	case strings.HasSuffix(name, "ies") && len(name) > 3:
+		return name[:len(name)-3] + "y"
+	case strings.HasSuffix(name, "sses"), strings.HasSuffix(name, "xes"), strings.HasSuffix(name, "ches"), strings.HasSuffix(name, "shes"):
+		return name[:len(name)-2]
+	case strings.HasSuffix(name, "ss"), strings.HasSuffix(name, "us"):
+		return name + "Item"
+	case strings.HasSuffix(name, "s") && len(name) > 1:
+		return name[:len(name)-1]
+	}
+	return name + "Item"
+}
@@ -0,0 +1,65 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCollidingFieldNames(t *testing.T) {
+	s := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"user_id": map[string]interface{}{"type": "integer"},
+			"userId":  map[string]interface{}{"type": "integer"},
+			"userID":  map[string]interface{}{"type": "string"},
+			"name":    map[string]interface{}{"type": "string"},
+		},
+		"required": []interface{}{"user_id", "userId", "userID", "name"},
+	}
+
+	// Keys are converted in sorted order: name, userID, userId, user_id
+	tests := []struct {
+		lang  string
+		lines []string
+	}{
+		{Go, []string{
+			"UserID  string `json:\"userID\"`",
+			"UserID2 int64  `json:\"userId\"`",
+			"UserID3 int64  `json:\"user_id\"`",
+			"Name    string `json:\"name\"`",
+		}},
+		{Kotlin, []string{
+			"@SerialName(\"userID\") val userId: String,",
+			"@SerialName(\"userId\") val userId2: Long,",
+			"@SerialName(\"user_id\") val userId3: Long,",
+			"    val name: String,",
+		}},
+		{Swift, []string{
+			"let userId: String\n",
+			"let userId2: Int\n",
+			"let userId3: Int\n",
+			"case userId = \"userID\"\n",
+			"case userId2 = \"userId\"\n",
+			"case userId3 = \"user_id\"\n",
+			"case name\n",
+		}},
+		{TypeScript, []string{
+			"  userID: string;",
+			"  userId: number;",
+			"  user_id: number;",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.lang, func(t *testing.T) {
+			source, err := Generate(tt.lang, "user", s)
+			if err != nil {
+				t.Fatalf("Expected source, got %v", err)
+			}
+			for _, line := range tt.lines {
+				if !strings.Contains(source, line) {
+					t.Errorf("Expected %q in\n%s", line, source)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package codegen
+
+import (
+	"fmt"
+	"go/format"
+	"strings"
+)
+
+// goInitialisms are kept upper case in Go identifiers, as golint expects
+var goInitialisms = map[string]bool{
+	"API": true, "CPU": true, "CSS": true, "DNS": true, "HTML": true, "HTTP": true, "HTTPS": true,
+	"ID": true, "IP": true, "JSON": true, "SQL": true, "TCP": true, "TLS": true, "TTL": true,
+	"UI": true, "URI": true, "URL": true, "UUID": true, "XML": true,
+}
+
+func (m *model) golang(rootName string) (string, error) {
+	var b strings.Builder
+	b.WriteString("package models\n")
+
+	if m.root.kind != kindObject {
+		fmt.Fprintf(&b, "\ntype %s %s\n", goName(rootName), m.goType(m.root, false))
+	}
+
+	for _, object := range m.objects {
+		fmt.Fprintf(&b, "\ntype %s struct {\n", goName(object.name))
+		names := fieldNames(object, goName)
+		for i, f := range object.fields {
+			tag := f.jsonName
+			if f.optional {
+				tag += ",omitempty"
+			}
+			fmt.Fprintf(&b, "\t%s %s `json:%q`\n", names[i], m.goType(f.typ, f.optional), tag)
+		}
+		b.WriteString("}\n")
+	}
+
+	source, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return "", fmt.Errorf("failed to format Go source: %w", err)
+	}
+	return string(source), nil
+}
+
+// goType renders a type, using a pointer when a scalar or struct may be absent or null
+func (m *model) goType(t *typeRef, optional bool) string {
+	var name string
+	switch t.kind {
+	case kindString:
+		name = "string"
+	case kindInteger:
+		name = "int64"
+	case kindNumber:
+		name = "float64"
+	case kindBoolean:
+		name = "bool"
+	case kindArray:
+		return "[]" + m.goType(t.elem, false)
+	case kindObject:
+		name = goName(t.object.name)
+	default:
+		return "interface{}"
+	}
+
+	if optional || t.nullable {
+		return "*" + name
+	}
+	return name
+}
+
+// goName converts a JSON key into an exported Go identifier
+func goName(name string) string {
+	var b strings.Builder
+	for _, word := range words(name) {
+		if upper := strings.ToUpper(word); goInitialisms[upper] {
+			b.WriteString(upper)
+		} else {
+			b.WriteString(capitalize(strings.ToLower(word)))
+		}
+	}
+
+	result := b.String()
+	if result == "" {
+		return "Field"
+	}
+	if result[0] >= '0' && result[0] <= '9' {
+		result = "F" + result
+	}
+	return result
+}
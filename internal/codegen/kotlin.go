@@ -0,0 +1,103 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// kotlinKeywords cannot be used as property names without backticks
+var kotlinKeywords = map[string]bool{
+	"as": true, "break": true, "class": true, "continue": true, "do": true, "else": true,
+	"false": true, "for": true, "fun": true, "if": true, "in": true, "interface": true,
+	"is": true, "null": true, "object": true, "package": true, "return": true, "super": true,
+	"this": true, "throw": true, "true": true, "try": true, "typealias": true, "typeof": true,
+	"val": true, "var": true, "when": true, "while": true,
+}
+
+// kotlin emits kotlinx.serialization data classes
+func (m *model) kotlin(rootName string) string {
+	var b strings.Builder
+	b.WriteString("import kotlinx.serialization.SerialName\n")
+	b.WriteString("import kotlinx.serialization.Serializable\n")
+	if m.usesAny() {
+		b.WriteString("import kotlinx.serialization.json.JsonElement\n")
+	}
+
+	if m.root.kind != kindObject {
+		fmt.Fprintf(&b, "\ntypealias %s = %s\n", rootName, m.kotlinType(m.root, false))
+	}
+
+	for _, object := range m.objects {
+		fmt.Fprintf(&b, "\n@Serializable\ndata class %s(\n", object.name)
+		names := fieldNames(object, camelName)
+		for i, f := range object.fields {
+			b.WriteString("    ")
+			name := names[i]
+			if name != f.jsonName {
+				fmt.Fprintf(&b, "@SerialName(%q) ", f.jsonName)
+			}
+			if kotlinKeywords[name] {
+				name = "`" + name + "`"
+			}
+			fmt.Fprintf(&b, "val %s: %s", name, m.kotlinType(f.typ, f.optional))
+			if f.optional || f.typ.nullable || f.typ.kind == kindAny {
+				b.WriteString(" = null")
+			}
+			b.WriteString(",\n")
+		}
+		b.WriteString(")\n")
+	}
+
+	return b.String()
+}
+
+func (m *model) kotlinType(t *typeRef, optional bool) string {
+	var name string
+	switch t.kind {
+	case kindString:
+		name = "String"
+	case kindInteger:
+		name = "Long"
+	case kindNumber:
+		name = "Double"
+	case kindBoolean:
+		name = "Boolean"
+	case kindArray:
+		name = "List<" + m.kotlinType(t.elem, false) + ">"
+	case kindObject:
+		name = t.object.name
+	default:
+		return "JsonElement?"
+	}
+
+	if optional || t.nullable {
+		return name + "?"
+	}
+	return name
+}
+
+// usesAny reports whether any type lacks a static type
+func (m *model) usesAny() bool {
+	var walk func(t *typeRef) bool
+	walk = func(t *typeRef) bool {
+		switch t.kind {
+		case kindAny:
+			return true
+		case kindArray:
+			return walk(t.elem)
+		}
+		return false
+	}
+
+	if walk(m.root) {
+		return true
+	}
+	for _, object := range m.objects {
+		for _, f := range object.fields {
+			if walk(f.typ) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,135 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// swiftKeywords cannot be used as property names without backticks
+var swiftKeywords = map[string]bool{
+	"as": true, "break": true, "case": true, "class": true, "continue": true, "default": true,
+	"defer": true, "do": true, "else": true, "enum": true, "extension": true, "false": true,
+	"for": true, "func": true, "guard": true, "if": true, "import": true, "in": true,
+	"init": true, "internal": true, "is": true, "let": true, "nil": true, "operator": true,
+	"private": true, "protocol": true, "public": true, "repeat": true, "return": true,
+	"self": true, "static": true, "struct": true, "subscript": true, "super": true,
+	"switch": true, "true": true, "try": true, "typealias": true, "var": true, "where": true,
+	"while": true,
+}
+
+// swiftJSONValue is emitted when some value has no static type, as Foundation has no Codable "any"
+const swiftJSONValue = `
+enum JSONValue: Codable {
+    case string(String)
+    case number(Double)
+    case bool(Bool)
+    case array([JSONValue])
+    case object([String: JSONValue])
+    case null
+
+    init(from decoder: Decoder) throws {
+        let container = try decoder.singleValueContainer()
+        if container.decodeNil() {
+            self = .null
+        } else if let value = try? container.decode(Bool.self) {
+            self = .bool(value)
+        } else if let value = try? container.decode(Double.self) {
+            self = .number(value)
+        } else if let value = try? container.decode(String.self) {
+            self = .string(value)
+        } else if let value = try? container.decode([JSONValue].self) {
+            self = .array(value)
+        } else {
+            self = .object(try container.decode([String: JSONValue].self))
+        }
+    }
+
+    func encode(to encoder: Encoder) throws {
+        var container = encoder.singleValueContainer()
+        switch self {
+        case .string(let value): try container.encode(value)
+        case .number(let value): try container.encode(value)
+        case .bool(let value): try container.encode(value)
+        case .array(let value): try container.encode(value)
+        case .object(let value): try container.encode(value)
+        case .null: try container.encodeNil()
+        }
+    }
+}
+`
+
+// swift emits Codable structs with CodingKeys for renamed properties
+func (m *model) swift(rootName string) string {
+	var b strings.Builder
+	b.WriteString("import Foundation\n")
+
+	if m.root.kind != kindObject {
+		fmt.Fprintf(&b, "\ntypealias %s = %s\n", rootName, m.swiftType(m.root, false))
+	}
+
+	for _, object := range m.objects {
+		fmt.Fprintf(&b, "\nstruct %s: Codable {\n", object.name)
+
+		renamed := false
+		names := fieldNames(object, camelName)
+		for i, f := range object.fields {
+			if names[i] != f.jsonName {
+				renamed = true
+			}
+
+			name := names[i]
+			if swiftKeywords[name] {
+				name = "`" + name + "`"
+			}
+			fmt.Fprintf(&b, "    let %s: %s\n", name, m.swiftType(f.typ, f.optional))
+		}
+
+		if renamed {
+			b.WriteString("\n    enum CodingKeys: String, CodingKey {\n")
+			for i, f := range object.fields {
+				name := names[i]
+				if swiftKeywords[name] {
+					name = "`" + name + "`"
+				}
+				if names[i] == f.jsonName {
+					fmt.Fprintf(&b, "        case %s\n", name)
+				} else {
+					fmt.Fprintf(&b, "        case %s = %q\n", name, f.jsonName)
+				}
+			}
+			b.WriteString("    }\n")
+		}
+		b.WriteString("}\n")
+	}
+
+	if m.usesAny() {
+		b.WriteString(swiftJSONValue)
+	}
+
+	return b.String()
+}
+
+func (m *model) swiftType(t *typeRef, optional bool) string {
+	var name string
+	switch t.kind {
+	case kindString:
+		name = "String"
+	case kindInteger:
+		name = "Int"
+	case kindNumber:
+		name = "Double"
+	case kindBoolean:
+		name = "Bool"
+	case kindArray:
+		name = "[" + m.swiftType(t.elem, false) + "]"
+	case kindObject:
+		name = t.object.name
+	default:
+		name = "JSONValue"
+	}
+
+	if optional || t.nullable {
+		return name + "?"
+	}
+	return name
+}
@@ -0,0 +1,68 @@
+package codegen
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// tsIdentifier matches property names that need no quoting
+var tsIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+func (m *model) typescript(rootName string) string {
+	var b strings.Builder
+
+	if m.root.kind != kindObject {
+		fmt.Fprintf(&b, "export type %s = %s;\n", rootName, m.tsType(m.root))
+	}
+
+	for i, object := range m.objects {
+		if i > 0 || m.root.kind != kindObject {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "export interface %s {\n", object.name)
+		// Properties keep their JSON keys, which are unique already
+		names := fieldNames(object, func(key string) string { return key })
+		for i, f := range object.fields {
+			name := names[i]
+			if !tsIdentifier.MatchString(name) {
+				name = fmt.Sprintf("%q", name)
+			}
+			optional := ""
+			if f.optional {
+				optional = "?"
+			}
+			fmt.Fprintf(&b, "  %s%s: %s;\n", name, optional, m.tsType(f.typ))
+		}
+		b.WriteString("}\n")
+	}
+
+	return b.String()
+}
+
+func (m *model) tsType(t *typeRef) string {
+	var name string
+	switch t.kind {
+	case kindString:
+		name = "string"
+	case kindInteger, kindNumber:
+		name = "number"
+	case kindBoolean:
+		name = "boolean"
+	case kindArray:
+		elem := m.tsType(t.elem)
+		if strings.Contains(elem, " ") {
+			elem = "(" + elem + ")"
+		}
+		name = elem + "[]"
+	case kindObject:
+		name = t.object.name
+	default:
+		return "unknown"
+	}
+
+	if t.nullable {
+		return name + " | null"
+	}
+	return name
+}
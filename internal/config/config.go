@@ -4,13 +4,24 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
 type Config struct {
-	Server    ServerConfig
-	Database  DatabaseConfig
-	RateLimit RateLimitConfig
+	Server     ServerConfig
+	Log        LogConfig
+	Database   DatabaseConfig
+	RateLimit  RateLimitConfig
+	CORS       CORSConfig
+	Journal    JournalConfig
+	Proxy      ProxyConfig
+	Fallback   FallbackConfig
+	Transfer   TransferConfig
+	Seed       SeedConfig
+	Attachment AttachmentConfig
+	Webhook    WebhookConfig
+	Callback   CallbackConfig
 }
 
 type ServerConfig struct {
@@ -21,6 +32,11 @@ type ServerConfig struct {
 	IdleTimeout  time.Duration
 }
 
+type LogConfig struct {
+	Level  string // debug, info, warn or error
+	Format string // text or json
+}
+
 type DatabaseConfig struct {
 	DataSourceName  string
 	MaxOpenConns    int
@@ -30,11 +46,92 @@ type DatabaseConfig struct {
 }
 
 type RateLimitConfig struct {
-	Requests int
-	Window   time.Duration
-	Enabled  bool
+	Requests       int
+	Window         time.Duration
+	Burst          int // Requests allowed at once, Requests when zero
+	Enabled        bool
+	TrustedProxies []string          // IPs or CIDRs whose X-Forwarded-For is believed
+	Routes         map[string]string // "[METHOD ]/path/prefix" to "requests/window"
+	Tokens         map[string]string // Bearer token to "requests/window"
+	Store          string            // memory, sqlite to share buckets through the database, or redis
+	RedisURL       string            // redis://[user:password@]host:port[/db] of the redis store
+	FailOpen       bool              // Let requests through when the store fails, instead of 503
+}
+
+type CORSConfig struct {
+	AllowedOrigins   []string // Exact origins or patterns such as https://*.example.com; * allows any
+	AllowedMethods   []string
+	AllowedHeaders   []string // * allows any
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration // Zero leaves preflight caching to the browser
+}
+
+type JournalConfig struct {
+	MaxEntries      int
+	Retention       time.Duration
+	CleanupInterval time.Duration
+}
+
+type ProxyConfig struct {
+	Target         string
+	Mode           string
+	Prefix         string
+	Password       string
+	ExcludeHeaders []string
+	Timeout        time.Duration
+}
+
+type FallbackConfig struct {
+	Target        string
+	Timeout       time.Duration
+	PreserveHost  bool
+	SetHeaders    map[string]string
+	RemoveHeaders []string
+}
+
+type TransferConfig struct {
+	Password      string
+	MaxImportSize int64
+}
+
+type AttachmentConfig struct {
+	Storage string
+	Dir     string
+	MaxSize int64
+	Quota   int64
+}
+
+type WebhookConfig struct {
+	Timeout      time.Duration
+	MaxAttempts  int
+	RetryBase    time.Duration // Delay before the first retry, doubled for each further one
+	RetryMax     time.Duration
+	PollInterval time.Duration
+	Retention    time.Duration
+	AllowPrivate bool // Deliver to loopback, link-local and private addresses
+}
+
+type CallbackConfig struct {
+	Workers      int
+	QueueSize    int
+	Timeout      time.Duration
+	AllowPrivate bool // Send to loopback, link-local and private addresses
+}
+
+type SeedConfig struct {
+	Dir          string
+	Password     string
+	Watch        bool
+	PollInterval time.Duration
 }
 
+// Proxy modes
+const (
+	ProxyModeRecord = "record"
+	ProxyModeReplay = "replay"
+)
+
 func Load() (*Config, error) {
 	config := &Config{
 		Server: ServerConfig{
@@ -44,6 +141,10 @@ func Load() (*Config, error) {
 			WriteTimeout: getEnvAsDuration("SERVER_WRITE_TIMEOUT", 15*time.Second),
 			IdleTimeout:  getEnvAsDuration("SERVER_IDLE_TIMEOUT", 60*time.Second),
 		},
+		Log: LogConfig{
+			Level:  strings.ToLower(getEnv("LOG_LEVEL", "info")),
+			Format: strings.ToLower(getEnv("LOG_FORMAT", "text")),
+		},
 		Database: DatabaseConfig{
 			DataSourceName:  getEnv("DATABASE_URL", "data/mockj.db"),
 			MaxOpenConns:    getEnvAsInt("DATABASE_MAX_OPEN_CONNS", 25),
@@ -52,10 +153,118 @@ func Load() (*Config, error) {
 			CleanupInterval: getEnvAsDuration("DATABASE_CLEANUP_INTERVAL", 1*time.Hour),
 		},
 		RateLimit: RateLimitConfig{
-			Requests: getEnvAsInt("RATE_LIMIT_REQUESTS", 100),
-			Window:   getEnvAsDuration("RATE_LIMIT_WINDOW", time.Minute),
-			Enabled:  getEnvAsBool("RATE_LIMIT_ENABLED", true),
+			Requests:       getEnvAsInt("RATE_LIMIT_REQUESTS", 100),
+			Window:         getEnvAsDuration("RATE_LIMIT_WINDOW", time.Minute),
+			Burst:          getEnvAsInt("RATE_LIMIT_BURST", 0),
+			Enabled:        getEnvAsBool("RATE_LIMIT_ENABLED", true),
+			TrustedProxies: getEnvAsSlice("RATE_LIMIT_TRUSTED_PROXIES", nil),
+			Routes:         getEnvAsMap("RATE_LIMIT_ROUTES"),
+			Tokens:         getEnvAsMap("RATE_LIMIT_TOKENS"),
+			Store:          getEnv("RATE_LIMIT_STORE", "memory"),
+			RedisURL:       getEnv("RATE_LIMIT_REDIS_URL", ""),
+			FailOpen:       getEnvAsBool("RATE_LIMIT_FAIL_OPEN", true),
+		},
+		CORS: CORSConfig{
+			AllowedOrigins:   getEnvAsSlice("CORS_ALLOWED_ORIGINS", []string{"*"}),
+			AllowedMethods:   getEnvAsSlice("CORS_ALLOWED_METHODS", []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}),
+			AllowedHeaders:   getEnvAsSlice("CORS_ALLOWED_HEADERS", []string{"Content-Type", "Authorization", "X-Mock-Password"}),
+			ExposedHeaders:   getEnvAsSlice("CORS_EXPOSED_HEADERS", nil),
+			AllowCredentials: getEnvAsBool("CORS_ALLOW_CREDENTIALS", false),
+			MaxAge:           getEnvAsDuration("CORS_MAX_AGE", 0),
+		},
+		Journal: JournalConfig{
+			MaxEntries:      getEnvAsInt("JOURNAL_MAX_ENTRIES", 10000),
+			Retention:       getEnvAsDuration("JOURNAL_RETENTION", 24*time.Hour),
+			CleanupInterval: getEnvAsDuration("JOURNAL_CLEANUP_INTERVAL", time.Minute),
+		},
+		Proxy: ProxyConfig{
+			Target:         getEnv("PROXY_TARGET", ""),
+			Mode:           getEnv("PROXY_MODE", ProxyModeRecord),
+			Prefix:         strings.TrimSuffix(getEnv("PROXY_PREFIX", "/proxy"), "/"),
+			Password:       getEnv("PROXY_PASSWORD", ""),
+			ExcludeHeaders: getEnvAsSlice("PROXY_RECORD_EXCLUDE_HEADERS", []string{"Date", "Set-Cookie", "Content-Length", "Content-Encoding"}),
+			Timeout:        getEnvAsDuration("PROXY_TIMEOUT", 30*time.Second),
+		},
+		Fallback: FallbackConfig{
+			Target:        getEnv("FALLBACK_TARGET", ""),
+			Timeout:       getEnvAsDuration("FALLBACK_TIMEOUT", 30*time.Second),
+			PreserveHost:  getEnvAsBool("FALLBACK_PRESERVE_HOST", false),
+			SetHeaders:    getEnvAsMap("FALLBACK_SET_HEADERS"),
+			RemoveHeaders: getEnvAsSlice("FALLBACK_REMOVE_HEADERS", nil),
+		},
+		Transfer: TransferConfig{
+			Password:      getEnv("TRANSFER_PASSWORD", ""),
+			MaxImportSize: int64(getEnvAsInt("TRANSFER_MAX_IMPORT_SIZE", 64<<20)),
+		},
+		Seed: SeedConfig{
+			Dir:          getEnv("SEED_DIR", ""),
+			Password:     getEnv("SEED_PASSWORD", ""),
+			Watch:        getEnvAsBool("SEED_WATCH", false),
+			PollInterval: getEnvAsDuration("SEED_POLL_INTERVAL", 2*time.Second),
+		},
+		Attachment: AttachmentConfig{
+			Storage: getEnv("ATTACHMENT_STORAGE", "sqlite"),
+			Dir:     getEnv("ATTACHMENT_DIR", "data/attachments"),
+			MaxSize: int64(getEnvAsInt("ATTACHMENT_MAX_SIZE", 10<<20)),
+			Quota:   int64(getEnvAsInt("ATTACHMENT_QUOTA", 512<<20)),
+		},
+		Webhook: WebhookConfig{
+			Timeout:      getEnvAsDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+			MaxAttempts:  getEnvAsInt("WEBHOOK_MAX_ATTEMPTS", 8),
+			RetryBase:    getEnvAsDuration("WEBHOOK_RETRY_BASE", 5*time.Second),
+			RetryMax:     getEnvAsDuration("WEBHOOK_RETRY_MAX", time.Hour),
+			PollInterval: getEnvAsDuration("WEBHOOK_POLL_INTERVAL", time.Second),
+			Retention:    getEnvAsDuration("WEBHOOK_RETENTION", 7*24*time.Hour),
+			AllowPrivate: getEnvAsBool("WEBHOOK_ALLOW_PRIVATE", false),
 		},
+		Callback: CallbackConfig{
+			Workers:      getEnvAsInt("CALLBACK_WORKERS", 4),
+			QueueSize:    getEnvAsInt("CALLBACK_QUEUE_SIZE", 256),
+			Timeout:      getEnvAsDuration("CALLBACK_TIMEOUT", 10*time.Second),
+			AllowPrivate: getEnvAsBool("CALLBACK_ALLOW_PRIVATE", false),
+		},
+	}
+
+	switch config.Log.Level {
+	case "debug", "info", "warn", "error":
+	default:
+		return nil, fmt.Errorf("invalid LOG_LEVEL %q: must be debug, info, warn or error", config.Log.Level)
+	}
+
+	if config.Log.Format != "text" && config.Log.Format != "json" {
+		return nil, fmt.Errorf("invalid LOG_FORMAT %q: must be text or json", config.Log.Format)
+	}
+
+	if config.Proxy.Mode != ProxyModeRecord && config.Proxy.Mode != ProxyModeReplay {
+		return nil, fmt.Errorf("invalid PROXY_MODE %q: must be %s or %s", config.Proxy.Mode, ProxyModeRecord, ProxyModeReplay)
+	}
+
+	if config.Proxy.Target != "" && config.Proxy.Password == "" {
+		return nil, fmt.Errorf("PROXY_PASSWORD is required when PROXY_TARGET is set")
+	}
+
+	if config.Seed.Dir != "" && config.Seed.Password == "" {
+		return nil, fmt.Errorf("SEED_PASSWORD is required when SEED_DIR is set")
+	}
+
+	if config.RateLimit.Store != "memory" && config.RateLimit.Store != "sqlite" && config.RateLimit.Store != "redis" {
+		return nil, fmt.Errorf("invalid RATE_LIMIT_STORE %q: must be memory, sqlite or redis", config.RateLimit.Store)
+	}
+
+	if config.RateLimit.Store == "redis" && config.RateLimit.RedisURL == "" {
+		return nil, fmt.Errorf("RATE_LIMIT_REDIS_URL is required when RATE_LIMIT_STORE is redis")
+	}
+
+	if config.Attachment.Storage != "sqlite" && config.Attachment.Storage != "dir" {
+		return nil, fmt.Errorf("invalid ATTACHMENT_STORAGE %q: must be sqlite or dir", config.Attachment.Storage)
+	}
+
+	if config.Webhook.MaxAttempts < 1 {
+		return nil, fmt.Errorf("invalid WEBHOOK_MAX_ATTEMPTS %d: must be at least 1", config.Webhook.MaxAttempts)
+	}
+
+	if config.Callback.Workers < 1 {
+		return nil, fmt.Errorf("invalid CALLBACK_WORKERS %d: must be at least 1", config.Callback.Workers)
 	}
 
 	return config, nil
@@ -90,6 +299,30 @@ func getEnvAsBool(key string, defaultValue bool) bool {
 	return defaultValue
 }
 
+func getEnvAsSlice(key string, defaultValue []string) []string {
+	if value := os.Getenv(key); value != "" {
+		var values []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				values = append(values, item)
+			}
+		}
+		return values
+	}
+	return defaultValue
+}
+
+// getEnvAsMap parses comma-separated key:value pairs
+func getEnvAsMap(key string) map[string]string {
+	values := make(map[string]string)
+	for _, pair := range getEnvAsSlice(key, nil) {
+		if name, value, ok := strings.Cut(pair, ":"); ok {
+			values[strings.TrimSpace(name)] = strings.TrimSpace(value)
+		}
+	}
+	return values
+}
+
 func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
 	if value := os.Getenv(key); value != "" {
 		if duration, err := time.ParseDuration(value); err == nil {
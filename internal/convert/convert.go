@@ -0,0 +1,323 @@
+// Package convert renders JSON documents as YAML, XML or CSV, keeping the order of object keys,
+// and decodes YAML documents for encoding as JSON.
+package convert
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrNotConvertible is returned when a document has no representation in the target format
+var ErrNotConvertible = errors.New("document cannot be converted")
+
+// Kinds of Value
+const (
+	Null = iota
+	Bool
+	Number
+	String
+	Array
+	Object
+)
+
+// Value is a decoded JSON value that remembers the order of object members
+type Value struct {
+	Kind    int
+	Scalar  string   // Text of booleans, numbers and strings
+	Items   []*Value // Array elements
+	Members []Member // Object members in document order
+}
+
+// Member is one key of an object
+type Member struct {
+	Key   string
+	Value *Value
+}
+
+// Decode parses a single JSON document
+func Decode(content []byte) (*Value, error) {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+
+	value, err := decodeValue(decoder)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after JSON document")
+	}
+	return value, nil
+}
+
+func decodeValue(decoder *json.Decoder) (*Value, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := token.(type) {
+	case json.Delim:
+		if t == '[' {
+			value := &Value{Kind: Array, Items: []*Value{}}
+			for decoder.More() {
+				item, err := decodeValue(decoder)
+				if err != nil {
+					return nil, err
+				}
+				value.Items = append(value.Items, item)
+			}
+			_, err := decoder.Token()
+			return value, err
+		}
+
+		value := &Value{Kind: Object}
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			member, err := decodeValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			value.Members = append(value.Members, Member{Key: key.(string), Value: member})
+		}
+		_, err := decoder.Token()
+		return value, err
+	case bool:
+		return &Value{Kind: Bool, Scalar: fmt.Sprint(t)}, nil
+	case json.Number:
+		return &Value{Kind: Number, Scalar: t.String()}, nil
+	case string:
+		return &Value{Kind: String, Scalar: t}, nil
+	default:
+		return &Value{Kind: Null}, nil
+	}
+}
+
+// yaml11Bools are plain strings in YAML 1.2 but booleans to YAML 1.1 parsers, so they are quoted
+var yaml11Bools = map[string]bool{"y": true, "yes": true, "n": true, "no": true, "on": true, "off": true}
+
+// ToYAML renders a value as a block-style YAML document
+func ToYAML(value *Value) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(yamlNode(value)); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func yamlNode(value *Value) *yaml.Node {
+	switch value.Kind {
+	case Array:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, item := range value.Items {
+			node.Content = append(node.Content, yamlNode(item))
+		}
+		// Empty collections can only be written in flow style
+		if len(value.Items) == 0 {
+			node.Style = yaml.FlowStyle
+		}
+		return node
+	case Object:
+		node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for _, member := range value.Members {
+			node.Content = append(node.Content, yamlString(member.Key), yamlNode(member.Value))
+		}
+		if len(value.Members) == 0 {
+			node.Style = yaml.FlowStyle
+		}
+		return node
+	case Bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: value.Scalar}
+	case Number:
+		tag := "!!int"
+		if strings.ContainsAny(value.Scalar, ".eE") {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value.Scalar}
+	case String:
+		return yamlString(value.Scalar)
+	default:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+	}
+}
+
+func yamlString(value string) *yaml.Node {
+	// The !!str tag makes the encoder quote strings that would read as other types in YAML 1.2
+	node := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+	if yaml11Bools[strings.ToLower(value)] {
+		node.Style = yaml.DoubleQuotedStyle
+	}
+	return node
+}
+
+// ToXML renders a value as an XML document. The value is wrapped in a root element,
+// array elements become item elements, and object members become elements named after
+// their keys, or item elements with a name attribute when the key is not a valid name.
+func ToXML(value *Value, root, item string) ([]byte, error) {
+	if !validXMLName(root) || !validXMLName(item) {
+		return nil, fmt.Errorf("invalid XML element name")
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+
+	encoder := xml.NewEncoder(&buf)
+	encoder.Indent("", "  ")
+	if err := writeXML(encoder, xml.StartElement{Name: xml.Name{Local: root}}, value, item); err != nil {
+		return nil, err
+	}
+	if err := encoder.Flush(); err != nil {
+		return nil, err
+	}
+
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+func writeXML(encoder *xml.Encoder, start xml.StartElement, value *Value, item string) error {
+	if err := encoder.EncodeToken(start); err != nil {
+		return err
+	}
+
+	switch value.Kind {
+	case Array:
+		for _, element := range value.Items {
+			if err := writeXML(encoder, xml.StartElement{Name: xml.Name{Local: item}}, element, item); err != nil {
+				return err
+			}
+		}
+	case Object:
+		for _, member := range value.Members {
+			child := xml.StartElement{Name: xml.Name{Local: member.Key}}
+			if !validXMLName(member.Key) {
+				child = xml.StartElement{
+					Name: xml.Name{Local: item},
+					Attr: []xml.Attr{{Name: xml.Name{Local: "name"}, Value: member.Key}},
+				}
+			}
+			if err := writeXML(encoder, child, member.Value, item); err != nil {
+				return err
+			}
+		}
+	case Null:
+	default:
+		if err := encoder.EncodeToken(xml.CharData(value.Scalar)); err != nil {
+			return err
+		}
+	}
+
+	return encoder.EncodeToken(start.End())
+}
+
+// validXMLName reports whether name can be used as an element name
+func validXMLName(name string) bool {
+	if name == "" || strings.HasPrefix(strings.ToLower(name), "xml") {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_' || unicode.IsLetter(r):
+		case i > 0 && (r == '-' || r == '.' || unicode.IsDigit(r)):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// ToCSV renders an array of flat objects as CSV with a header row. Columns follow the
+// order in which keys first appear; missing and null values are empty.
+func ToCSV(value *Value) ([]byte, error) {
+	if value.Kind != Array {
+		return nil, fmt.Errorf("%w: CSV needs an array of objects", ErrNotConvertible)
+	}
+
+	var (
+		columns []string
+		index   = map[string]int{}
+	)
+	for _, row := range value.Items {
+		if row.Kind != Object {
+			return nil, fmt.Errorf("%w: CSV needs an array of objects", ErrNotConvertible)
+		}
+		for _, member := range row.Members {
+			if member.Value.Kind == Array || member.Value.Kind == Object {
+				return nil, fmt.Errorf("%w: CSV needs flat objects, %q is nested", ErrNotConvertible, member.Key)
+			}
+			if _, ok := index[member.Key]; !ok {
+				index[member.Key] = len(columns)
+				columns = append(columns, member.Key)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if len(columns) > 0 {
+		if err := writer.Write(columns); err != nil {
+			return nil, err
+		}
+	}
+	for _, row := range value.Items {
+		record := make([]string, len(columns))
+		for _, member := range row.Members {
+			record[index[member.Key]] = member.Value.Scalar
+		}
+		if err := writer.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	writer.Flush()
+
+	return buf.Bytes(), writer.Error()
+}
+
+// DecodeYAML decodes a YAML document into values encoding/json can marshal: mappings with
+// non-string keys, such as unquoted response codes, become map[string]interface{}
+func DecodeYAML(data []byte) (interface{}, error) {
+	var value interface{}
+	if err := yaml.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	return normalizeYAML(value), nil
+}
+
+func normalizeYAML(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for key, item := range value {
+			value[key] = normalizeYAML(item)
+		}
+		return value
+	case map[interface{}]interface{}:
+		object := make(map[string]interface{}, len(value))
+		for key, item := range value {
+			object[fmt.Sprint(key)] = normalizeYAML(item)
+		}
+		return object
+	case []interface{}:
+		for i, item := range value {
+			value[i] = normalizeYAML(item)
+		}
+		return value
+	default:
+		return v
+	}
+}
@@ -0,0 +1,133 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"mockj-go/internal/models"
+)
+
+const attachmentColumns = `id, json_id, filename, content_type, size, sha256, created_at`
+
+func scanAttachment(row rowScanner) (*models.Attachment, error) {
+	attachment := &models.Attachment{}
+	err := row.Scan(
+		&attachment.ID,
+		&attachment.MockID,
+		&attachment.Filename,
+		&attachment.ContentType,
+		&attachment.Size,
+		&attachment.SHA256,
+		&attachment.CreatedAt,
+	)
+	return attachment, err
+}
+
+// CreateAttachment stores attachment metadata, with its bytes when they are kept in the database.
+// With a non-zero quota it fails when the sizes of all attachments would exceed it; the check
+// and the insert are one statement, so concurrent uploads cannot overshoot together.
+func (d *Database) CreateAttachment(ctx context.Context, attachment *models.Attachment, data []byte, quota int64) error {
+	query := `
+	INSERT INTO attachments (id, json_id, filename, content_type, size, sha256, data, created_at)
+	SELECT ?, ?, ?, ?, ?, ?, ?, ?
+	WHERE ? = 0 OR (SELECT COALESCE(SUM(size), 0) FROM attachments) + ? <= ?
+	`
+
+	result, err := d.conn.ExecContext(ctx, query, attachment.ID, attachment.MockID, attachment.Filename, attachment.ContentType,
+		attachment.Size, attachment.SHA256, data, attachment.CreatedAt, quota, attachment.Size, quota)
+	if err != nil {
+		return fmt.Errorf("failed to create attachment: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("attachment quota exceeded")
+	}
+	return nil
+}
+
+// GetAttachment retrieves the metadata of an attachment of a mock
+func (d *Database) GetAttachment(ctx context.Context, mockID, id string) (*models.Attachment, error) {
+	query := `SELECT ` + attachmentColumns + ` FROM attachments WHERE json_id = ? AND id = ?`
+
+	attachment, err := scanAttachment(d.conn.QueryRowContext(ctx, query, mockID, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("attachment not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attachment: %w", err)
+	}
+
+	return attachment, nil
+}
+
+// GetAttachmentData retrieves the bytes of an attachment kept in the database
+func (d *Database) GetAttachmentData(ctx context.Context, id string) ([]byte, error) {
+	var data []byte
+	err := d.conn.QueryRowContext(ctx, `SELECT data FROM attachments WHERE id = ?`, id).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("attachment not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attachment data: %w", err)
+	}
+	return data, nil
+}
+
+// ListAttachments retrieves the attachments of a mock, oldest first
+func (d *Database) ListAttachments(ctx context.Context, mockID string) ([]*models.Attachment, error) {
+	query := `SELECT ` + attachmentColumns + ` FROM attachments WHERE json_id = ? ORDER BY created_at, id`
+
+	rows, err := d.conn.QueryContext(ctx, query, mockID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list attachments: %w", err)
+	}
+	defer rows.Close()
+
+	attachments := []*models.Attachment{}
+	for rows.Next() {
+		attachment, err := scanAttachment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan attachment: %w", err)
+		}
+		attachments = append(attachments, attachment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list attachments: %w", err)
+	}
+
+	return attachments, nil
+}
+
+// DeleteAttachment deletes an attachment of a mock
+func (d *Database) DeleteAttachment(ctx context.Context, mockID, id string) error {
+	result, err := d.conn.ExecContext(ctx, `DELETE FROM attachments WHERE json_id = ? AND id = ?`, mockID, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete attachment: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("attachment not found")
+	}
+
+	return nil
+}
+
+// AttachmentExists reports whether an attachment with this ID is stored
+func (d *Database) AttachmentExists(ctx context.Context, id string) (bool, error) {
+	var count int
+	if err := d.conn.QueryRowContext(ctx, `SELECT COUNT(*) FROM attachments WHERE id = ?`, id).Scan(&count); err != nil {
+		return false, fmt.Errorf("failed to check attachment: %w", err)
+	}
+	return count > 0, nil
+}
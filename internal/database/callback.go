@@ -0,0 +1,80 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"mockj-go/internal/models"
+)
+
+// RecordCallback appends the outcome of a callback request to the log
+func (d *Database) RecordCallback(ctx context.Context, result *models.CallbackResult) error {
+	query := `
+	INSERT INTO callback_results (json_id, method, url, status, error, duration_ms, created_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+
+	res, err := d.conn.ExecContext(ctx, query, result.MockID, result.Method, result.URL, result.Status, result.Error,
+		result.DurationMs, result.Timestamp)
+	if err != nil {
+		return fmt.Errorf("failed to record callback: %w", err)
+	}
+
+	result.ID, err = res.LastInsertId()
+	return err
+}
+
+// ListCallbackResults returns the logged callbacks of a mock, newest first
+func (d *Database) ListCallbackResults(ctx context.Context, jsonID string, limit int) ([]*models.CallbackResult, error) {
+	query := `
+	SELECT id, json_id, method, url, status, error, duration_ms, created_at
+	FROM callback_results WHERE json_id = ? ORDER BY id DESC LIMIT ?
+	`
+
+	rows, err := d.conn.QueryContext(ctx, query, jsonID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list callbacks: %w", err)
+	}
+	defer rows.Close()
+
+	results := []*models.CallbackResult{}
+	for rows.Next() {
+		result := &models.CallbackResult{}
+		if err := rows.Scan(
+			&result.ID,
+			&result.MockID,
+			&result.Method,
+			&result.URL,
+			&result.Status,
+			&result.Error,
+			&result.DurationMs,
+			&result.Timestamp,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan callback: %w", err)
+		}
+		results = append(results, result)
+	}
+
+	return results, rows.Err()
+}
+
+// CleanupCallbackResults drops logged callbacks older than retention
+func (d *Database) CleanupCallbackResults(ctx context.Context, retention time.Duration) error {
+	result, err := d.conn.ExecContext(ctx, `DELETE FROM callback_results WHERE created_at < ?`, time.Now().Add(-retention))
+	if err != nil {
+		return fmt.Errorf("failed to cleanup callbacks: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected > 0 {
+		slog.InfoContext(ctx, "Cleaned up callback results", "count", rowsAffected)
+	}
+
+	return nil
+}
@@ -1,18 +1,31 @@
 package database
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"time"
 
 	"mockj-go/internal/models"
 
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/mattn/go-sqlite3"
 )
 
 type Database struct {
 	db *sql.DB
+
+	// conn runs queries: the pool itself, or a transaction inside RunInTx
+	conn conn
+}
+
+// conn is implemented by *sql.DB and *sql.Tx
+type conn interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
 }
 
 // NewDatabase creates a new database connection
@@ -22,13 +35,18 @@ func NewDatabase(dataSourceName string) (*Database, error) {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
+	// Every connection to ":memory:" opens a separate empty database
+	if dataSourceName == ":memory:" {
+		db.SetMaxOpenConns(1)
+	}
+
 	if err = db.Ping(); err != nil {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	database := &Database{db: db}
+	database := &Database{db: db, conn: db}
 
-	if err = database.createTables(); err != nil {
+	if err = database.createTables(context.Background()); err != nil {
 		return nil, fmt.Errorf("failed to create tables: %w", err)
 	}
 
@@ -36,7 +54,7 @@ func NewDatabase(dataSourceName string) (*Database, error) {
 }
 
 // createTables creates the necessary database tables
-func (d *Database) createTables() error {
+func (d *Database) createTables(ctx context.Context) error {
 	query := `
 	CREATE TABLE IF NOT EXISTS json (
 		id TEXT PRIMARY KEY,
@@ -49,67 +67,356 @@ func (d *Database) createTables() error {
 	
 	CREATE INDEX IF NOT EXISTS idx_json_expires ON json(expires);
 	CREATE INDEX IF NOT EXISTS idx_json_created_at ON json(created_at);
+
+	CREATE TABLE IF NOT EXISTS requests (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		json_id TEXT NOT NULL,
+		method TEXT NOT NULL,
+		path TEXT NOT NULL,
+		query TEXT NOT NULL,
+		headers TEXT NOT NULL,
+		body TEXT NOT NULL,
+		status INTEGER NOT NULL,
+		created_at DATETIME NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_requests_json_id ON requests(json_id, created_at);
+	CREATE INDEX IF NOT EXISTS idx_requests_created_at ON requests(created_at);
+
+	CREATE TABLE IF NOT EXISTS attachments (
+		id TEXT PRIMARY KEY,
+		json_id TEXT NOT NULL,
+		filename TEXT NOT NULL,
+		content_type TEXT NOT NULL,
+		size INTEGER NOT NULL,
+		sha256 TEXT NOT NULL,
+		data BLOB,
+		created_at DATETIME NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_attachments_json_id ON attachments(json_id);
+
+	CREATE TABLE IF NOT EXISTS callback_results (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		json_id TEXT NOT NULL,
+		method TEXT NOT NULL,
+		url TEXT NOT NULL,
+		status INTEGER NOT NULL,
+		error TEXT NOT NULL,
+		duration_ms INTEGER NOT NULL,
+		created_at DATETIME NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_callback_results_json_id ON callback_results(json_id, id);
+	CREATE INDEX IF NOT EXISTS idx_callback_results_created_at ON callback_results(created_at);
+
+	CREATE TABLE IF NOT EXISTS webhooks (
+		id TEXT PRIMARY KEY,
+		url TEXT NOT NULL,
+		events TEXT,
+		json_id TEXT NOT NULL,
+		secret TEXT NOT NULL,
+		password TEXT NOT NULL,
+		created_at DATETIME NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS webhook_deliveries (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		webhook_id TEXT NOT NULL,
+		event_id INTEGER NOT NULL,
+		event_type TEXT NOT NULL,
+		json_id TEXT NOT NULL,
+		payload TEXT NOT NULL,
+		state TEXT NOT NULL,
+		attempts INTEGER NOT NULL,
+		response_status INTEGER NOT NULL,
+		error TEXT NOT NULL,
+		next_attempt_at DATETIME NOT NULL,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook_id ON webhook_deliveries(webhook_id, id);
+	CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(state, next_attempt_at);
+
+	CREATE TABLE IF NOT EXISTS rate_limits (
+		key TEXT PRIMARY KEY,
+		tokens REAL NOT NULL,
+		allowed INTEGER NOT NULL,
+		burst INTEGER NOT NULL,
+		rate REAL NOT NULL,
+		updated_at REAL NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS seeds (
+		path TEXT PRIMARY KEY,
+		json_id TEXT NOT NULL
+	);
 	`
 
-	_, err := d.db.Exec(query)
+	if _, err := d.conn.ExecContext(ctx, query); err != nil {
+		return err
+	}
+
+	migrations := []struct{ column, definition string }{
+		{"fault", "TEXT"},
+		{"method", "TEXT NOT NULL DEFAULT ''"},
+		{"route", "TEXT NOT NULL DEFAULT ''"},
+		{"status", "INTEGER NOT NULL DEFAULT 0"},
+		{"headers", "TEXT"},
+		{"content_type", "TEXT NOT NULL DEFAULT ''"},
+		{"graphql_schema", "TEXT NOT NULL DEFAULT ''"},
+		{"websocket", "TEXT"},
+		{"callbacks", "TEXT"},
+		{"cors", "TEXT"},
+		{"recorded", "INTEGER NOT NULL DEFAULT 0"},
+	}
+	for _, m := range migrations {
+		if err := d.addColumnIfMissing(ctx, "json", m.column, m.definition); err != nil {
+			return err
+		}
+	}
+
+	// Route lookups read only this index, never the content. Recordings are unique per
+	// method and route, so concurrent captures of one route cannot both create a mock.
+	_, err := d.conn.ExecContext(ctx, `
+	CREATE INDEX IF NOT EXISTS idx_json_method_route ON json(method, route, expires, modified_at, id);
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_json_recorded_route ON json(method, route) WHERE recorded = 1;
+	`)
 	return err
 }
 
+// addColumnIfMissing adds a column to an existing table created by an older version
+func (d *Database) addColumnIfMissing(ctx context.Context, table, column, definition string) error {
+	rows, err := d.conn.QueryContext(ctx, fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid        int
+			name       string
+			colType    string
+			notNull    int
+			defaultVal sql.NullString
+			primaryKey int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultVal, &primaryKey); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	_, err = d.conn.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	return err
+}
+
+// encodeJSONColumn marshals an optional value into a nullable TEXT column
+func encodeJSONColumn(v interface{}) (sql.NullString, error) {
+	if v == nil {
+		return sql.NullString{}, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+
+	if string(data) == "null" {
+		return sql.NullString{}, nil
+	}
+
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+// decodeJSONColumn unmarshals a nullable TEXT column into v, leaving v untouched when NULL
+func decodeJSONColumn(column sql.NullString, v interface{}) error {
+	if !column.Valid || column.String == "" {
+		return nil
+	}
+	return json.Unmarshal([]byte(column.String), v)
+}
+
 // Close closes the database connection
 func (d *Database) Close() error {
 	return d.db.Close()
 }
 
-// CreateJSON inserts a new JSON entity
-func (d *Database) CreateJSON(json *models.JSON) error {
-	query := `
-	INSERT INTO json (id, json, password, created_at, modified_at, expires)
-	VALUES (?, ?, ?, ?, ?, ?)
-	`
+// RunInTx calls fn with a Database whose methods run in a single transaction,
+// committed when fn returns nil and rolled back otherwise. Calls nest into the
+// outer transaction.
+func (d *Database) RunInTx(ctx context.Context, fn func(tx *Database) error) error {
+	if _, ok := d.conn.(*sql.Tx); ok {
+		return fn(d)
+	}
 
-	_, err := d.db.Exec(query, json.ID, json.Content, json.Password, json.CreatedAt, json.ModifiedAt, json.Expires)
-	return err
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	if err := fn(&Database{db: d.db, conn: tx}); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
 }
 
-// GetJSON retrieves a JSON entity by ID
-func (d *Database) GetJSON(id string) (*models.JSON, error) {
-	query := `
-	SELECT id, json, created_at, modified_at, expires
-	FROM json
-	WHERE id = ? AND expires > ?
-	`
+// jsonColumns lists the columns read by scanJSON, in order
+const jsonColumns = `id, json, password, created_at, modified_at, expires, fault, method, route, status, headers, content_type, graphql_schema, websocket, callbacks, cors`
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
 
+// scanJSON reads a row selected with jsonColumns
+func scanJSON(row rowScanner) (*models.JSON, error) {
 	json := &models.JSON{}
-	err := d.db.QueryRow(query, id, time.Now()).Scan(
+	var fault, headers, websocket, callbacks, cors sql.NullString
+	err := row.Scan(
 		&json.ID,
 		&json.Content,
+		&json.Password,
 		&json.CreatedAt,
 		&json.ModifiedAt,
 		&json.Expires,
+		&fault,
+		&json.Method,
+		&json.Route,
+		&json.Status,
+		&headers,
+		&json.ContentType,
+		&json.GraphQLSchema,
+		&websocket,
+		&callbacks,
+		&cors,
 	)
+	if err != nil {
+		return nil, err
+	}
 
-	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("json not found or expired")
+	if err := decodeJSONColumn(fault, &json.Fault); err != nil {
+		return nil, fmt.Errorf("failed to decode fault: %w", err)
+	}
+
+	if err := decodeJSONColumn(headers, &json.Headers); err != nil {
+		return nil, fmt.Errorf("failed to decode headers: %w", err)
 	}
 
+	if err := decodeJSONColumn(websocket, &json.WebSocket); err != nil {
+		return nil, fmt.Errorf("failed to decode websocket: %w", err)
+	}
+
+	if err := decodeJSONColumn(callbacks, &json.Callbacks); err != nil {
+		return nil, fmt.Errorf("failed to decode callbacks: %w", err)
+	}
+
+	if err := decodeJSONColumn(cors, &json.CORS); err != nil {
+		return nil, fmt.Errorf("failed to decode cors: %w", err)
+	}
+
+	return json, nil
+}
+
+// encodeJSONColumns encodes the JSON-typed columns of a JSON entity
+func encodeJSONColumns(json *models.JSON) (fault, headers, websocket, callbacks, cors sql.NullString, err error) {
+	if fault, err = encodeJSONColumn(json.Fault); err != nil {
+		return fault, headers, websocket, callbacks, cors, fmt.Errorf("failed to encode fault: %w", err)
+	}
+	if headers, err = encodeJSONColumn(json.Headers); err != nil {
+		return fault, headers, websocket, callbacks, cors, fmt.Errorf("failed to encode headers: %w", err)
+	}
+	if websocket, err = encodeJSONColumn(json.WebSocket); err != nil {
+		return fault, headers, websocket, callbacks, cors, fmt.Errorf("failed to encode websocket: %w", err)
+	}
+	if callbacks, err = encodeJSONColumn(json.Callbacks); err != nil {
+		return fault, headers, websocket, callbacks, cors, fmt.Errorf("failed to encode callbacks: %w", err)
+	}
+	if cors, err = encodeJSONColumn(json.CORS); err != nil {
+		return fault, headers, websocket, callbacks, cors, fmt.Errorf("failed to encode cors: %w", err)
+	}
+	return fault, headers, websocket, callbacks, cors, nil
+}
+
+// CreateJSON inserts a new JSON entity
+func (d *Database) CreateJSON(ctx context.Context, json *models.JSON) error {
+	query := `
+	INSERT INTO json (id, json, password, created_at, modified_at, expires, fault, method, route, status, headers, content_type,
+		graphql_schema, websocket, callbacks, cors)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	fault, headers, websocket, callbacks, cors, err := encodeJSONColumns(json)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get json: %w", err)
+		return err
 	}
 
+	_, err = d.conn.ExecContext(ctx, query, json.ID, json.Content, json.Password, json.CreatedAt, json.ModifiedAt, json.Expires,
+		fault, json.Method, json.Route, json.Status, headers, json.ContentType, json.GraphQLSchema, websocket, callbacks, cors)
+	return err
+}
+
+// CreateRecordedJSON inserts a mock captured by the recording proxy. Only one recording
+// may be bound to a method and route; a second fails with "json already recorded for route".
+func (d *Database) CreateRecordedJSON(ctx context.Context, json *models.JSON) error {
+	return d.RunInTx(ctx, func(tx *Database) error {
+		if err := tx.CreateJSON(ctx, json); err != nil {
+			return err
+		}
+
+		_, err := tx.conn.ExecContext(ctx, `UPDATE json SET recorded = 1 WHERE id = ?`, json.ID)
+		var sqliteErr sqlite3.Error
+		if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique {
+			return fmt.Errorf("json already recorded for route")
+		}
+		if err != nil {
+			return fmt.Errorf("failed to mark json as recorded: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetJSON retrieves a JSON entity by ID
+func (d *Database) GetJSON(ctx context.Context, id string) (*models.JSON, error) {
+	json, err := d.GetJSONWithPassword(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	json.Password = ""
 	return json, nil
 }
 
 // UpdateJSON updates an existing JSON entity
-func (d *Database) UpdateJSON(json *models.JSON) error {
+func (d *Database) UpdateJSON(ctx context.Context, json *models.JSON) error {
 	query := `
 	UPDATE json
-	SET json = ?, password = ?, modified_at = ?, expires = ?
+	SET json = ?, password = ?, modified_at = ?, expires = ?, fault = ?, method = ?, route = ?, status = ?, headers = ?,
+		content_type = ?, graphql_schema = ?, websocket = ?, callbacks = ?, cors = ?
 	WHERE id = ?
 	`
 
 	json.ModifiedAt = time.Now()
 
-	result, err := d.db.Exec(query, json.Content, json.Password, json.ModifiedAt, json.Expires, json.ID)
+	fault, headers, websocket, callbacks, cors, err := encodeJSONColumns(json)
+	if err != nil {
+		return err
+	}
+
+	result, err := d.conn.ExecContext(ctx, query, json.Content, json.Password, json.ModifiedAt, json.Expires,
+		fault, json.Method, json.Route, json.Status, headers, json.ContentType, json.GraphQLSchema, websocket, callbacks, cors, json.ID)
 	if err != nil {
 		return fmt.Errorf("failed to update json: %w", err)
 	}
@@ -126,44 +433,138 @@ func (d *Database) UpdateJSON(json *models.JSON) error {
 	return nil
 }
 
-// DeleteJSON deletes a JSON entity by ID
-func (d *Database) DeleteJSON(id string) error {
-	query := `DELETE FROM json WHERE id = ?`
+// DeleteJSON deletes a JSON entity by ID, with its attachments, journal and callback results
+func (d *Database) DeleteJSON(ctx context.Context, id string) error {
+	return d.RunInTx(ctx, func(tx *Database) error {
+		result, err := tx.conn.ExecContext(ctx, `DELETE FROM json WHERE id = ?`, id)
+		if err != nil {
+			return fmt.Errorf("failed to delete json: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+
+		if rowsAffected == 0 {
+			return fmt.Errorf("json not found")
+		}
+
+		// A mock recreated with the same ID, as seeding and imports do, starts afresh
+		for _, table := range []string{"attachments", "requests", "callback_results"} {
+			if _, err := tx.conn.ExecContext(ctx, `DELETE FROM `+table+` WHERE json_id = ?`, id); err != nil {
+				return fmt.Errorf("failed to delete %s of json: %w", table, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// GetJSONWithPassword retrieves a JSON entity by ID including the password
+func (d *Database) GetJSONWithPassword(ctx context.Context, id string) (*models.JSON, error) {
+	query := `SELECT ` + jsonColumns + ` FROM json WHERE id = ? AND expires > ?`
 
-	result, err := d.db.Exec(query, id)
-	if err != nil {
-		return fmt.Errorf("failed to delete json: %w", err)
+	json, err := scanJSON(d.conn.QueryRowContext(ctx, query, id, time.Now()))
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("json not found or expired")
 	}
 
-	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
+		return nil, fmt.Errorf("failed to get json: %w", err)
 	}
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("json not found")
+	return json, nil
+}
+
+// ListJSON retrieves every unexpired JSON entity including passwords, oldest first
+func (d *Database) ListJSON(ctx context.Context) ([]*models.JSON, error) {
+	query := `SELECT ` + jsonColumns + ` FROM json WHERE expires > ? ORDER BY created_at, id`
+
+	rows, err := d.conn.QueryContext(ctx, query, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list json: %w", err)
+	}
+	defer rows.Close()
+
+	var jsons []*models.JSON
+	for rows.Next() {
+		json, err := scanJSON(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan json: %w", err)
+		}
+		jsons = append(jsons, json)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list json: %w", err)
 	}
 
-	return nil
+	return jsons, nil
 }
 
-// GetJSONWithPassword retrieves a JSON entity by ID including the password
-func (d *Database) GetJSONWithPassword(id string) (*models.JSON, error) {
-	query := `
-	SELECT id, json, password, created_at, modified_at, expires
-	FROM json
-	WHERE id = ? AND expires > ?
-	`
+// JSONExists reports whether a JSON entity with this ID is stored, even if expired
+func (d *Database) JSONExists(ctx context.Context, id string) (bool, error) {
+	var count int
+	if err := d.conn.QueryRowContext(ctx, `SELECT COUNT(*) FROM json WHERE id = ?`, id).Scan(&count); err != nil {
+		return false, fmt.Errorf("failed to check json: %w", err)
+	}
+	return count > 0, nil
+}
 
-	json := &models.JSON{}
-	err := d.db.QueryRow(query, id, time.Now()).Scan(
-		&json.ID,
-		&json.Content,
-		&json.Password,
-		&json.CreatedAt,
-		&json.ModifiedAt,
-		&json.Expires,
+// FindJSONByRoute returns the unexpired mock bound to the route that best matches method and path.
+// Literal segments beat parameters, a bound method beats any method, and newer mocks win ties.
+// Candidates are matched on their routes alone; only the best one is loaded.
+func (d *Database) FindJSONByRoute(ctx context.Context, method, path string) (*models.JSON, error) {
+	query := `SELECT id, method, route FROM json
+	WHERE method IN ('', ?) AND route != '' AND expires > ?
+	ORDER BY modified_at DESC`
+
+	rows, err := d.conn.QueryContext(ctx, query, method, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to find json by route: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		bestID    string
+		bestScore = -1
 	)
+	for rows.Next() {
+		var id, boundMethod, route string
+		if err := rows.Scan(&id, &boundMethod, &route); err != nil {
+			return nil, fmt.Errorf("failed to scan route: %w", err)
+		}
+
+		score, ok := models.MatchRoute(route, path)
+		if !ok {
+			continue
+		}
+		if boundMethod != "" {
+			score++
+		}
+		if score > bestScore {
+			bestID, bestScore = id, score
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to find json by route: %w", err)
+	}
+
+	if bestScore < 0 {
+		return nil, fmt.Errorf("json not found or expired")
+	}
+
+	return d.GetJSON(ctx, bestID)
+}
+
+// GetJSONByRoute retrieves the unexpired mock bound to exactly this method and route pattern
+func (d *Database) GetJSONByRoute(ctx context.Context, method, route string) (*models.JSON, error) {
+	query := `SELECT ` + jsonColumns + ` FROM json
+	WHERE method = ? AND route = ? AND expires > ?
+	ORDER BY modified_at DESC LIMIT 1`
+
+	json, err := scanJSON(d.conn.QueryRowContext(ctx, query, method, route, time.Now()))
 
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("json not found or expired")
@@ -176,23 +577,53 @@ func (d *Database) GetJSONWithPassword(id string) (*models.JSON, error) {
 	return json, nil
 }
 
-// CleanupExpired removes expired JSON entities
-func (d *Database) CleanupExpired() error {
-	query := `DELETE FROM json WHERE expires <= ?`
+// GetRecordedJSON retrieves the proxy's recording of a method and route, expired or not.
+// Mocks created through the API are never returned, so recording cannot overwrite them.
+func (d *Database) GetRecordedJSON(ctx context.Context, method, route string) (*models.JSON, error) {
+	query := `SELECT ` + jsonColumns + ` FROM json WHERE recorded = 1 AND method = ? AND route = ?`
+
+	json, err := scanJSON(d.conn.QueryRowContext(ctx, query, method, route))
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("json not found")
+	}
 
-	result, err := d.db.Exec(query, time.Now())
 	if err != nil {
-		return fmt.Errorf("failed to cleanup expired jsons: %w", err)
+		return nil, fmt.Errorf("failed to get json: %w", err)
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	return json, nil
+}
+
+// CleanupExpired removes expired JSON entities and returns their IDs
+func (d *Database) CleanupExpired(ctx context.Context) ([]string, error) {
+	query := `DELETE FROM json WHERE expires <= ? RETURNING id`
+
+	rows, err := d.conn.QueryContext(ctx, query, time.Now())
 	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
+		return nil, fmt.Errorf("failed to cleanup expired jsons: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan expired json: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to cleanup expired jsons: %w", err)
 	}
 
-	if rowsAffected > 0 {
-		log.Printf("Cleaned up %d expired JSON entities", rowsAffected)
+	if len(ids) > 0 {
+		slog.InfoContext(ctx, "Cleaned up expired JSON entities", "count", len(ids))
 	}
 
-	return nil
+	if _, err := d.conn.ExecContext(ctx, `DELETE FROM attachments WHERE json_id NOT IN (SELECT id FROM json)`); err != nil {
+		return ids, fmt.Errorf("failed to cleanup orphaned attachments: %w", err)
+	}
+
+	return ids, nil
 }
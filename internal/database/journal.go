@@ -0,0 +1,154 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"mockj-go/internal/models"
+)
+
+// RecordRequest appends a served request to the journal
+func (d *Database) RecordRequest(ctx context.Context, entry *models.RequestLog) error {
+	query := `
+	INSERT INTO requests (json_id, method, path, query, headers, body, status, created_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	headers, err := encodeJSONColumn(entry.Headers)
+	if err != nil {
+		return fmt.Errorf("failed to encode headers: %w", err)
+	}
+
+	result, err := d.conn.ExecContext(ctx, query, entry.MockID, entry.Method, entry.Path, entry.Query, headers.String, entry.Body, entry.Status, entry.Timestamp)
+	if err != nil {
+		return fmt.Errorf("failed to record request: %w", err)
+	}
+
+	entry.ID, err = result.LastInsertId()
+	return err
+}
+
+// ListRequests returns journal entries matching the filter, newest first
+func (d *Database) ListRequests(ctx context.Context, filter models.RequestFilter) ([]*models.RequestLog, error) {
+	var (
+		conditions []string
+		args       []interface{}
+	)
+
+	if filter.MockID != "" {
+		conditions = append(conditions, "json_id = ?")
+		args = append(args, filter.MockID)
+	}
+	if filter.Method != "" {
+		conditions = append(conditions, "method = ?")
+		args = append(args, strings.ToUpper(filter.Method))
+	}
+	if filter.Status != 0 {
+		conditions = append(conditions, "status = ?")
+		args = append(args, filter.Status)
+	}
+	if !filter.Since.IsZero() {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		conditions = append(conditions, "created_at <= ?")
+		args = append(args, filter.Until)
+	}
+
+	query := `SELECT id, json_id, method, path, query, headers, body, status, created_at FROM requests`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY id DESC"
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := d.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list requests: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []*models.RequestLog{}
+	for rows.Next() {
+		entry := &models.RequestLog{}
+		var headers string
+		if err := rows.Scan(
+			&entry.ID,
+			&entry.MockID,
+			&entry.Method,
+			&entry.Path,
+			&entry.Query,
+			&headers,
+			&entry.Body,
+			&entry.Status,
+			&entry.Timestamp,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan request: %w", err)
+		}
+
+		if err := decodeJSONColumn(sql.NullString{String: headers, Valid: true}, &entry.Headers); err != nil {
+			return nil, fmt.Errorf("failed to decode headers: %w", err)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// ClearRequests deletes all journal entries of a mock
+func (d *Database) ClearRequests(ctx context.Context, jsonID string) (int64, error) {
+	result, err := d.conn.ExecContext(ctx, `DELETE FROM requests WHERE json_id = ?`, jsonID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to clear requests: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// CleanupJournal drops entries older than retention and keeps at most maxEntries rows
+func (d *Database) CleanupJournal(ctx context.Context, retention time.Duration, maxEntries int) error {
+	var removed int64
+
+	if retention > 0 {
+		result, err := d.conn.ExecContext(ctx, `DELETE FROM requests WHERE created_at < ?`, time.Now().Add(-retention))
+		if err != nil {
+			return fmt.Errorf("failed to cleanup old requests: %w", err)
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		removed += rowsAffected
+	}
+
+	if maxEntries > 0 {
+		query := `
+		DELETE FROM requests
+		WHERE id <= (SELECT id FROM requests ORDER BY id DESC LIMIT 1 OFFSET ?)
+		`
+		result, err := d.conn.ExecContext(ctx, query, maxEntries)
+		if err != nil {
+			return fmt.Errorf("failed to trim requests: %w", err)
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		removed += rowsAffected
+	}
+
+	if removed > 0 {
+		slog.InfoContext(ctx, "Cleaned up journal entries", "count", removed)
+	}
+
+	return nil
+}
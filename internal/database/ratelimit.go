@@ -0,0 +1,52 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// refillTokens is the number of tokens in a bucket row once it has been refilled up to ?3,
+// given its capacity ?2 and refill rate ?4 in tokens per second. Clocks of replicas may
+// disagree, so time never runs backwards for a bucket.
+const refillTokens = `MIN(?2, tokens + MAX(0, ?3 - updated_at) * ?4)`
+
+// TakeRateLimitToken removes a token from the bucket of key if one is available, creating a
+// full bucket of burst tokens for a new key. It returns the tokens left and whether one was
+// taken. The bucket is updated in one statement, so replicas sharing the database share it.
+func (d *Database) TakeRateLimitToken(ctx context.Context, key string, burst int, rate float64, now time.Time) (float64, bool, error) {
+	query := `
+	INSERT INTO rate_limits (key, tokens, allowed, burst, rate, updated_at)
+	VALUES (?1, ?2 - 1, 1, ?2, ?4, ?3)
+	ON CONFLICT(key) DO UPDATE SET
+		allowed = ` + refillTokens + ` >= 1,
+		tokens = ` + refillTokens + ` - (` + refillTokens + ` >= 1),
+		burst = ?2,
+		rate = ?4,
+		updated_at = MAX(updated_at, ?3)
+	RETURNING tokens, allowed
+	`
+
+	var (
+		tokens  float64
+		allowed bool
+	)
+	if err := d.conn.QueryRowContext(ctx, query, key, burst, unixSeconds(now), rate).Scan(&tokens, &allowed); err != nil {
+		return 0, false, fmt.Errorf("failed to take rate limit token: %w", err)
+	}
+
+	return tokens, allowed, nil
+}
+
+// CleanupRateLimits removes buckets that have refilled since their last use
+func (d *Database) CleanupRateLimits(ctx context.Context, now time.Time) error {
+	_, err := d.conn.ExecContext(ctx, `DELETE FROM rate_limits WHERE tokens + (? - updated_at) * rate >= burst`, unixSeconds(now))
+	if err != nil {
+		return fmt.Errorf("failed to cleanup rate limits: %w", err)
+	}
+	return nil
+}
+
+func unixSeconds(t time.Time) float64 {
+	return float64(t.UnixNano()) / float64(time.Second)
+}
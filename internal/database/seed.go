@@ -0,0 +1,47 @@
+package database
+
+import (
+	"context"
+	"fmt"
+)
+
+// SaveSeed records that the mock jsonID was loaded from the fixture at path
+func (d *Database) SaveSeed(ctx context.Context, path, jsonID string) error {
+	query := `
+	INSERT INTO seeds (path, json_id) VALUES (?, ?)
+	ON CONFLICT(path) DO UPDATE SET json_id = excluded.json_id
+	`
+
+	if _, err := d.conn.ExecContext(ctx, query, path, jsonID); err != nil {
+		return fmt.Errorf("failed to save seed: %w", err)
+	}
+	return nil
+}
+
+// ListSeeds returns the IDs of seeded mocks keyed by the path of their fixture
+func (d *Database) ListSeeds(ctx context.Context) (map[string]string, error) {
+	rows, err := d.conn.QueryContext(ctx, `SELECT path, json_id FROM seeds`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list seeds: %w", err)
+	}
+	defer rows.Close()
+
+	seeds := map[string]string{}
+	for rows.Next() {
+		var path, jsonID string
+		if err := rows.Scan(&path, &jsonID); err != nil {
+			return nil, fmt.Errorf("failed to scan seed: %w", err)
+		}
+		seeds[path] = jsonID
+	}
+
+	return seeds, rows.Err()
+}
+
+// DeleteSeed forgets the fixture at path. Its mock is left to the caller.
+func (d *Database) DeleteSeed(ctx context.Context, path string) error {
+	if _, err := d.conn.ExecContext(ctx, `DELETE FROM seeds WHERE path = ?`, path); err != nil {
+		return fmt.Errorf("failed to delete seed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"mockj-go/internal/models"
+)
+
+const webhookColumns = `id, url, events, json_id, secret, password, created_at`
+
+const deliveryColumns = `id, webhook_id, event_id, event_type, json_id, payload, state, attempts,
+	response_status, error, next_attempt_at, created_at, updated_at`
+
+func scanWebhook(row rowScanner) (*models.Webhook, error) {
+	webhook := &models.Webhook{}
+	var events sql.NullString
+	err := row.Scan(
+		&webhook.ID,
+		&webhook.URL,
+		&events,
+		&webhook.MockID,
+		&webhook.Secret,
+		&webhook.Password,
+		&webhook.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := decodeJSONColumn(events, &webhook.Events); err != nil {
+		return nil, fmt.Errorf("failed to decode events: %w", err)
+	}
+	return webhook, nil
+}
+
+func scanDelivery(row rowScanner) (*models.WebhookDelivery, error) {
+	delivery := &models.WebhookDelivery{}
+	err := row.Scan(
+		&delivery.ID,
+		&delivery.WebhookID,
+		&delivery.EventID,
+		&delivery.EventType,
+		&delivery.MockID,
+		&delivery.Payload,
+		&delivery.State,
+		&delivery.Attempts,
+		&delivery.ResponseStatus,
+		&delivery.Error,
+		&delivery.NextAttemptAt,
+		&delivery.CreatedAt,
+		&delivery.UpdatedAt,
+	)
+	return delivery, err
+}
+
+// CreateWebhook stores a webhook subscription
+func (d *Database) CreateWebhook(ctx context.Context, webhook *models.Webhook) error {
+	query := `INSERT INTO webhooks (` + webhookColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	events, err := encodeJSONColumn(webhook.Events)
+	if err != nil {
+		return fmt.Errorf("failed to encode events: %w", err)
+	}
+
+	_, err = d.conn.ExecContext(ctx, query, webhook.ID, webhook.URL, events, webhook.MockID, webhook.Secret, webhook.Password, webhook.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook: %w", err)
+	}
+	return nil
+}
+
+// GetWebhook retrieves a webhook with its secret and password hash
+func (d *Database) GetWebhook(ctx context.Context, id string) (*models.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE id = ?`
+
+	webhook, err := scanWebhook(d.conn.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("webhook not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook: %w", err)
+	}
+
+	return webhook, nil
+}
+
+// ListWebhooks returns every webhook, oldest first
+func (d *Database) ListWebhooks(ctx context.Context) ([]*models.Webhook, error) {
+	rows, err := d.conn.QueryContext(ctx, `SELECT `+webhookColumns+` FROM webhooks ORDER BY created_at`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	webhooks := []*models.Webhook{}
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook: %w", err)
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
+	return webhooks, rows.Err()
+}
+
+// DeleteWebhook removes a webhook and its delivery log
+func (d *Database) DeleteWebhook(ctx context.Context, id string) error {
+	return d.RunInTx(ctx, func(tx *Database) error {
+		result, err := tx.conn.ExecContext(ctx, `DELETE FROM webhooks WHERE id = ?`, id)
+		if err != nil {
+			return fmt.Errorf("failed to delete webhook: %w", err)
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if rowsAffected == 0 {
+			return fmt.Errorf("webhook not found")
+		}
+
+		if _, err := tx.conn.ExecContext(ctx, `DELETE FROM webhook_deliveries WHERE webhook_id = ?`, id); err != nil {
+			return fmt.Errorf("failed to delete deliveries: %w", err)
+		}
+		return nil
+	})
+}
+
+// CreateDelivery queues a delivery
+func (d *Database) CreateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	query := `
+	INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, json_id, payload, state, attempts,
+		response_status, error, next_attempt_at, created_at, updated_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	result, err := d.conn.ExecContext(ctx, query, delivery.WebhookID, delivery.EventID, delivery.EventType, delivery.MockID,
+		delivery.Payload, delivery.State, delivery.Attempts, delivery.ResponseStatus, delivery.Error,
+		delivery.NextAttemptAt, delivery.CreatedAt, delivery.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create delivery: %w", err)
+	}
+
+	delivery.ID, err = result.LastInsertId()
+	return err
+}
+
+// UpdateDelivery stores the outcome of a delivery attempt
+func (d *Database) UpdateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	query := `
+	UPDATE webhook_deliveries
+	SET state = ?, attempts = ?, response_status = ?, error = ?, next_attempt_at = ?, updated_at = ?
+	WHERE id = ?
+	`
+
+	_, err := d.conn.ExecContext(ctx, query, delivery.State, delivery.Attempts, delivery.ResponseStatus, delivery.Error,
+		delivery.NextAttemptAt, delivery.UpdatedAt, delivery.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update delivery: %w", err)
+	}
+	return nil
+}
+
+// DueDeliveries returns up to limit pending deliveries whose next attempt is due, oldest first
+func (d *Database) DueDeliveries(ctx context.Context, now time.Time, limit int) ([]*models.WebhookDelivery, error) {
+	query := `SELECT ` + deliveryColumns + ` FROM webhook_deliveries
+	WHERE state = ? AND next_attempt_at <= ? ORDER BY id LIMIT ?`
+
+	return d.queryDeliveries(ctx, query, models.DeliveryPending, now, limit)
+}
+
+// ListDeliveries returns the delivery log of a webhook, newest first, optionally only in one state
+func (d *Database) ListDeliveries(ctx context.Context, webhookID, state string, limit int) ([]*models.WebhookDelivery, error) {
+	query := `SELECT ` + deliveryColumns + ` FROM webhook_deliveries WHERE webhook_id = ?`
+	args := []interface{}{webhookID}
+	if state != "" {
+		query += ` AND state = ?`
+		args = append(args, state)
+	}
+	query += ` ORDER BY id DESC LIMIT ?`
+	args = append(args, limit)
+
+	return d.queryDeliveries(ctx, query, args...)
+}
+
+func (d *Database) queryDeliveries(ctx context.Context, query string, args ...interface{}) ([]*models.WebhookDelivery, error) {
+	rows, err := d.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []*models.WebhookDelivery{}
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan delivery: %w", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// CleanupDeliveries drops finished deliveries last attempted before retention
+func (d *Database) CleanupDeliveries(ctx context.Context, retention time.Duration) error {
+	result, err := d.conn.ExecContext(ctx, `DELETE FROM webhook_deliveries WHERE state != ? AND updated_at < ?`,
+		models.DeliveryPending, time.Now().Add(-retention))
+	if err != nil {
+		return fmt.Errorf("failed to cleanup deliveries: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected > 0 {
+		slog.InfoContext(ctx, "Cleaned up webhook deliveries", "count", rowsAffected)
+	}
+
+	return nil
+}
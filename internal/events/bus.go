@@ -0,0 +1,122 @@
+// Package events is an in-process publish/subscribe bus for mock lifecycle events.
+// Handlers and background routines publish; watch streams and other subsystems subscribe.
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Mock lifecycle event types
+const (
+	Created = "created"
+	Updated = "updated"
+	Deleted = "deleted"
+	Expired = "expired"
+)
+
+// historySize is the number of recent events kept for subscribers resuming after a disconnect
+const historySize = 256
+
+// subscriberBuffer is the number of events a subscriber may fall behind before it is
+// dropped. It holds the whole history, so a resuming subscriber is never dropped by its replay.
+const subscriberBuffer = historySize
+
+// Event is one published event. IDs increase by one per event across the bus.
+type Event struct {
+	ID     uint64      `json:"id"`
+	Type   string      `json:"type"`
+	MockID string      `json:"mockId,omitempty"`
+	Time   time.Time   `json:"time"`
+	Data   interface{} `json:"data,omitempty"`
+}
+
+// Bus delivers published events to every subscriber whose filter accepts them
+type Bus struct {
+	mu          sync.Mutex
+	nextID      uint64
+	history     []Event
+	subscribers map[*Subscription]struct{}
+}
+
+// Subscription receives events on C until it is closed. C is also closed when the
+// subscriber falls too far behind, so slow readers never hold up publishers.
+type Subscription struct {
+	C <-chan Event
+
+	bus    *Bus
+	events chan Event
+	filter func(Event) bool
+}
+
+func NewBus() *Bus {
+	return &Bus{
+		nextID:      1,
+		subscribers: map[*Subscription]struct{}{},
+	}
+}
+
+// Publish sends an event to the current subscribers. Data must not be modified afterwards.
+func (b *Bus) Publish(eventType, mockID string, data interface{}) Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	event := Event{ID: b.nextID, Type: eventType, MockID: mockID, Time: time.Now(), Data: data}
+	b.nextID++
+
+	b.history = append(b.history, event)
+	if len(b.history) > historySize {
+		b.history = b.history[len(b.history)-historySize:]
+	}
+
+	for sub := range b.subscribers {
+		b.deliver(sub, event)
+	}
+	return event
+}
+
+// Subscribe returns a subscription to the events accepted by filter, or all events when
+// filter is nil. A non-zero after first replays the retained events that followed it.
+func (b *Bus) Subscribe(after uint64, filter func(Event) bool) *Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	events := make(chan Event, subscriberBuffer)
+	sub := &Subscription{C: events, bus: b, events: events, filter: filter}
+	b.subscribers[sub] = struct{}{}
+
+	if after > 0 {
+		for _, event := range b.history {
+			if event.ID > after {
+				b.deliver(sub, event)
+			}
+		}
+	}
+	return sub
+}
+
+// deliver queues an event for a subscriber, dropping the subscriber when its buffer is full
+func (b *Bus) deliver(sub *Subscription, event Event) {
+	if _, ok := b.subscribers[sub]; !ok {
+		return
+	}
+	if sub.filter != nil && !sub.filter(event) {
+		return
+	}
+	select {
+	case sub.events <- event:
+	default:
+		delete(b.subscribers, sub)
+		close(sub.events)
+	}
+}
+
+// Close ends the subscription and closes C
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	if _, ok := s.bus.subscribers[s]; ok {
+		delete(s.bus.subscribers, s)
+		close(s.events)
+	}
+}
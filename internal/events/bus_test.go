@@ -0,0 +1,68 @@
+package events
+
+import "testing"
+
+func TestBus(t *testing.T) {
+	t.Run("DeliversFilteredEvents", func(t *testing.T) {
+		bus := NewBus()
+		sub := bus.Subscribe(0, func(event Event) bool { return event.MockID == "a" })
+		defer sub.Close()
+
+		bus.Publish(Created, "a", nil)
+		bus.Publish(Created, "b", nil)
+		bus.Publish(Deleted, "a", nil)
+
+		for _, expected := range []Event{{ID: 1, Type: Created}, {ID: 3, Type: Deleted}} {
+			event := <-sub.C
+			if event.ID != expected.ID || event.Type != expected.Type || event.MockID != "a" {
+				t.Errorf("Expected event %d %s, got %+v", expected.ID, expected.Type, event)
+			}
+		}
+	})
+
+	t.Run("ReplaysAfterID", func(t *testing.T) {
+		bus := NewBus()
+		for i := 0; i < 3; i++ {
+			bus.Publish(Updated, "a", nil)
+		}
+
+		sub := bus.Subscribe(1, nil)
+		defer sub.Close()
+		if first, second := <-sub.C, <-sub.C; first.ID != 2 || second.ID != 3 {
+			t.Errorf("Expected events 2 and 3, got %d and %d", first.ID, second.ID)
+		}
+	})
+
+	t.Run("ReplaysWholeHistory", func(t *testing.T) {
+		bus := NewBus()
+		for i := 0; i < historySize+10; i++ {
+			bus.Publish(Updated, "a", nil)
+		}
+
+		sub := bus.Subscribe(1, nil)
+		defer sub.Close()
+		for i := 0; i < historySize; i++ {
+			if _, ok := <-sub.C; !ok {
+				t.Fatalf("Expected the replay of %d events, dropped after %d", historySize, i)
+			}
+		}
+	})
+
+	t.Run("DropsLaggingSubscribers", func(t *testing.T) {
+		bus := NewBus()
+		sub := bus.Subscribe(0, nil)
+		for i := 0; i <= subscriberBuffer; i++ {
+			bus.Publish(Updated, "a", nil)
+		}
+
+		received := 0
+		for range sub.C {
+			received++
+		}
+		if received != subscriberBuffer {
+			t.Errorf("Expected %d buffered events before the drop, got %d", subscriberBuffer, received)
+		}
+		// Closing a dropped subscription is harmless
+		sub.Close()
+	})
+}
@@ -0,0 +1,158 @@
+package graphql
+
+import "strings"
+
+// Location is a 1-based position in a GraphQL document
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Document is a parsed executable document: operations and fragments
+type Document struct {
+	Operations []*Operation
+	Fragments  []*Fragment
+}
+
+// Operation types
+const (
+	Query        = "query"
+	Mutation     = "mutation"
+	Subscription = "subscription"
+)
+
+type Operation struct {
+	Type       string
+	Name       string
+	Variables  []*VariableDefinition
+	Directives []*Directive
+	Selections []Selection
+	Loc        Location
+}
+
+type VariableDefinition struct {
+	Name    string
+	Type    *TypeRef
+	Default *Value
+	Loc     Location
+}
+
+type Fragment struct {
+	Name          string
+	TypeCondition string
+	Directives    []*Directive
+	Selections    []Selection
+	Loc           Location
+}
+
+// Selection is a *Field, *FragmentSpread or *InlineFragment
+type Selection interface {
+	location() Location
+}
+
+type Field struct {
+	Alias      string
+	Name       string
+	Arguments  []*Argument
+	Directives []*Directive
+	Selections []Selection
+	Loc        Location
+}
+
+// ResponseKey is the key of the field in the response: its alias, or else its name
+func (f *Field) ResponseKey() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+	return f.Name
+}
+
+type FragmentSpread struct {
+	Name       string
+	Directives []*Directive
+	Loc        Location
+}
+
+type InlineFragment struct {
+	TypeCondition string
+	Directives    []*Directive
+	Selections    []Selection
+	Loc           Location
+}
+
+func (f *Field) location() Location          { return f.Loc }
+func (f *FragmentSpread) location() Location { return f.Loc }
+func (f *InlineFragment) location() Location { return f.Loc }
+
+type Argument struct {
+	Name  string
+	Value *Value
+	Loc   Location
+}
+
+type Directive struct {
+	Name      string
+	Arguments []*Argument
+	Loc       Location
+}
+
+// Kinds of Value
+const (
+	VariableValue = iota
+	IntValue
+	FloatValue
+	StringValue
+	BooleanValue
+	NullValue
+	EnumValue
+	ListValue
+	ObjectValue
+)
+
+// Value is an input value literal
+type Value struct {
+	Kind   int
+	Raw    string // Variable name, scalar text or enum name
+	List   []*Value
+	Fields []*ObjectField
+	Loc    Location
+}
+
+type ObjectField struct {
+	Name  string
+	Value *Value
+	Loc   Location
+}
+
+// TypeRef is a named, list or non-null type reference
+type TypeRef struct {
+	Name    string   // Named type; empty for lists
+	Elem    *TypeRef // Element type of lists
+	NonNull bool
+}
+
+// NamedType returns the name of the type under any list and non-null wrappers
+func (t *TypeRef) NamedType() string {
+	for t.Elem != nil {
+		t = t.Elem
+	}
+	return t.Name
+}
+
+// Nullable returns the type without its non-null wrapper
+func (t *TypeRef) Nullable() *TypeRef {
+	return &TypeRef{Name: t.Name, Elem: t.Elem}
+}
+
+func (t *TypeRef) String() string {
+	var b strings.Builder
+	if t.Elem != nil {
+		b.WriteString("[" + t.Elem.String() + "]")
+	} else {
+		b.WriteString(t.Name)
+	}
+	if t.NonNull {
+		b.WriteString("!")
+	}
+	return b.String()
+}
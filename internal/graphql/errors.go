@@ -0,0 +1,52 @@
+package graphql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Error codes reported in the extensions of request errors
+const (
+	CodeParseFailed      = "GRAPHQL_PARSE_FAILED"
+	CodeValidationFailed = "GRAPHQL_VALIDATION_FAILED"
+	CodeBadUserInput     = "BAD_USER_INPUT"
+	CodeOperationInvalid = "OPERATION_RESOLUTION_FAILURE"
+	CodeQueryTooComplex  = "QUERY_TOO_COMPLEX"
+)
+
+// Error is a GraphQL error as it appears in the "errors" list of a response
+type Error struct {
+	Message    string                 `json:"message"`
+	Locations  []Location             `json:"locations,omitempty"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+func (e *Error) Error() string {
+	if len(e.Locations) == 0 {
+		return e.Message
+	}
+	return fmt.Sprintf("%s (%d:%d)", e.Message, e.Locations[0].Line, e.Locations[0].Column)
+}
+
+func newError(code string, loc *Location, format string, args ...interface{}) *Error {
+	err := &Error{Message: fmt.Sprintf(format, args...)}
+	if loc != nil {
+		err.Locations = []Location{*loc}
+	}
+	if code != "" {
+		err.Extensions = map[string]interface{}{"code": code}
+	}
+	return err
+}
+
+// SchemaError lists every problem of an invalid schema
+type SchemaError []*Error
+
+func (e SchemaError) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return "invalid schema: " + strings.Join(messages, "; ")
+}
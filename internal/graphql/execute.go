@@ -0,0 +1,562 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strconv"
+)
+
+// generatedListLength is the number of items generated for list fields missing from the data
+const generatedListLength = 2
+
+// Request is a GraphQL request as sent by clients
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// Response is the result of a request. Requests that fail before execution have no data;
+// field errors leave data with null in place of the failed fields.
+type Response struct {
+	Errors  []*Error
+	Data    interface{}
+	HasData bool
+}
+
+// MarshalJSON writes errors before data, as the spec recommends
+func (r *Response) MarshalJSON() ([]byte, error) {
+	response := object{}
+	if len(r.Errors) > 0 {
+		response = append(response, member{key: "errors", value: r.Errors})
+	}
+	if r.HasData {
+		response = append(response, member{key: "data", value: r.Data})
+	}
+	return json.Marshal(response)
+}
+
+// object is a response object that keeps fields in selection order
+type object []member
+
+type member struct {
+	key   string
+	value interface{}
+}
+
+func (o object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, m := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(m.key)
+		buf.Write(key)
+		buf.WriteByte(':')
+		value, err := json.Marshal(m.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// Resolvers are values in the data that are computed when selected, with the field's arguments
+type Resolver func(args map[string]interface{}) interface{}
+
+// Execute answers a request from data: a JSON object with one member per root operation
+// type, such as {"Query": {...}, "Mutation": {...}}, holding the value of each root field.
+// Values missing from the data are generated from the schema.
+func (s *Schema) Execute(req Request, data interface{}) *Response {
+	doc, err := Parse(req.Query)
+	if err != nil {
+		gqlErr, ok := err.(*Error)
+		if !ok {
+			gqlErr = &Error{Message: err.Error()}
+		}
+		gqlErr.Extensions = map[string]interface{}{"code": CodeParseFailed}
+		return &Response{Errors: []*Error{gqlErr}}
+	}
+
+	if errs := s.Validate(doc); len(errs) > 0 {
+		return &Response{Errors: errs}
+	}
+
+	op, opErr := selectOperation(doc, req.OperationName)
+	if opErr != nil {
+		return &Response{Errors: []*Error{opErr}}
+	}
+	if op.Type == Subscription {
+		return &Response{Errors: []*Error{newError(CodeOperationInvalid, &op.Loc, "Subscriptions are not supported.")}}
+	}
+
+	e := &executor{schema: s, fragments: map[string]*Fragment{}}
+	for _, fragment := range doc.Fragments {
+		e.fragments[fragment.Name] = fragment
+	}
+
+	if depth := e.depth(op.Selections, map[string]int{}); depth > MaxDepth {
+		return &Response{Errors: []*Error{newError(CodeQueryTooComplex, &op.Loc, "Query nests fields %d levels deep, more than the maximum of %d.", depth, MaxDepth)}}
+	}
+
+	var errs []*Error
+	if e.variables, errs = s.coerceVariables(op, req.Variables); len(errs) > 0 {
+		return &Response{Errors: errs}
+	}
+
+	root := s.rootType(op.Type)
+	var source map[string]interface{}
+	if m, ok := data.(map[string]interface{}); ok {
+		source, _ = m[root.Name].(map[string]interface{})
+	}
+
+	result, ok := e.selectionSet(root, source, op.Selections, nil, true)
+	if e.exceeded {
+		return &Response{Errors: []*Error{newError(CodeQueryTooComplex, &op.Loc, "Query resolves more than the maximum of %d fields.", MaxFields)}}
+	}
+	response := &Response{Errors: e.errs, HasData: true}
+	if ok {
+		response.Data = result
+	}
+	return response
+}
+
+// OperationType returns the type of the operation a request would execute, such as
+// "mutation", or "" when the query does not parse or names no operation. Transports
+// use it to refuse mutations over GET before executing anything.
+func OperationType(req Request) string {
+	doc, err := Parse(req.Query)
+	if err != nil {
+		return ""
+	}
+	op, opErr := selectOperation(doc, req.OperationName)
+	if opErr != nil {
+		return ""
+	}
+	return op.Type
+}
+
+func selectOperation(doc *Document, name string) (*Operation, *Error) {
+	if name == "" {
+		if len(doc.Operations) != 1 {
+			return nil, newError(CodeOperationInvalid, nil, "Must provide operation name if query contains multiple operations.")
+		}
+		return doc.Operations[0], nil
+	}
+	for _, op := range doc.Operations {
+		if op.Name == name {
+			return op, nil
+		}
+	}
+	return nil, newError(CodeOperationInvalid, nil, "Unknown operation named %q.", name)
+}
+
+type executor struct {
+	schema    *Schema
+	fragments map[string]*Fragment
+	variables map[string]interface{}
+	errs      []*Error
+	fields    int  // Fields resolved so far
+	exceeded  bool // More than MaxFields were resolved; execution stops
+}
+
+// fieldInfo identifies the field being completed, for error messages
+type fieldInfo struct {
+	parent *Type
+	name   string
+	fields []*Field
+}
+
+func (e *executor) fieldError(info fieldInfo, path []interface{}, format string, args ...interface{}) {
+	err := &Error{Message: fmt.Sprintf(format, args...), Path: append([]interface{}(nil), path...)}
+	for _, field := range info.fields {
+		err.Locations = append(err.Locations, field.Loc)
+	}
+	e.errs = append(e.errs, err)
+}
+
+// fieldGroup is the fields of a selection set that share a response key
+type fieldGroup struct {
+	key    string
+	fields []*Field
+}
+
+func (e *executor) collectFields(t *Type, selections []Selection, groups []fieldGroup, visited map[string]bool) []fieldGroup {
+	for _, selection := range selections {
+		switch sel := selection.(type) {
+		case *Field:
+			if !e.included(sel.Directives) {
+				continue
+			}
+			found := false
+			for i := range groups {
+				if groups[i].key == sel.ResponseKey() {
+					groups[i].fields = append(groups[i].fields, sel)
+					found = true
+					break
+				}
+			}
+			if !found {
+				groups = append(groups, fieldGroup{key: sel.ResponseKey(), fields: []*Field{sel}})
+			}
+		case *FragmentSpread:
+			if visited[sel.Name] || !e.included(sel.Directives) {
+				continue
+			}
+			visited[sel.Name] = true
+			fragment := e.fragments[sel.Name]
+			if e.applies(t, fragment.TypeCondition) {
+				groups = e.collectFields(t, fragment.Selections, groups, visited)
+			}
+		case *InlineFragment:
+			if !e.included(sel.Directives) || (sel.TypeCondition != "" && !e.applies(t, sel.TypeCondition)) {
+				continue
+			}
+			groups = e.collectFields(t, sel.Selections, groups, visited)
+		}
+	}
+	return groups
+}
+
+// applies reports whether a fragment on the type condition applies to an object type
+func (e *executor) applies(t *Type, condition string) bool {
+	conditionType := e.schema.Types[condition]
+	return conditionType == t || (conditionType.IsAbstract() && e.schema.isPossibleType(conditionType, t))
+}
+
+// included evaluates @skip and @include
+func (e *executor) included(directives []*Directive) bool {
+	for _, directive := range directives {
+		if directive.Name != "skip" && directive.Name != "include" {
+			continue
+		}
+		args := e.arguments(e.schema.Directives[directive.Name].Arguments, directive.Arguments)
+		if condition, _ := args["if"].(bool); condition == (directive.Name == "skip") {
+			return false
+		}
+	}
+	return true
+}
+
+// selectionSet executes selections on an object. A nil source generates every field.
+// fixture is false for introspection, whose missing fields are null rather than generated.
+func (e *executor) selectionSet(t *Type, source map[string]interface{}, selections []Selection, path []interface{}, fixture bool) (object, bool) {
+	result := object{}
+	for _, group := range e.collectFields(t, selections, nil, map[string]bool{}) {
+		if !e.countField() {
+			return nil, false
+		}
+		field := group.fields[0]
+		def := e.schema.fieldDefinition(t, field.Name)
+		fieldPath := append(path[:len(path):len(path)], group.key)
+		info := fieldInfo{parent: t, name: field.Name, fields: group.fields}
+
+		var (
+			value        interface{}
+			found        bool
+			fieldFixture = fixture
+		)
+		switch def {
+		case typenameField:
+			value, found = t.Name, true
+		case schemaField:
+			value, found, fieldFixture = e.schema.introspectSchema(), true, false
+		case typeField:
+			name, _ := e.arguments(def.Arguments, field.Arguments)["name"].(string)
+			value, found, fieldFixture = e.schema.introspectType(name), true, false
+		default:
+			value, found = e.resolve(source, def, field, fixture)
+		}
+
+		completed, ok := e.complete(def.Type, info, value, found, fieldPath, fieldFixture)
+		if !ok {
+			return nil, false
+		}
+		result = append(result, member{key: group.key, value: completed})
+	}
+	return result, true
+}
+
+// resolve looks the field up in its parent's data
+func (e *executor) resolve(source map[string]interface{}, def *FieldDefinition, field *Field, fixture bool) (interface{}, bool) {
+	if source == nil {
+		return nil, false
+	}
+	value, found := source[field.Name]
+	if !found {
+		return nil, false
+	}
+
+	args := e.arguments(def.Arguments, field.Arguments)
+	if resolver, ok := value.(Resolver); ok {
+		return resolver(args), true
+	}
+	if items, ok := value.([]interface{}); ok && fixture && len(args) > 0 {
+		return filterItems(items, args, def.Type), true
+	}
+	return value, true
+}
+
+// filterItems narrows list data to the items whose members equal the field's arguments,
+// so user(id: 2) can be answered from a list of users. Arguments no item has are ignored.
+// Fields that do not return a list get the first match.
+func filterItems(items []interface{}, args map[string]interface{}, t *TypeRef) interface{} {
+	matches := []interface{}{}
+	for _, item := range items {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return items
+		}
+		match := true
+		for name, arg := range args {
+			if value, ok := m[name]; ok && isScalar(arg) && isScalar(value) && fmt.Sprint(value) != fmt.Sprint(arg) {
+				match = false
+			}
+		}
+		if match {
+			matches = append(matches, item)
+		}
+	}
+
+	if t.Nullable().Elem != nil {
+		return matches
+	}
+	if len(matches) == 0 {
+		return nil
+	}
+	return matches[0]
+}
+
+func isScalar(value interface{}) bool {
+	switch value.(type) {
+	case string, bool, json.Number, int64, float64:
+		return true
+	}
+	return false
+}
+
+// complete turns a value into the response value of a field type. It returns false when
+// a non-null field is null, after reporting the error; the parent then becomes null.
+func (e *executor) complete(t *TypeRef, info fieldInfo, value interface{}, found bool, path []interface{}, fixture bool) (interface{}, bool) {
+	if t.NonNull {
+		completed, ok := e.completeNullable(t.Nullable(), info, value, found, path, fixture)
+		if ok && completed == nil {
+			e.fieldError(info, path, "Cannot return null for non-nullable field %s.%s.", info.parent.Name, info.name)
+			return nil, false
+		}
+		return completed, ok
+	}
+
+	completed, ok := e.completeNullable(t, info, value, found, path, fixture)
+	if !ok {
+		return nil, true
+	}
+	return completed, true
+}
+
+func (e *executor) completeNullable(t *TypeRef, info fieldInfo, value interface{}, found bool, path []interface{}, fixture bool) (interface{}, bool) {
+	if !found && !fixture {
+		return nil, true
+	}
+	if found && value == nil {
+		return nil, true
+	}
+
+	if t.Elem != nil {
+		var items []interface{}
+		if found {
+			var ok bool
+			if items, ok = value.([]interface{}); !ok {
+				e.fieldError(info, path, "Expected a list for field %s.%s, found %s.", info.parent.Name, info.name, describe(value))
+				return nil, false
+			}
+		} else {
+			items = make([]interface{}, generatedListLength)
+		}
+
+		result := make([]interface{}, len(items))
+		for i, item := range items {
+			if e.exceeded {
+				return nil, false
+			}
+			completed, ok := e.complete(t.Elem, info, item, found, append(path[:len(path):len(path)], i), fixture)
+			if !ok {
+				return nil, false
+			}
+			result[i] = completed
+		}
+		return result, true
+	}
+
+	named := e.schema.Types[t.Name]
+	if !found {
+		return e.generate(named, info, path)
+	}
+
+	switch named.Kind {
+	case KindScalar:
+		serialized, err := serializeScalar(named.Name, value)
+		if err != nil {
+			e.fieldError(info, path, "%s", err)
+			return nil, false
+		}
+		return serialized, true
+	case KindEnum:
+		if name, ok := value.(string); ok && named.HasEnumValue(name) {
+			return name, true
+		}
+		e.fieldError(info, path, "Enum %q cannot represent value: %s", named.Name, describe(value))
+		return nil, false
+	}
+
+	source, ok := value.(map[string]interface{})
+	if !ok {
+		e.fieldError(info, path, "Expected an object for field %s.%s, found %s.", info.parent.Name, info.name, describe(value))
+		return nil, false
+	}
+
+	objectType := named
+	if named.IsAbstract() {
+		possible := e.schema.PossibleTypes(named)
+		if len(possible) == 0 {
+			e.fieldError(info, path, "Abstract type %q has no implementations.", named.Name)
+			return nil, false
+		}
+		objectType = possible[0]
+		if typename, ok := source["__typename"].(string); ok {
+			if candidate := e.schema.Types[typename]; candidate != nil && e.schema.isPossibleType(named, candidate) {
+				objectType = candidate
+			} else {
+				e.fieldError(info, path, "Abstract type %q must resolve to an object type at runtime, received %q.", named.Name, typename)
+				return nil, false
+			}
+		}
+	}
+
+	return e.selectionSet(objectType, source, e.subselections(info.fields), path, fixture)
+}
+
+// subselections merges the selection sets of the fields sharing a response key
+func (e *executor) subselections(fields []*Field) []Selection {
+	if len(fields) == 1 {
+		return fields[0].Selections
+	}
+	var selections []Selection
+	for _, field := range fields {
+		selections = append(selections, field.Selections...)
+	}
+	return selections
+}
+
+// generate makes up a value for a field missing from the data. Values are derived from
+// the response path, so the same query always gets the same answer.
+func (e *executor) generate(t *Type, info fieldInfo, path []interface{}) (interface{}, bool) {
+	h := fnv.New32a()
+	for _, step := range path {
+		fmt.Fprint(h, step, "/")
+	}
+	seed := h.Sum32()
+
+	switch t.Kind {
+	case KindEnum:
+		return t.EnumValues[int(seed%uint32(len(t.EnumValues)))].Name, true
+	case KindScalar:
+		switch t.Name {
+		case "Int":
+			return int64(seed % 100), true
+		case "Float":
+			return float64(seed%10000) / 100, true
+		case "Boolean":
+			return seed%2 == 0, true
+		case "ID":
+			return strconv.Itoa(int(seed%1000) + 1), true
+		}
+		return fmt.Sprintf("%s %d", info.name, seed%100+1), true
+	}
+
+	objectType := t
+	if t.IsAbstract() {
+		possible := e.schema.PossibleTypes(t)
+		if len(possible) == 0 {
+			e.fieldError(info, path, "Abstract type %q has no implementations.", t.Name)
+			return nil, false
+		}
+		objectType = possible[int(seed%uint32(len(possible)))]
+	}
+	return e.selectionSet(objectType, nil, e.subselections(info.fields), path, true)
+}
+
+// serializeScalar converts data to the result value of a scalar. Custom scalars pass data through.
+func serializeScalar(scalar string, value interface{}) (interface{}, error) {
+	switch scalar {
+	case "Int":
+		if n, ok := toFloat(value); ok && n == math.Trunc(n) && n >= math.MinInt32 && n <= math.MaxInt32 {
+			return int64(n), nil
+		}
+		if b, ok := value.(bool); ok {
+			return map[bool]int64{false: 0, true: 1}[b], nil
+		}
+		return nil, fmt.Errorf("Int cannot represent non 32-bit signed integer value: %s", describe(value))
+	case "Float":
+		if n, ok := toFloat(value); ok {
+			return n, nil
+		}
+		if b, ok := value.(bool); ok {
+			return map[bool]float64{false: 0, true: 1}[b], nil
+		}
+		return nil, fmt.Errorf("Float cannot represent non numeric value: %s", describe(value))
+	case "String":
+		switch v := value.(type) {
+		case string:
+			return v, nil
+		case json.Number:
+			return v.String(), nil
+		case bool:
+			return strconv.FormatBool(v), nil
+		}
+		return nil, fmt.Errorf("String cannot represent value: %s", describe(value))
+	case "Boolean":
+		if b, ok := value.(bool); ok {
+			return b, nil
+		}
+		return nil, fmt.Errorf("Boolean cannot represent a non boolean value: %s", describe(value))
+	case "ID":
+		switch v := value.(type) {
+		case string:
+			return v, nil
+		case json.Number:
+			if _, err := v.Int64(); err == nil {
+				return v.String(), nil
+			}
+		}
+		return nil, fmt.Errorf("ID cannot represent value: %s", describe(value))
+	}
+	return value, nil
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		n, err := v.Float64()
+		return n, err == nil
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+// describe formats data for error messages
+func describe(value interface{}) string {
+	if b, err := json.Marshal(value); err == nil {
+		return string(b)
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,293 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const testSchema = `
+"A person"
+type User implements Node {
+  id: ID!
+  name: String!
+  age: Int
+  role: Role!
+  friends(first: Int = 10): [User!]!
+  nickname: String @deprecated(reason: "Use name")
+}
+
+interface Node { id: ID! }
+
+type Post implements Node {
+  id: ID!
+  title: String!
+}
+
+union SearchResult = User | Post
+
+enum Role { ADMIN MEMBER }
+
+input UserFilter {
+  role: Role
+  minAge: Int = 0
+}
+
+type Query {
+  me: User!
+  user(id: ID!): User
+  users(role: Role, filter: UserFilter): [User!]!
+  search(text: String!): [SearchResult!]!
+  count: Int!
+}
+
+type Mutation {
+  rename(id: ID!, name: String!): User
+}
+`
+
+const testData = `{
+  "Query": {
+    "me": {"id": "1", "name": "John", "age": 30, "role": "ADMIN"},
+    "user": [
+      {"id": "1", "name": "John", "role": "ADMIN"},
+      {"id": "2", "name": "Jane", "role": "MEMBER", "age": null}
+    ],
+    "users": [
+      {"id": "1", "name": "John", "role": "ADMIN"},
+      {"id": "2", "name": "Jane", "role": "MEMBER"}
+    ],
+    "search": [
+      {"__typename": "Post", "id": "10", "title": "Hello"},
+      {"__typename": "User", "id": "2", "name": "Jane", "role": "MEMBER"}
+    ],
+    "count": "many"
+  },
+  "Mutation": {
+    "rename": {"id": "1", "name": "Johnny", "role": "ADMIN"}
+  }
+}`
+
+func execute(t *testing.T, req Request) string {
+	t.Helper()
+
+	schema, err := ParseSchema(testSchema)
+	if err != nil {
+		t.Fatalf("Failed to parse schema: %v", err)
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(testData))
+	decoder.UseNumber()
+	var data interface{}
+	if err := decoder.Decode(&data); err != nil {
+		t.Fatalf("Failed to decode data: %v", err)
+	}
+
+	result, err := json.Marshal(schema.Execute(req, data))
+	if err != nil {
+		t.Fatalf("Failed to encode response: %v", err)
+	}
+	return string(result)
+}
+
+// compact normalizes expected JSON for comparison
+func compact(t *testing.T, expected string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, []byte(expected)); err != nil {
+		t.Fatalf("Invalid expected JSON: %v", err)
+	}
+	return buf.String()
+}
+
+func TestExecute(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      Request
+		expected string
+	}{
+		{
+			name:     "FieldsInSelectionOrder",
+			req:      Request{Query: `{ me { name id role } }`},
+			expected: `{"data": {"me": {"name": "John", "id": "1", "role": "ADMIN"}}}`,
+		},
+		{
+			name:     "ArgumentsPickFromLists",
+			req:      Request{Query: `query ($id: ID!) { user(id: $id) { name age } }`, Variables: map[string]interface{}{"id": "2"}},
+			expected: `{"data": {"user": {"name": "Jane", "age": null}}}`,
+		},
+		{
+			name:     "ArgumentsFilterLists",
+			req:      Request{Query: `{ users(role: MEMBER) { name } none: user(id: 99) { name } }`},
+			expected: `{"data": {"users": [{"name": "Jane"}], "none": null}}`,
+		},
+		{
+			name:     "AliasesAndFragments",
+			req:      Request{Query: `{ a: me { ...F } b: me { id @skip(if: true) } } fragment F on User { id ... on User { name } }`},
+			expected: `{"data": {"a": {"id": "1", "name": "John"}, "b": {}}}`,
+		},
+		{
+			name:     "AbstractTypes",
+			req:      Request{Query: `{ search(text: "x") { __typename ... on Node { id } ... on Post { title } } }`},
+			expected: `{"data": {"search": [{"__typename": "Post", "id": "10", "title": "Hello"}, {"__typename": "User", "id": "2"}]}}`,
+		},
+		{
+			name:     "Mutation",
+			req:      Request{Query: `mutation { rename(id: "1", name: "Johnny") { name } }`},
+			expected: `{"data": {"rename": {"name": "Johnny"}}}`,
+		},
+		{
+			name:     "NonNullErrorsPropagate",
+			req:      Request{Query: `{ count }`},
+			expected: `{"errors": [{"message": "Int cannot represent non 32-bit signed integer value: \"many\"", "locations": [{"line": 1, "column": 3}], "path": ["count"]}], "data": null}`,
+		},
+		{
+			name:     "OperationName",
+			req:      Request{Query: `query A { me { id } } query B { me { name } }`, OperationName: "B"},
+			expected: `{"data": {"me": {"name": "John"}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := execute(t, tt.req); got != compact(t, tt.expected) {
+				t.Errorf("Expected %s, got %s", compact(t, tt.expected), got)
+			}
+		})
+	}
+}
+
+func TestGeneratedValues(t *testing.T) {
+	query := Request{Query: `{ me { friends { id name age role friends { id } } } }`}
+
+	first := execute(t, query)
+	if first != execute(t, query) {
+		t.Errorf("Expected generated values to be stable")
+	}
+
+	var response struct {
+		Data struct {
+			Me struct {
+				Friends []struct {
+					ID   string `json:"id"`
+					Name string `json:"name"`
+					Age  *int   `json:"age"`
+					Role string `json:"role"`
+				} `json:"friends"`
+			} `json:"me"`
+		} `json:"data"`
+		Errors []interface{} `json:"errors"`
+	}
+	if err := json.Unmarshal([]byte(first), &response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	friends := response.Data.Me.Friends
+	if len(response.Errors) > 0 || len(friends) != generatedListLength {
+		t.Fatalf("Expected %d generated friends, got %s", generatedListLength, first)
+	}
+	for _, friend := range friends {
+		if friend.ID == "" || !strings.HasPrefix(friend.Name, "name ") || friend.Age == nil || (friend.Role != "ADMIN" && friend.Role != "MEMBER") {
+			t.Errorf("Unexpected generated friend %+v", friend)
+		}
+	}
+	if friends[0].ID == friends[1].ID && friends[0].Name == friends[1].Name {
+		t.Errorf("Expected list items to differ, got %+v", friends)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     Request
+		message string
+		code    string
+	}{
+		{"Syntax", Request{Query: `{ me { id }`}, `Syntax Error: Expected Name, found <EOF>.`, CodeParseFailed},
+		{"UnknownField", Request{Query: `{ me { email } }`}, `Cannot query field "email" on type "User".`, CodeValidationFailed},
+		{"MissingSubselection", Request{Query: `{ me }`}, `Field "me" of type "User!" must have a selection of subfields. Did you mean "me { ... }"?`, CodeValidationFailed},
+		{"LeafSubselection", Request{Query: `{ count { x } }`}, `Field "count" must not have a selection since type "Int!" has no subfields.`, CodeValidationFailed},
+		{"RequiredArgument", Request{Query: `{ user { id } }`}, `Argument "id" of type "ID!" is required on field "Query.user", but it was not provided.`, CodeValidationFailed},
+		{"InvalidLiteral", Request{Query: `{ users(role: OWNER) { id } }`}, `Value OWNER does not exist in "Role" enum.`, CodeValidationFailed},
+		{"InputObjectField", Request{Query: `{ users(filter: {minAge: "x"}) { id } }`}, `Int cannot represent value: "x"`, CodeValidationFailed},
+		{"UndefinedVariable", Request{Query: `{ user(id: $id) { id } }`}, `Variable "$id" is not defined by operation.`, CodeValidationFailed},
+		{"UnusedFragment", Request{Query: `{ me { id } } fragment F on User { id }`}, `Fragment "F" is never used.`, CodeValidationFailed},
+		{"FragmentCycle", Request{Query: `{ me { ...A } } fragment A on User { ...B } fragment B on User { ...A }`}, `Cannot spread fragment "A" within itself via B.`, CodeValidationFailed},
+		{"ImpossibleSpread", Request{Query: `{ me { ... on Post { id } } }`}, `Fragment cannot be spread here as objects of type "User" can never be of type "Post".`, CodeValidationFailed},
+		{"MissingVariable", Request{Query: `query ($id: ID!) { user(id: $id) { id } }`}, `Variable "$id" of required type "ID!" was not provided.`, CodeBadUserInput},
+		{"InvalidVariable", Request{Query: `query ($f: UserFilter) { users(filter: $f) { id } }`, Variables: map[string]interface{}{"f": map[string]interface{}{"role": "OWNER"}}}, `Variable "$f" got invalid value {"role":"OWNER"}; Value "OWNER" does not exist in "Role" enum.`, CodeBadUserInput},
+		{"AmbiguousOperation", Request{Query: `query A { count } query B { count }`}, `Must provide operation name if query contains multiple operations.`, CodeOperationInvalid},
+		{"TooDeep", Request{Query: nestedFriends(14)}, `Query nests fields 16 levels deep, more than the maximum of 15.`, CodeQueryTooComplex},
+		{"TooDeepThroughFragments", Request{Query: `{ me { ...A } } fragment A on User { friends { friends { friends { friends { ...B } } } } } fragment B on User { friends { friends { friends { friends { friends { friends { friends { friends { friends { friends { id } } } } } } } } } } }`}, `Query nests fields 16 levels deep, more than the maximum of 15.`, CodeQueryTooComplex},
+		{"TooManyFields", Request{Query: nestedFriends(13)}, `Query resolves more than the maximum of 10000 fields.`, CodeQueryTooComplex},
+		{"NestedTooDeeply", Request{Query: strings.Repeat("{ a ", maxNesting+1)}, `Syntax Error: Document nests deeper than 100 levels.`, CodeParseFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var response struct {
+				Data   *json.RawMessage `json:"data"`
+				Errors []Error          `json:"errors"`
+			}
+			got := execute(t, tt.req)
+			if err := json.Unmarshal([]byte(got), &response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+
+			if strings.Contains(got, `"data"`) {
+				t.Errorf("Expected no data for a request error, got %s", got)
+			}
+			if len(response.Errors) == 0 || response.Errors[0].Message != tt.message || response.Errors[0].Extensions["code"] != tt.code {
+				t.Errorf("Expected %q with code %s, got %s", tt.message, tt.code, got)
+			}
+		})
+	}
+}
+
+// nestedFriends selects the friends of friends n levels below me
+func nestedFriends(n int) string {
+	return "{ me { " + strings.Repeat("friends { ", n) + "id" + strings.Repeat(" }", n) + " } }"
+}
+
+func TestIntrospection(t *testing.T) {
+	got := execute(t, Request{Query: `{
+  __schema { queryType { name } mutationType { name } subscriptionType { name } }
+  user: __type(name: "User") {
+    kind description
+    interfaces { name }
+    fields(includeDeprecated: true) { name isDeprecated deprecationReason type { kind name ofType { kind name } } }
+  }
+  role: __type(name: "Role") { enumValues { name } }
+  filter: __type(name: "UserFilter") { inputFields { name defaultValue } }
+}`})
+
+	for _, expected := range []string{
+		`"__schema":{"queryType":{"name":"Query"},"mutationType":{"name":"Mutation"},"subscriptionType":null}`,
+		`"kind":"OBJECT","description":"A person","interfaces":[{"name":"Node"}]`,
+		`{"name":"id","isDeprecated":false,"deprecationReason":null,"type":{"kind":"NON_NULL","name":null,"ofType":{"kind":"SCALAR","name":"ID"}}}`,
+		`{"name":"nickname","isDeprecated":true,"deprecationReason":"Use name","type":{"kind":"SCALAR","name":"String","ofType":null}}`,
+		`"role":{"enumValues":[{"name":"ADMIN"},{"name":"MEMBER"}]}`,
+		`"filter":{"inputFields":[{"name":"role","defaultValue":null},{"name":"minAge","defaultValue":"0"}]}`,
+	} {
+		if !strings.Contains(got, expected) {
+			t.Errorf("Expected %s in %s", expected, got)
+		}
+	}
+}
+
+func TestParseSchemaErrors(t *testing.T) {
+	tests := map[string]string{
+		"NoQuery":          `type User { id: ID }`,
+		"UnknownType":      `type Query { user: Person }`,
+		"InputAsOutput":    `input I { a: Int } type Query { i: I }`,
+		"MissingInterface": `interface Node { id: ID! } type Query implements Node { name: String }`,
+		"Syntax":           `type Query { id: }`,
+	}
+
+	for name, sdl := range tests {
+		if _, err := ParseSchema(sdl); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package graphql
+
+// Introspection values are maps in the shape of the __Schema, __Type and related types.
+// Members that refer to other types are Resolvers, so cyclic type references are only
+// followed as far as a query selects them.
+
+func (s *Schema) introspectSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"description": optional(s.Description),
+		"types": Resolver(func(map[string]interface{}) interface{} {
+			types := make([]interface{}, len(s.typeNames))
+			for i, name := range s.typeNames {
+				types[i] = s.introspectType(name)
+			}
+			return types
+		}),
+		"queryType":        Resolver(func(map[string]interface{}) interface{} { return s.introspectType(s.Query) }),
+		"mutationType":     Resolver(func(map[string]interface{}) interface{} { return s.introspectType(s.Mutation) }),
+		"subscriptionType": Resolver(func(map[string]interface{}) interface{} { return s.introspectType(s.Subscription) }),
+		"directives": Resolver(func(map[string]interface{}) interface{} {
+			directives := make([]interface{}, len(s.directiveNames))
+			for i, name := range s.directiveNames {
+				directive := s.Directives[name]
+				locations := make([]interface{}, len(directive.Locations))
+				for j, location := range directive.Locations {
+					locations[j] = location
+				}
+				directives[i] = map[string]interface{}{
+					"name":         directive.Name,
+					"description":  optional(directive.Description),
+					"isRepeatable": directive.Repeatable,
+					"locations":    locations,
+					"args":         s.introspectInputValues(directive.Arguments),
+				}
+			}
+			return directives
+		}),
+	}
+}
+
+// introspectType returns the __Type of a named type, nil when there is no such type
+func (s *Schema) introspectType(name string) interface{} {
+	t, ok := s.Types[name]
+	if !ok {
+		return nil
+	}
+
+	value := map[string]interface{}{
+		"kind":           t.Kind,
+		"name":           t.Name,
+		"description":    optional(t.Description),
+		"specifiedByURL": nil,
+		"fields":         nil,
+		"interfaces":     nil,
+		"possibleTypes":  nil,
+		"enumValues":     nil,
+		"inputFields":    nil,
+		"ofType":         nil,
+		"isOneOf":        nil,
+	}
+
+	switch t.Kind {
+	case KindObject, KindInterface:
+		value["fields"] = Resolver(func(args map[string]interface{}) interface{} {
+			fields := []interface{}{}
+			for _, field := range t.Fields {
+				if field.Deprecation != nil && args["includeDeprecated"] != true {
+					continue
+				}
+				fields = append(fields, map[string]interface{}{
+					"name":              field.Name,
+					"description":       optional(field.Description),
+					"args":              s.introspectInputValues(field.Arguments),
+					"type":              s.introspectTypeRef(field.Type),
+					"isDeprecated":      field.Deprecation != nil,
+					"deprecationReason": deprecationReason(field.Deprecation),
+				})
+			}
+			return fields
+		})
+		value["interfaces"] = Resolver(func(map[string]interface{}) interface{} {
+			interfaces := []interface{}{}
+			for _, iface := range t.Interfaces {
+				interfaces = append(interfaces, s.introspectType(iface))
+			}
+			return interfaces
+		})
+		if t.Kind == KindInterface {
+			value["possibleTypes"] = s.introspectPossibleTypes(t)
+		}
+	case KindUnion:
+		value["possibleTypes"] = s.introspectPossibleTypes(t)
+	case KindEnum:
+		value["enumValues"] = Resolver(func(args map[string]interface{}) interface{} {
+			values := []interface{}{}
+			for _, enumValue := range t.EnumValues {
+				if enumValue.Deprecation != nil && args["includeDeprecated"] != true {
+					continue
+				}
+				values = append(values, map[string]interface{}{
+					"name":              enumValue.Name,
+					"description":       optional(enumValue.Description),
+					"isDeprecated":      enumValue.Deprecation != nil,
+					"deprecationReason": deprecationReason(enumValue.Deprecation),
+				})
+			}
+			return values
+		})
+	case KindInputObject:
+		value["inputFields"] = s.introspectInputValues(t.InputFields)
+		value["isOneOf"] = false
+	}
+
+	return value
+}
+
+func (s *Schema) introspectPossibleTypes(t *Type) Resolver {
+	return func(map[string]interface{}) interface{} {
+		types := []interface{}{}
+		for _, possible := range s.PossibleTypes(t) {
+			types = append(types, s.introspectType(possible.Name))
+		}
+		return types
+	}
+}
+
+func (s *Schema) introspectInputValues(defs []*InputValueDefinition) Resolver {
+	return func(args map[string]interface{}) interface{} {
+		values := []interface{}{}
+		for _, def := range defs {
+			if def.Deprecation != nil && args["includeDeprecated"] != true {
+				continue
+			}
+			var defaultValue interface{}
+			if def.Default != nil {
+				defaultValue = printValue(def.Default)
+			}
+			values = append(values, map[string]interface{}{
+				"name":              def.Name,
+				"description":       optional(def.Description),
+				"type":              s.introspectTypeRef(def.Type),
+				"defaultValue":      defaultValue,
+				"isDeprecated":      def.Deprecation != nil,
+				"deprecationReason": deprecationReason(def.Deprecation),
+			})
+		}
+		return values
+	}
+}
+
+// introspectTypeRef returns the __Type of a possibly wrapped type reference
+func (s *Schema) introspectTypeRef(ref *TypeRef) Resolver {
+	return func(map[string]interface{}) interface{} {
+		switch {
+		case ref.NonNull:
+			return wrapperType("NON_NULL", s.introspectTypeRef(ref.Nullable()))
+		case ref.Elem != nil:
+			return wrapperType("LIST", s.introspectTypeRef(ref.Elem))
+		}
+		return s.introspectType(ref.Name)
+	}
+}
+
+func wrapperType(kind string, ofType Resolver) map[string]interface{} {
+	return map[string]interface{}{
+		"kind":   kind,
+		"name":   nil,
+		"ofType": ofType,
+	}
+}
+
+func deprecationReason(reason *string) interface{} {
+	if reason == nil {
+		return nil
+	}
+	return *reason
+}
+
+// optional returns nil for empty descriptions
+func optional(description string) interface{} {
+	if description == "" {
+		return nil
+	}
+	return description
+}
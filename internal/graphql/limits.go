@@ -0,0 +1,49 @@
+package graphql
+
+// Limits on the work a single request may cause. Lists missing from the data are generated
+// at every level, so the response size grows exponentially with the depth of the query.
+const (
+	// MaxDepth is the deepest nesting of fields an operation may select
+	MaxDepth = 15
+	// MaxFields is the most fields that may be resolved while executing an operation
+	MaxFields = 10000
+)
+
+// depth returns how deeply an operation nests fields, following fragment spreads. The
+// document must be valid, so fragments exist and do not spread themselves.
+func (e *executor) depth(selections []Selection, memo map[string]int) int {
+	deepest := 0
+	for _, selection := range selections {
+		d := 0
+		switch sel := selection.(type) {
+		case *Field:
+			d = 1 + e.depth(sel.Selections, memo)
+		case *InlineFragment:
+			d = e.depth(sel.Selections, memo)
+		case *FragmentSpread:
+			var ok bool
+			if d, ok = memo[sel.Name]; !ok {
+				d = e.depth(e.fragments[sel.Name].Selections, memo)
+				memo[sel.Name] = d
+			}
+		}
+		if d > deepest {
+			deepest = d
+		}
+	}
+	return deepest
+}
+
+// countField counts a field about to be resolved, reporting false once the operation has
+// resolved more than MaxFields
+func (e *executor) countField() bool {
+	if e.exceeded {
+		return false
+	}
+	e.fields++
+	if e.fields > MaxFields {
+		e.exceeded = true
+		return false
+	}
+	return true
+}
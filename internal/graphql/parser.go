@@ -0,0 +1,464 @@
+package graphql
+
+import (
+	"fmt"
+)
+
+// maxNesting bounds how deeply selection sets, values and types may nest in a document,
+// so deeply nested input fails fast instead of exhausting the stack
+const maxNesting = 100
+
+// parser reads a document with one token of lookahead
+type parser struct {
+	lexer *lexer
+	token token
+	depth int
+}
+
+func newParser(src string) (*parser, error) {
+	p := &parser{lexer: newLexer(src)}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *parser) advance() error {
+	t, err := p.lexer.next()
+	if err != nil {
+		return err
+	}
+	p.token = t
+	return nil
+}
+
+func (p *parser) unexpected() error {
+	return &Error{
+		Message:   fmt.Sprintf("Syntax Error: Unexpected %s.", p.token),
+		Locations: []Location{p.token.loc},
+	}
+}
+
+// peek reports whether the current token is the punctuator or keyword
+func (p *parser) peek(kind tokenKind, value string) bool {
+	return p.token.kind == kind && p.token.value == value
+}
+
+// skip consumes the current token when it is the punctuator or keyword
+func (p *parser) skip(kind tokenKind, value string) (bool, error) {
+	if !p.peek(kind, value) {
+		return false, nil
+	}
+	return true, p.advance()
+}
+
+func (p *parser) expect(kind tokenKind, value string) error {
+	if !p.peek(kind, value) {
+		return &Error{
+			Message:   fmt.Sprintf("Syntax Error: Expected %q, found %s.", value, p.token),
+			Locations: []Location{p.token.loc},
+		}
+	}
+	return p.advance()
+}
+
+func (p *parser) name() (string, error) {
+	if p.token.kind != tokenName {
+		return "", &Error{
+			Message:   fmt.Sprintf("Syntax Error: Expected Name, found %s.", p.token),
+			Locations: []Location{p.token.loc},
+		}
+	}
+	name := p.token.value
+	return name, p.advance()
+}
+
+// nest enters a nested construct; the returned function leaves it
+func (p *parser) nest() (func(), error) {
+	if p.depth >= maxNesting {
+		return nil, &Error{
+			Message:   fmt.Sprintf("Syntax Error: Document nests deeper than %d levels.", maxNesting),
+			Locations: []Location{p.token.loc},
+		}
+	}
+	p.depth++
+	return func() { p.depth-- }, nil
+}
+
+// many parses items between open and close, at least one
+func (p *parser) many(open, close string, item func() error) error {
+	leave, err := p.nest()
+	if err != nil {
+		return err
+	}
+	defer leave()
+	if err := p.expect(tokenPunct, open); err != nil {
+		return err
+	}
+	for {
+		if err := item(); err != nil {
+			return err
+		}
+		if ok, err := p.skip(tokenPunct, close); ok || err != nil {
+			return err
+		}
+	}
+}
+
+// optionalMany parses items between open and close when open is the current token
+func (p *parser) optionalMany(open, close string, item func() error) error {
+	if !p.peek(tokenPunct, open) {
+		return nil
+	}
+	return p.many(open, close, item)
+}
+
+// Parse parses an executable document
+func Parse(src string) (*Document, error) {
+	p, err := newParser(src)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &Document{}
+	for {
+		switch {
+		case p.token.kind == tokenEOF:
+			if len(doc.Operations) == 0 && len(doc.Fragments) == 0 {
+				return nil, p.unexpected()
+			}
+			return doc, nil
+		case p.peek(tokenPunct, "{"):
+			op := &Operation{Type: Query, Loc: p.token.loc}
+			if op.Selections, err = p.selectionSet(); err != nil {
+				return nil, err
+			}
+			doc.Operations = append(doc.Operations, op)
+		case p.peek(tokenName, Query) || p.peek(tokenName, Mutation) || p.peek(tokenName, Subscription):
+			op, err := p.operation()
+			if err != nil {
+				return nil, err
+			}
+			doc.Operations = append(doc.Operations, op)
+		case p.peek(tokenName, "fragment"):
+			fragment, err := p.fragment()
+			if err != nil {
+				return nil, err
+			}
+			doc.Fragments = append(doc.Fragments, fragment)
+		default:
+			return nil, p.unexpected()
+		}
+	}
+}
+
+func (p *parser) operation() (*Operation, error) {
+	op := &Operation{Type: p.token.value, Loc: p.token.loc}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if p.token.kind == tokenName {
+		if op.Name, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+
+	err = p.optionalMany("(", ")", func() error {
+		variable := &VariableDefinition{Loc: p.token.loc}
+		if err := p.expect(tokenPunct, "$"); err != nil {
+			return err
+		}
+		var err error
+		if variable.Name, err = p.name(); err != nil {
+			return err
+		}
+		if err := p.expect(tokenPunct, ":"); err != nil {
+			return err
+		}
+		if variable.Type, err = p.typeRef(); err != nil {
+			return err
+		}
+		if ok, err := p.skip(tokenPunct, "="); err != nil {
+			return err
+		} else if ok {
+			if variable.Default, err = p.value(true); err != nil {
+				return err
+			}
+		}
+		if _, err := p.directives(true); err != nil {
+			return err
+		}
+		op.Variables = append(op.Variables, variable)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if op.Directives, err = p.directives(false); err != nil {
+		return nil, err
+	}
+	if op.Selections, err = p.selectionSet(); err != nil {
+		return nil, err
+	}
+	return op, nil
+}
+
+func (p *parser) fragment() (*Fragment, error) {
+	fragment := &Fragment{Loc: p.token.loc}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if p.peek(tokenName, "on") {
+		return nil, p.unexpected()
+	}
+	if fragment.Name, err = p.name(); err != nil {
+		return nil, err
+	}
+	if err := p.expect(tokenName, "on"); err != nil {
+		return nil, err
+	}
+	if fragment.TypeCondition, err = p.name(); err != nil {
+		return nil, err
+	}
+	if fragment.Directives, err = p.directives(false); err != nil {
+		return nil, err
+	}
+	if fragment.Selections, err = p.selectionSet(); err != nil {
+		return nil, err
+	}
+	return fragment, nil
+}
+
+func (p *parser) selectionSet() ([]Selection, error) {
+	var selections []Selection
+	err := p.many("{", "}", func() error {
+		selection, err := p.selection()
+		if err != nil {
+			return err
+		}
+		selections = append(selections, selection)
+		return nil
+	})
+	return selections, err
+}
+
+func (p *parser) selection() (Selection, error) {
+	loc := p.token.loc
+	if ok, err := p.skip(tokenPunct, "..."); err != nil {
+		return nil, err
+	} else if ok {
+		return p.fragmentSelection(loc)
+	}
+
+	field := &Field{Loc: loc}
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if ok, err := p.skip(tokenPunct, ":"); err != nil {
+		return nil, err
+	} else if ok {
+		field.Alias = name
+		if name, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+	field.Name = name
+
+	if field.Arguments, err = p.arguments(false); err != nil {
+		return nil, err
+	}
+	if field.Directives, err = p.directives(false); err != nil {
+		return nil, err
+	}
+	if p.peek(tokenPunct, "{") {
+		if field.Selections, err = p.selectionSet(); err != nil {
+			return nil, err
+		}
+	}
+	return field, nil
+}
+
+func (p *parser) fragmentSelection(loc Location) (Selection, error) {
+	var err error
+	if p.token.kind == tokenName && p.token.value != "on" {
+		spread := &FragmentSpread{Loc: loc}
+		if spread.Name, err = p.name(); err != nil {
+			return nil, err
+		}
+		if spread.Directives, err = p.directives(false); err != nil {
+			return nil, err
+		}
+		return spread, nil
+	}
+
+	fragment := &InlineFragment{Loc: loc}
+	if ok, err := p.skip(tokenName, "on"); err != nil {
+		return nil, err
+	} else if ok {
+		if fragment.TypeCondition, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+	if fragment.Directives, err = p.directives(false); err != nil {
+		return nil, err
+	}
+	if fragment.Selections, err = p.selectionSet(); err != nil {
+		return nil, err
+	}
+	return fragment, nil
+}
+
+func (p *parser) arguments(constant bool) ([]*Argument, error) {
+	var arguments []*Argument
+	err := p.optionalMany("(", ")", func() error {
+		argument := &Argument{Loc: p.token.loc}
+		var err error
+		if argument.Name, err = p.name(); err != nil {
+			return err
+		}
+		if err := p.expect(tokenPunct, ":"); err != nil {
+			return err
+		}
+		if argument.Value, err = p.value(constant); err != nil {
+			return err
+		}
+		arguments = append(arguments, argument)
+		return nil
+	})
+	return arguments, err
+}
+
+func (p *parser) directives(constant bool) ([]*Directive, error) {
+	var directives []*Directive
+	for p.peek(tokenPunct, "@") {
+		directive := &Directive{Loc: p.token.loc}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		var err error
+		if directive.Name, err = p.name(); err != nil {
+			return nil, err
+		}
+		if directive.Arguments, err = p.arguments(constant); err != nil {
+			return nil, err
+		}
+		directives = append(directives, directive)
+	}
+	return directives, nil
+}
+
+// value parses an input value; constant values may not reference variables
+func (p *parser) value(constant bool) (*Value, error) {
+	leave, err := p.nest()
+	if err != nil {
+		return nil, err
+	}
+	defer leave()
+	t := p.token
+	value := &Value{Loc: t.loc, Raw: t.value}
+
+	switch {
+	case t.kind == tokenPunct && t.value == "[":
+		value.Kind = ListValue
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		for {
+			if ok, err := p.skip(tokenPunct, "]"); err != nil {
+				return nil, err
+			} else if ok {
+				return value, nil
+			}
+			item, err := p.value(constant)
+			if err != nil {
+				return nil, err
+			}
+			value.List = append(value.List, item)
+		}
+	case t.kind == tokenPunct && t.value == "{":
+		value.Kind = ObjectValue
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		for {
+			if ok, err := p.skip(tokenPunct, "}"); err != nil {
+				return nil, err
+			} else if ok {
+				return value, nil
+			}
+			field := &ObjectField{Loc: p.token.loc}
+			var err error
+			if field.Name, err = p.name(); err != nil {
+				return nil, err
+			}
+			if err := p.expect(tokenPunct, ":"); err != nil {
+				return nil, err
+			}
+			if field.Value, err = p.value(constant); err != nil {
+				return nil, err
+			}
+			value.Fields = append(value.Fields, field)
+		}
+	case t.kind == tokenPunct && t.value == "$" && !constant:
+		value.Kind = VariableValue
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		var err error
+		value.Raw, err = p.name()
+		return value, err
+	case t.kind == tokenInt:
+		value.Kind = IntValue
+	case t.kind == tokenFloat:
+		value.Kind = FloatValue
+	case t.kind == tokenString || t.kind == tokenBlockString:
+		value.Kind = StringValue
+	case t.kind == tokenName && (t.value == "true" || t.value == "false"):
+		value.Kind = BooleanValue
+	case t.kind == tokenName && t.value == "null":
+		value.Kind = NullValue
+	case t.kind == tokenName:
+		value.Kind = EnumValue
+	default:
+		return nil, p.unexpected()
+	}
+
+	return value, p.advance()
+}
+
+func (p *parser) typeRef() (*TypeRef, error) {
+	leave, err := p.nest()
+	if err != nil {
+		return nil, err
+	}
+	defer leave()
+	var ref *TypeRef
+	if ok, err := p.skip(tokenPunct, "["); err != nil {
+		return nil, err
+	} else if ok {
+		elem, err := p.typeRef()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenPunct, "]"); err != nil {
+			return nil, err
+		}
+		ref = &TypeRef{Elem: elem}
+	} else {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		ref = &TypeRef{Name: name}
+	}
+
+	ok, err := p.skip(tokenPunct, "!")
+	ref.NonNull = ok
+	return ref, err
+}
@@ -0,0 +1,565 @@
+package graphql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Type kinds, named as in introspection
+const (
+	KindScalar      = "SCALAR"
+	KindObject      = "OBJECT"
+	KindInterface   = "INTERFACE"
+	KindUnion       = "UNION"
+	KindEnum        = "ENUM"
+	KindInputObject = "INPUT_OBJECT"
+)
+
+// Schema is a type system parsed from SDL, including the built-in scalars,
+// directives and introspection types
+type Schema struct {
+	Description  string
+	Types        map[string]*Type
+	Directives   map[string]*DirectiveDefinition
+	Query        string
+	Mutation     string
+	Subscription string
+
+	// typeNames and directiveNames keep definition order for introspection
+	typeNames      []string
+	directiveNames []string
+}
+
+type Type struct {
+	Kind        string
+	Name        string
+	Description string
+	Fields      []*FieldDefinition      // Objects and interfaces
+	Interfaces  []string                // Objects and interfaces
+	Members     []string                // Unions
+	EnumValues  []*EnumValueDefinition  // Enums
+	InputFields []*InputValueDefinition // Input objects
+	Loc         Location
+}
+
+type FieldDefinition struct {
+	Name        string
+	Description string
+	Arguments   []*InputValueDefinition
+	Type        *TypeRef
+	Deprecation *string
+	Loc         Location
+}
+
+type InputValueDefinition struct {
+	Name        string
+	Description string
+	Type        *TypeRef
+	Default     *Value
+	Deprecation *string
+	Loc         Location
+}
+
+type EnumValueDefinition struct {
+	Name        string
+	Description string
+	Deprecation *string
+}
+
+type DirectiveDefinition struct {
+	Name        string
+	Description string
+	Arguments   []*InputValueDefinition
+	Locations   []string
+	Repeatable  bool
+}
+
+// Field returns the field of an object or interface type
+func (t *Type) Field(name string) *FieldDefinition {
+	for _, field := range t.Fields {
+		if field.Name == name {
+			return field
+		}
+	}
+	return nil
+}
+
+// InputField returns the field of an input object type
+func (t *Type) InputField(name string) *InputValueDefinition {
+	return findInputValue(t.InputFields, name)
+}
+
+// HasEnumValue reports whether an enum type defines the value
+func (t *Type) HasEnumValue(name string) bool {
+	for _, value := range t.EnumValues {
+		if value.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// IsComposite reports whether selections can be made on the type
+func (t *Type) IsComposite() bool {
+	return t.Kind == KindObject || t.Kind == KindInterface || t.Kind == KindUnion
+}
+
+// IsAbstract reports whether the type is resolved to one of several object types
+func (t *Type) IsAbstract() bool {
+	return t.Kind == KindInterface || t.Kind == KindUnion
+}
+
+// IsLeaf reports whether values of the type are serialized directly
+func (t *Type) IsLeaf() bool {
+	return t.Kind == KindScalar || t.Kind == KindEnum
+}
+
+// IsInput reports whether the type can be used for arguments and variables
+func (t *Type) IsInput() bool {
+	return t.Kind == KindScalar || t.Kind == KindEnum || t.Kind == KindInputObject
+}
+
+func findInputValue(values []*InputValueDefinition, name string) *InputValueDefinition {
+	for _, value := range values {
+		if value.Name == name {
+			return value
+		}
+	}
+	return nil
+}
+
+// PossibleTypes returns the object types a value of the type can have
+func (s *Schema) PossibleTypes(t *Type) []*Type {
+	switch t.Kind {
+	case KindObject:
+		return []*Type{t}
+	case KindUnion:
+		types := make([]*Type, 0, len(t.Members))
+		for _, name := range t.Members {
+			types = append(types, s.Types[name])
+		}
+		return types
+	case KindInterface:
+		var types []*Type
+		for _, name := range s.typeNames {
+			candidate := s.Types[name]
+			if candidate.Kind != KindObject {
+				continue
+			}
+			for _, iface := range candidate.Interfaces {
+				if iface == t.Name {
+					types = append(types, candidate)
+					break
+				}
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+// isPossibleType reports whether an object type belongs to an abstract or object type
+func (s *Schema) isPossibleType(abstract, object *Type) bool {
+	for _, t := range s.PossibleTypes(abstract) {
+		if t == object {
+			return true
+		}
+	}
+	return false
+}
+
+// overlap reports whether two composite types share a possible object type
+func (s *Schema) overlap(a, b *Type) bool {
+	for _, t := range s.PossibleTypes(a) {
+		if s.isPossibleType(b, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// Meta fields available on every composite type, and on the query root
+var (
+	typenameField = &FieldDefinition{Name: "__typename", Type: &TypeRef{Name: "String", NonNull: true}}
+	schemaField   = &FieldDefinition{Name: "__schema", Type: &TypeRef{Name: "__Schema", NonNull: true}}
+	typeField     = &FieldDefinition{
+		Name:      "__type",
+		Type:      &TypeRef{Name: "__Type"},
+		Arguments: []*InputValueDefinition{{Name: "name", Type: &TypeRef{Name: "String", NonNull: true}}},
+	}
+)
+
+// fieldDefinition returns the definition of a selected field, including meta fields
+func (s *Schema) fieldDefinition(parent *Type, name string) *FieldDefinition {
+	switch {
+	case name == typenameField.Name:
+		return typenameField
+	case name == schemaField.Name && parent.Name == s.Query:
+		return schemaField
+	case name == typeField.Name && parent.Name == s.Query:
+		return typeField
+	case parent.Kind == KindObject || parent.Kind == KindInterface:
+		return parent.Field(name)
+	}
+	return nil
+}
+
+// rootType returns the root type of an operation type, nil when the schema has none
+func (s *Schema) rootType(operation string) *Type {
+	switch operation {
+	case Query:
+		return s.Types[s.Query]
+	case Mutation:
+		return s.Types[s.Mutation]
+	case Subscription:
+		return s.Types[s.Subscription]
+	}
+	return nil
+}
+
+// builtins are part of every schema
+const builtins = `
+"The ` + "`Int`" + ` scalar type represents non-fractional signed whole numeric values between -(2^31) and 2^31 - 1."
+scalar Int
+"The ` + "`Float`" + ` scalar type represents signed double-precision fractional values."
+scalar Float
+"The ` + "`String`" + ` scalar type represents textual data as UTF-8 character sequences."
+scalar String
+"The ` + "`Boolean`" + ` scalar type represents ` + "`true` or `false`" + `."
+scalar Boolean
+"The ` + "`ID`" + ` scalar type represents a unique identifier, serialized as a String."
+scalar ID
+
+"Directs the executor to include this field or fragment only when the ` + "`if`" + ` argument is true."
+directive @include(if: Boolean!) on FIELD | FRAGMENT_SPREAD | INLINE_FRAGMENT
+"Directs the executor to skip this field or fragment when the ` + "`if`" + ` argument is true."
+directive @skip(if: Boolean!) on FIELD | FRAGMENT_SPREAD | INLINE_FRAGMENT
+"Marks an element of a GraphQL schema as no longer supported."
+directive @deprecated(reason: String = "No longer supported") on FIELD_DEFINITION | ARGUMENT_DEFINITION | INPUT_FIELD_DEFINITION | ENUM_VALUE
+"Exposes a URL that specifies the behavior of this scalar."
+directive @specifiedBy(url: String!) on SCALAR
+
+type __Schema {
+  description: String
+  types: [__Type!]!
+  queryType: __Type!
+  mutationType: __Type
+  subscriptionType: __Type
+  directives: [__Directive!]!
+}
+
+type __Type {
+  kind: __TypeKind!
+  name: String
+  description: String
+  specifiedByURL: String
+  fields(includeDeprecated: Boolean = false): [__Field!]
+  interfaces: [__Type!]
+  possibleTypes: [__Type!]
+  enumValues(includeDeprecated: Boolean = false): [__EnumValue!]
+  inputFields(includeDeprecated: Boolean = false): [__InputValue!]
+  ofType: __Type
+  isOneOf: Boolean
+}
+
+type __Field {
+  name: String!
+  description: String
+  args(includeDeprecated: Boolean = false): [__InputValue!]!
+  type: __Type!
+  isDeprecated: Boolean!
+  deprecationReason: String
+}
+
+type __InputValue {
+  name: String!
+  description: String
+  type: __Type!
+  defaultValue: String
+  isDeprecated: Boolean!
+  deprecationReason: String
+}
+
+type __EnumValue {
+  name: String!
+  description: String
+  isDeprecated: Boolean!
+  deprecationReason: String
+}
+
+type __Directive {
+  name: String!
+  description: String
+  isRepeatable: Boolean!
+  locations: [__DirectiveLocation!]!
+  args(includeDeprecated: Boolean = false): [__InputValue!]!
+}
+
+enum __TypeKind { SCALAR OBJECT INTERFACE UNION ENUM INPUT_OBJECT LIST NON_NULL }
+
+enum __DirectiveLocation {
+  QUERY MUTATION SUBSCRIPTION FIELD FRAGMENT_DEFINITION FRAGMENT_SPREAD INLINE_FRAGMENT VARIABLE_DEFINITION
+  SCHEMA SCALAR OBJECT FIELD_DEFINITION ARGUMENT_DEFINITION INTERFACE UNION ENUM ENUM_VALUE INPUT_OBJECT INPUT_FIELD_DEFINITION
+}
+`
+
+// ParseSchema parses SDL into a schema and checks that it is consistent
+func ParseSchema(sdl string) (*Schema, error) {
+	s := &Schema{
+		Types:      map[string]*Type{},
+		Directives: map[string]*DirectiveDefinition{},
+	}
+
+	b := &schemaBuilder{schema: s, builtin: true}
+	if err := b.parse(builtins); err != nil {
+		return nil, fmt.Errorf("invalid built-in definitions: %w", err)
+	}
+
+	b.builtin = false
+	if err := b.parse(sdl); err != nil {
+		return nil, err
+	}
+	b.resolveRoots()
+	if errs := b.validate(); len(errs) > 0 {
+		return nil, errs
+	}
+	return s, nil
+}
+
+type schemaBuilder struct {
+	schema  *Schema
+	builtin bool
+
+	// schemaDefined is set by a schema definition, which takes over root type naming
+	schemaDefined bool
+	// extensions are applied once every type is defined
+	extensions []func() *Error
+	errs       SchemaError
+}
+
+func (b *schemaBuilder) errorf(loc Location, format string, args ...interface{}) {
+	b.errs = append(b.errs, newError("", &loc, format, args...))
+}
+
+func (b *schemaBuilder) parse(src string) error {
+	p, err := newParser(src)
+	if err != nil {
+		return err
+	}
+	if err := b.definitions(p); err != nil {
+		return err
+	}
+	for _, extend := range b.extensions {
+		if err := extend(); err != nil {
+			b.errs = append(b.errs, err)
+		}
+	}
+	b.extensions = nil
+	if len(b.errs) > 0 {
+		return b.errs
+	}
+	return nil
+}
+
+func (b *schemaBuilder) define(t *Type) {
+	s := b.schema
+	if _, ok := s.Types[t.Name]; ok {
+		b.errorf(t.Loc, "There can be only one type named %q.", t.Name)
+		return
+	}
+	if !b.builtin && strings.HasPrefix(t.Name, "__") {
+		b.errorf(t.Loc, "Name %q must not begin with \"__\", which is reserved by GraphQL introspection.", t.Name)
+		return
+	}
+	s.Types[t.Name] = t
+	s.typeNames = append(s.typeNames, t.Name)
+}
+
+// extend merges an extension into its type once every type is defined
+func (b *schemaBuilder) extend(extension *Type) {
+	b.extensions = append(b.extensions, func() *Error {
+		t, ok := b.schema.Types[extension.Name]
+		if !ok || t.Kind != extension.Kind {
+			return newError("", &extension.Loc, "Cannot extend type %q because it is not defined as %s.", extension.Name, strings.ToLower(extension.Kind))
+		}
+		t.Fields = append(t.Fields, extension.Fields...)
+		t.Interfaces = append(t.Interfaces, extension.Interfaces...)
+		t.Members = append(t.Members, extension.Members...)
+		t.EnumValues = append(t.EnumValues, extension.EnumValues...)
+		t.InputFields = append(t.InputFields, extension.InputFields...)
+		return nil
+	})
+}
+
+// resolveRoots names the root types after the conventional type names unless a schema definition did
+func (b *schemaBuilder) resolveRoots() {
+	s := b.schema
+	if b.schemaDefined {
+		return
+	}
+	for operation, root := range map[string]*string{"Query": &s.Query, "Mutation": &s.Mutation, "Subscription": &s.Subscription} {
+		if _, ok := s.Types[operation]; ok {
+			*root = operation
+		}
+	}
+}
+
+func (b *schemaBuilder) validate() SchemaError {
+	s := b.schema
+
+	if s.Query == "" {
+		b.errs = append(b.errs, newError("", nil, "Query root type must be provided."))
+	}
+	for operation, name := range map[string]string{"Query": s.Query, "Mutation": s.Mutation, "Subscription": s.Subscription} {
+		if name == "" {
+			continue
+		}
+		if t, ok := s.Types[name]; !ok || t.Kind != KindObject {
+			b.errs = append(b.errs, newError("", nil, "%s root type %q must be a defined object type.", operation, name))
+		}
+	}
+
+	for _, name := range s.typeNames {
+		t := s.Types[name]
+		switch t.Kind {
+		case KindObject, KindInterface:
+			b.validateFields(t)
+			b.validateInterfaces(t)
+		case KindUnion:
+			if len(t.Members) == 0 {
+				b.errorf(t.Loc, "Union type %s must define one or more member types.", t.Name)
+			}
+			seen := map[string]bool{}
+			for _, member := range t.Members {
+				if m, ok := s.Types[member]; !ok || m.Kind != KindObject {
+					b.errorf(t.Loc, "Union type %s can only include object types, it cannot include %s.", t.Name, member)
+				} else if seen[member] {
+					b.errorf(t.Loc, "Union type %s can only include type %s once.", t.Name, member)
+				}
+				seen[member] = true
+			}
+		case KindEnum:
+			if len(t.EnumValues) == 0 {
+				b.errorf(t.Loc, "Enum type %s must define one or more values.", t.Name)
+			}
+			seen := map[string]bool{}
+			for _, value := range t.EnumValues {
+				if value.Name == "true" || value.Name == "false" || value.Name == "null" {
+					b.errorf(t.Loc, "Enum type %s cannot include value: %s.", t.Name, value.Name)
+				} else if seen[value.Name] {
+					b.errorf(t.Loc, "Enum value %s.%s can only be defined once.", t.Name, value.Name)
+				}
+				seen[value.Name] = true
+			}
+		case KindInputObject:
+			if len(t.InputFields) == 0 {
+				b.errorf(t.Loc, "Input Object type %s must define one or more fields.", t.Name)
+			}
+			b.validateInputValues(t.Name, t.InputFields)
+		}
+	}
+
+	for _, name := range s.directiveNames {
+		directive := s.Directives[name]
+		b.validateInputValues("@"+directive.Name, directive.Arguments)
+	}
+
+	return b.errs
+}
+
+func (b *schemaBuilder) validateFields(t *Type) {
+	s := b.schema
+	if len(t.Fields) == 0 {
+		b.errorf(t.Loc, "Type %s must define one or more fields.", t.Name)
+	}
+
+	seen := map[string]bool{}
+	for _, field := range t.Fields {
+		if seen[field.Name] {
+			b.errorf(field.Loc, "Field %s.%s can only be defined once.", t.Name, field.Name)
+		}
+		seen[field.Name] = true
+
+		if !strings.HasPrefix(t.Name, "__") && strings.HasPrefix(field.Name, "__") {
+			b.errorf(field.Loc, "Name %q must not begin with \"__\", which is reserved by GraphQL introspection.", field.Name)
+		}
+
+		if named, ok := s.Types[field.Type.NamedType()]; !ok {
+			b.errorf(field.Loc, "Unknown type %q.", field.Type.NamedType())
+		} else if named.Kind == KindInputObject {
+			b.errorf(field.Loc, "The type of %s.%s must be Output Type but got: %s.", t.Name, field.Name, field.Type)
+		}
+		b.validateInputValues(t.Name+"."+field.Name, field.Arguments)
+	}
+}
+
+func (b *schemaBuilder) validateInterfaces(t *Type) {
+	s := b.schema
+	for _, name := range t.Interfaces {
+		iface, ok := s.Types[name]
+		if !ok || iface.Kind != KindInterface {
+			b.errorf(t.Loc, "Type %s must only implement Interface types, it cannot implement %s.", t.Name, name)
+			continue
+		}
+		for _, field := range iface.Fields {
+			implementation := t.Field(field.Name)
+			if implementation == nil {
+				b.errorf(t.Loc, "Interface field %s.%s expected but %s does not provide it.", name, field.Name, t.Name)
+				continue
+			}
+			if implementation.Type.NamedType() != field.Type.NamedType() && !b.implements(implementation.Type.NamedType(), field.Type.NamedType()) {
+				b.errorf(implementation.Loc, "Interface field %s.%s expects type %s but %s.%s is type %s.",
+					name, field.Name, field.Type, t.Name, field.Name, implementation.Type)
+			}
+			for _, argument := range field.Arguments {
+				if findInputValue(implementation.Arguments, argument.Name) == nil {
+					b.errorf(implementation.Loc, "Interface field argument %s.%s(%s:) expected but %s.%s does not provide it.",
+						name, field.Name, argument.Name, t.Name, field.Name)
+				}
+			}
+		}
+	}
+}
+
+// implements reports whether a type name is a valid covariant of an interface field type
+func (b *schemaBuilder) implements(name, expected string) bool {
+	t, ok := b.schema.Types[name]
+	expectedType, ok2 := b.schema.Types[expected]
+	if !ok || !ok2 || !expectedType.IsAbstract() {
+		return false
+	}
+	if t.Kind == KindObject {
+		return b.schema.isPossibleType(expectedType, t)
+	}
+	for _, iface := range t.Interfaces {
+		if iface == expected {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *schemaBuilder) validateInputValues(owner string, values []*InputValueDefinition) {
+	seen := map[string]bool{}
+	for _, value := range values {
+		if seen[value.Name] {
+			b.errorf(value.Loc, "Argument %s(%s:) can only be defined once.", owner, value.Name)
+		}
+		seen[value.Name] = true
+
+		named, ok := b.schema.Types[value.Type.NamedType()]
+		if !ok {
+			b.errorf(value.Loc, "Unknown type %q.", value.Type.NamedType())
+			continue
+		}
+		if !named.IsInput() {
+			b.errorf(value.Loc, "The type of %s(%s:) must be Input Type but got: %s.", owner, value.Name, value.Type)
+			continue
+		}
+		if value.Default != nil {
+			if err := checkLiteral(b.schema, value.Default, value.Type, nil); err != "" {
+				b.errorf(value.Default.Loc, "Invalid default value for %s(%s:): %s", owner, value.Name, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,353 @@
+package graphql
+
+import "strconv"
+
+// definitions parses the type system definitions of an SDL document
+func (b *schemaBuilder) definitions(p *parser) error {
+	for p.token.kind != tokenEOF {
+		description, err := p.description()
+		if err != nil {
+			return err
+		}
+
+		extend := false
+		if description == "" && p.peek(tokenName, "extend") {
+			extend = true
+			if err := p.advance(); err != nil {
+				return err
+			}
+		}
+
+		if p.token.kind != tokenName {
+			return p.unexpected()
+		}
+
+		var t *Type
+		switch keyword, loc := p.token.value, p.token.loc; keyword {
+		case "schema":
+			if err := b.schemaDefinition(p, extend); err != nil {
+				return err
+			}
+			continue
+		case "directive":
+			if extend {
+				return p.unexpected()
+			}
+			if err := b.directiveDefinition(p, description); err != nil {
+				return err
+			}
+			continue
+		case "scalar":
+			t = &Type{Kind: KindScalar, Loc: loc}
+			err = b.typeHeader(p, t)
+		case "type", "interface":
+			t = &Type{Kind: KindObject, Loc: loc}
+			if keyword == "interface" {
+				t.Kind = KindInterface
+			}
+			err = b.objectDefinition(p, t)
+		case "union":
+			t = &Type{Kind: KindUnion, Loc: loc}
+			err = b.unionDefinition(p, t)
+		case "enum":
+			t = &Type{Kind: KindEnum, Loc: loc}
+			err = b.enumDefinition(p, t)
+		case "input":
+			t = &Type{Kind: KindInputObject, Loc: loc}
+			if err = b.typeHeader(p, t); err == nil {
+				t.InputFields, err = b.inputValues(p, "{", "}")
+			}
+		default:
+			return p.unexpected()
+		}
+		if err != nil {
+			return err
+		}
+
+		t.Description = description
+		if extend {
+			b.extend(t)
+		} else {
+			b.define(t)
+		}
+	}
+	return nil
+}
+
+// description parses an optional description string
+func (p *parser) description() (string, error) {
+	if p.token.kind != tokenString && p.token.kind != tokenBlockString {
+		return "", nil
+	}
+	description := p.token.value
+	return description, p.advance()
+}
+
+// typeHeader parses the keyword, name and directives of a type definition
+func (b *schemaBuilder) typeHeader(p *parser, t *Type) error {
+	if err := p.advance(); err != nil {
+		return err
+	}
+	var err error
+	if t.Name, err = p.name(); err != nil {
+		return err
+	}
+	_, err = p.directives(true)
+	return err
+}
+
+func (b *schemaBuilder) schemaDefinition(p *parser, extend bool) error {
+	if err := p.advance(); err != nil {
+		return err
+	}
+	if _, err := p.directives(true); err != nil {
+		return err
+	}
+	if !extend && b.schemaDefined {
+		return &Error{Message: "Must provide only one schema definition.", Locations: []Location{p.token.loc}}
+	}
+	b.schemaDefined = true
+
+	s := b.schema
+	return p.many("{", "}", func() error {
+		operation, err := p.name()
+		if err != nil {
+			return err
+		}
+		if err := p.expect(tokenPunct, ":"); err != nil {
+			return err
+		}
+		name, err := p.name()
+		if err != nil {
+			return err
+		}
+
+		switch operation {
+		case Query:
+			s.Query = name
+		case Mutation:
+			s.Mutation = name
+		case Subscription:
+			s.Subscription = name
+		default:
+			return &Error{Message: "Syntax Error: Unknown operation type " + strconv.Quote(operation) + ".", Locations: []Location{p.token.loc}}
+		}
+		return nil
+	})
+}
+
+func (b *schemaBuilder) directiveDefinition(p *parser, description string) error {
+	loc := p.token.loc
+	if err := p.advance(); err != nil {
+		return err
+	}
+	if err := p.expect(tokenPunct, "@"); err != nil {
+		return err
+	}
+
+	directive := &DirectiveDefinition{Description: description}
+	var err error
+	if directive.Name, err = p.name(); err != nil {
+		return err
+	}
+	if p.peek(tokenPunct, "(") {
+		if directive.Arguments, err = b.inputValues(p, "(", ")"); err != nil {
+			return err
+		}
+	}
+	if directive.Repeatable, err = p.skip(tokenName, "repeatable"); err != nil {
+		return err
+	}
+	if err := p.expect(tokenName, "on"); err != nil {
+		return err
+	}
+	if _, err := p.skip(tokenPunct, "|"); err != nil {
+		return err
+	}
+	for {
+		location, err := p.name()
+		if err != nil {
+			return err
+		}
+		directive.Locations = append(directive.Locations, location)
+		if ok, err := p.skip(tokenPunct, "|"); err != nil {
+			return err
+		} else if !ok {
+			break
+		}
+	}
+
+	s := b.schema
+	if _, ok := s.Directives[directive.Name]; ok {
+		b.errorf(loc, "Directive \"@%s\" already exists in the schema.", directive.Name)
+		return nil
+	}
+	s.Directives[directive.Name] = directive
+	s.directiveNames = append(s.directiveNames, directive.Name)
+	return nil
+}
+
+func (b *schemaBuilder) objectDefinition(p *parser, t *Type) error {
+	if err := p.advance(); err != nil {
+		return err
+	}
+	var err error
+	if t.Name, err = p.name(); err != nil {
+		return err
+	}
+
+	if ok, err := p.skip(tokenName, "implements"); err != nil {
+		return err
+	} else if ok {
+		if _, err := p.skip(tokenPunct, "&"); err != nil {
+			return err
+		}
+		for {
+			name, err := p.name()
+			if err != nil {
+				return err
+			}
+			t.Interfaces = append(t.Interfaces, name)
+			if ok, err := p.skip(tokenPunct, "&"); err != nil {
+				return err
+			} else if !ok {
+				break
+			}
+		}
+	}
+
+	if _, err := p.directives(true); err != nil {
+		return err
+	}
+
+	return p.optionalMany("{", "}", func() error {
+		field := &FieldDefinition{}
+		var err error
+		if field.Description, err = p.description(); err != nil {
+			return err
+		}
+		field.Loc = p.token.loc
+		if field.Name, err = p.name(); err != nil {
+			return err
+		}
+		if p.peek(tokenPunct, "(") {
+			if field.Arguments, err = b.inputValues(p, "(", ")"); err != nil {
+				return err
+			}
+		}
+		if err := p.expect(tokenPunct, ":"); err != nil {
+			return err
+		}
+		if field.Type, err = p.typeRef(); err != nil {
+			return err
+		}
+		directives, err := p.directives(true)
+		if err != nil {
+			return err
+		}
+		field.Deprecation = deprecation(directives)
+		t.Fields = append(t.Fields, field)
+		return nil
+	})
+}
+
+func (b *schemaBuilder) unionDefinition(p *parser, t *Type) error {
+	if err := b.typeHeader(p, t); err != nil {
+		return err
+	}
+	if ok, err := p.skip(tokenPunct, "="); err != nil || !ok {
+		return err
+	}
+	if _, err := p.skip(tokenPunct, "|"); err != nil {
+		return err
+	}
+	for {
+		name, err := p.name()
+		if err != nil {
+			return err
+		}
+		t.Members = append(t.Members, name)
+		if ok, err := p.skip(tokenPunct, "|"); err != nil {
+			return err
+		} else if !ok {
+			return nil
+		}
+	}
+}
+
+func (b *schemaBuilder) enumDefinition(p *parser, t *Type) error {
+	if err := b.typeHeader(p, t); err != nil {
+		return err
+	}
+	return p.optionalMany("{", "}", func() error {
+		value := &EnumValueDefinition{}
+		var err error
+		if value.Description, err = p.description(); err != nil {
+			return err
+		}
+		if value.Name, err = p.name(); err != nil {
+			return err
+		}
+		directives, err := p.directives(true)
+		if err != nil {
+			return err
+		}
+		value.Deprecation = deprecation(directives)
+		t.EnumValues = append(t.EnumValues, value)
+		return nil
+	})
+}
+
+// inputValues parses argument or input field definitions
+func (b *schemaBuilder) inputValues(p *parser, open, close string) ([]*InputValueDefinition, error) {
+	var values []*InputValueDefinition
+	err := p.optionalMany(open, close, func() error {
+		value := &InputValueDefinition{}
+		var err error
+		if value.Description, err = p.description(); err != nil {
+			return err
+		}
+		value.Loc = p.token.loc
+		if value.Name, err = p.name(); err != nil {
+			return err
+		}
+		if err := p.expect(tokenPunct, ":"); err != nil {
+			return err
+		}
+		if value.Type, err = p.typeRef(); err != nil {
+			return err
+		}
+		if ok, err := p.skip(tokenPunct, "="); err != nil {
+			return err
+		} else if ok {
+			if value.Default, err = p.value(true); err != nil {
+				return err
+			}
+		}
+		directives, err := p.directives(true)
+		if err != nil {
+			return err
+		}
+		value.Deprecation = deprecation(directives)
+		values = append(values, value)
+		return nil
+	})
+	return values, err
+}
+
+// deprecation returns the reason of a @deprecated directive, nil when there is none
+func deprecation(directives []*Directive) *string {
+	for _, directive := range directives {
+		if directive.Name != "deprecated" {
+			continue
+		}
+		reason := "No longer supported"
+		for _, argument := range directive.Arguments {
+			if argument.Name == "reason" && argument.Value.Kind == StringValue {
+				reason = argument.Value.Raw
+			}
+		}
+		return &reason
+	}
+	return nil
+}
@@ -0,0 +1,472 @@
+package graphql
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// variableUsage is a variable referenced where a value of type expected is required
+type variableUsage struct {
+	name       string
+	expected   *TypeRef
+	hasDefault bool // The position has a default value, so a nullable variable may fill a non-null one
+	loc        Location
+}
+
+// scope collects what an operation or fragment definition references
+type scope struct {
+	usages  []variableUsage
+	spreads []string
+}
+
+type validator struct {
+	schema    *Schema
+	doc       *Document
+	fragments map[string]*Fragment
+	scopes    map[string]*scope // Fragment scopes by name
+	used      map[string]bool   // Fragments spread anywhere
+	errs      []*Error
+}
+
+// Validate checks an executable document against the schema and returns every error found
+func (s *Schema) Validate(doc *Document) []*Error {
+	v := &validator{
+		schema:    s,
+		doc:       doc,
+		fragments: map[string]*Fragment{},
+		scopes:    map[string]*scope{},
+		used:      map[string]bool{},
+	}
+
+	for _, fragment := range doc.Fragments {
+		if _, ok := v.fragments[fragment.Name]; ok {
+			v.errorf(fragment.Loc, "There can be only one fragment named %q.", fragment.Name)
+			continue
+		}
+		v.fragments[fragment.Name] = fragment
+	}
+
+	for _, fragment := range doc.Fragments {
+		if v.scopes[fragment.Name] != nil {
+			continue
+		}
+		sc := &scope{}
+		v.scopes[fragment.Name] = sc
+		v.directives(fragment.Directives, "FRAGMENT_DEFINITION", sc)
+
+		t, ok := s.Types[fragment.TypeCondition]
+		if !ok {
+			v.errorf(fragment.Loc, "Unknown type %q.", fragment.TypeCondition)
+			continue
+		}
+		if !t.IsComposite() {
+			v.errorf(fragment.Loc, "Fragment %q cannot condition on non composite type %q.", fragment.Name, t.Name)
+			continue
+		}
+		v.selectionSet(t, fragment.Selections, sc)
+	}
+	v.fragmentCycles()
+
+	v.operations()
+
+	for _, fragment := range doc.Fragments {
+		if !v.used[fragment.Name] {
+			v.errorf(fragment.Loc, "Fragment %q is never used.", fragment.Name)
+		}
+	}
+
+	return v.errs
+}
+
+func (v *validator) errorf(loc Location, format string, args ...interface{}) {
+	v.errs = append(v.errs, newError(CodeValidationFailed, &loc, format, args...))
+}
+
+func (v *validator) operations() {
+	names := map[string]bool{}
+	for _, op := range v.doc.Operations {
+		if op.Name == "" && len(v.doc.Operations) > 1 {
+			v.errorf(op.Loc, "This anonymous operation must be the only defined operation.")
+		}
+		if op.Name != "" {
+			if names[op.Name] {
+				v.errorf(op.Loc, "There can be only one operation named %q.", op.Name)
+			}
+			names[op.Name] = true
+		}
+
+		sc := &scope{}
+		v.directives(op.Directives, strings.ToUpper(op.Type), sc)
+
+		defined := map[string]*VariableDefinition{}
+		for _, variable := range op.Variables {
+			if defined[variable.Name] != nil {
+				v.errorf(variable.Loc, "There can be only one variable named \"$%s\".", variable.Name)
+				continue
+			}
+			defined[variable.Name] = variable
+
+			t, ok := v.schema.Types[variable.Type.NamedType()]
+			if !ok {
+				v.errorf(variable.Loc, "Unknown type %q.", variable.Type.NamedType())
+				continue
+			}
+			if !t.IsInput() {
+				v.errorf(variable.Loc, "Variable \"$%s\" cannot be non-input type %q.", variable.Name, variable.Type)
+				continue
+			}
+			if variable.Default != nil {
+				if msg := checkLiteral(v.schema, variable.Default, variable.Type, nil); msg != "" {
+					v.errorf(variable.Default.Loc, "%s", msg)
+				}
+			}
+		}
+
+		root := v.schema.rootType(op.Type)
+		if root == nil {
+			v.errorf(op.Loc, "Schema is not configured for %ss.", op.Type)
+			continue
+		}
+		v.selectionSet(root, op.Selections, sc)
+		v.variables(op, defined, sc)
+	}
+}
+
+// variables checks the variables an operation uses, directly or through its fragments
+func (v *validator) variables(op *Operation, defined map[string]*VariableDefinition, sc *scope) {
+	usages := append([]variableUsage(nil), sc.usages...)
+	visited := map[string]bool{}
+	pending := append([]string(nil), sc.spreads...)
+	for len(pending) > 0 {
+		name := pending[0]
+		pending = pending[1:]
+		if visited[name] || v.scopes[name] == nil {
+			continue
+		}
+		visited[name] = true
+		usages = append(usages, v.scopes[name].usages...)
+		pending = append(pending, v.scopes[name].spreads...)
+	}
+
+	operation := "operation"
+	if op.Name != "" {
+		operation = fmt.Sprintf("operation %q", op.Name)
+	}
+
+	used := map[string]bool{}
+	for _, usage := range usages {
+		used[usage.name] = true
+		variable := defined[usage.name]
+		if variable == nil {
+			v.errorf(usage.loc, "Variable \"$%s\" is not defined by %s.", usage.name, operation)
+			continue
+		}
+
+		varType := variable.Type
+		if usage.expected.NonNull && !varType.NonNull && (variable.Default != nil && variable.Default.Kind != NullValue || usage.hasDefault) {
+			varType = &TypeRef{Name: varType.Name, Elem: varType.Elem, NonNull: true}
+		}
+		if !typeFits(varType, usage.expected) {
+			v.errorf(usage.loc, "Variable \"$%s\" of type %q used in position expecting type %q.", usage.name, variable.Type, usage.expected)
+		}
+	}
+
+	for _, variable := range op.Variables {
+		if !used[variable.Name] {
+			v.errorf(variable.Loc, "Variable \"$%s\" is never used in %s.", variable.Name, operation)
+		}
+	}
+}
+
+// typeFits reports whether a variable type may be used where the expected type is required
+func typeFits(varType, expected *TypeRef) bool {
+	if expected.NonNull && !varType.NonNull {
+		return false
+	}
+	if (varType.Elem == nil) != (expected.Elem == nil) {
+		return false
+	}
+	if varType.Elem != nil {
+		return typeFits(varType.Elem, expected.Elem)
+	}
+	return varType.Name == expected.Name
+}
+
+// fragmentCycles reports fragments that spread themselves, directly or indirectly
+func (v *validator) fragmentCycles() {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := map[string]int{}
+
+	// chain holds the fragments being visited, outermost first
+	var visit func(chain []string)
+	visit = func(chain []string) {
+		name := chain[len(chain)-1]
+		state[name] = visiting
+		for _, spread := range v.scopes[name].spreads {
+			switch {
+			case v.fragments[spread] == nil:
+			case state[spread] == visiting:
+				via := ""
+				for i, step := range chain {
+					if step == spread && i+1 < len(chain) {
+						via = " via " + strings.Join(chain[i+1:], ", ")
+					}
+				}
+				v.errorf(v.fragments[spread].Loc, "Cannot spread fragment %q within itself%s.", spread, via)
+			case state[spread] == unvisited:
+				visit(append(chain[:len(chain):len(chain)], spread))
+			}
+		}
+		state[name] = done
+	}
+
+	for _, fragment := range v.doc.Fragments {
+		if state[fragment.Name] == unvisited {
+			visit([]string{fragment.Name})
+		}
+	}
+}
+
+func (v *validator) selectionSet(parent *Type, selections []Selection, sc *scope) {
+	for _, selection := range selections {
+		switch sel := selection.(type) {
+		case *Field:
+			v.field(parent, sel, sc)
+		case *FragmentSpread:
+			v.directives(sel.Directives, "FRAGMENT_SPREAD", sc)
+			sc.spreads = append(sc.spreads, sel.Name)
+			v.used[sel.Name] = true
+
+			fragment, ok := v.fragments[sel.Name]
+			if !ok {
+				v.errorf(sel.Loc, "Unknown fragment %q.", sel.Name)
+				continue
+			}
+			if t, ok := v.schema.Types[fragment.TypeCondition]; ok && t.IsComposite() && !v.schema.overlap(parent, t) {
+				v.errorf(sel.Loc, "Fragment %q cannot be spread here as objects of type %q can never be of type %q.", sel.Name, parent.Name, t.Name)
+			}
+		case *InlineFragment:
+			v.directives(sel.Directives, "INLINE_FRAGMENT", sc)
+
+			t := parent
+			if sel.TypeCondition != "" {
+				var ok bool
+				if t, ok = v.schema.Types[sel.TypeCondition]; !ok {
+					v.errorf(sel.Loc, "Unknown type %q.", sel.TypeCondition)
+					continue
+				}
+				if !t.IsComposite() {
+					v.errorf(sel.Loc, "Fragment cannot condition on non composite type %q.", t.Name)
+					continue
+				}
+				if !v.schema.overlap(parent, t) {
+					v.errorf(sel.Loc, "Fragment cannot be spread here as objects of type %q can never be of type %q.", parent.Name, t.Name)
+				}
+			}
+			v.selectionSet(t, sel.Selections, sc)
+		}
+	}
+}
+
+func (v *validator) field(parent *Type, field *Field, sc *scope) {
+	v.directives(field.Directives, "FIELD", sc)
+
+	def := v.schema.fieldDefinition(parent, field.Name)
+	if def == nil {
+		v.errorf(field.Loc, "Cannot query field %q on type %q.", field.Name, parent.Name)
+		return
+	}
+
+	v.arguments(field.Arguments, def.Arguments, fmt.Sprintf("field \"%s.%s\"", parent.Name, field.Name), field.Loc, sc)
+
+	t := v.schema.Types[def.Type.NamedType()]
+	switch {
+	case t.IsLeaf() && len(field.Selections) > 0:
+		v.errorf(field.Loc, "Field %q must not have a selection since type %q has no subfields.", field.Name, def.Type)
+	case !t.IsLeaf() && len(field.Selections) == 0:
+		v.errorf(field.Loc, "Field %q of type %q must have a selection of subfields. Did you mean \"%s { ... }\"?", field.Name, def.Type, field.Name)
+	case !t.IsLeaf():
+		v.selectionSet(t, field.Selections, sc)
+	}
+}
+
+func (v *validator) directives(directives []*Directive, location string, sc *scope) {
+	seen := map[string]bool{}
+	for _, directive := range directives {
+		def, ok := v.schema.Directives[directive.Name]
+		if !ok {
+			v.errorf(directive.Loc, "Unknown directive \"@%s\".", directive.Name)
+			continue
+		}
+
+		allowed := false
+		for _, l := range def.Locations {
+			allowed = allowed || l == location
+		}
+		if !allowed {
+			v.errorf(directive.Loc, "Directive \"@%s\" may not be used on %s.", directive.Name, location)
+		}
+		if seen[directive.Name] && !def.Repeatable {
+			v.errorf(directive.Loc, "The directive \"@%s\" can only be used once at this location.", directive.Name)
+		}
+		seen[directive.Name] = true
+
+		v.arguments(directive.Arguments, def.Arguments, fmt.Sprintf("directive \"@%s\"", directive.Name), directive.Loc, sc)
+	}
+}
+
+func (v *validator) arguments(arguments []*Argument, defs []*InputValueDefinition, owner string, loc Location, sc *scope) {
+	given := map[string]bool{}
+	for _, argument := range arguments {
+		if given[argument.Name] {
+			v.errorf(argument.Loc, "There can be only one argument named %q.", argument.Name)
+			continue
+		}
+		given[argument.Name] = true
+
+		def := findInputValue(defs, argument.Name)
+		if def == nil {
+			v.errorf(argument.Loc, "Unknown argument %q on %s.", argument.Name, owner)
+			continue
+		}
+
+		msg := checkLiteral(v.schema, argument.Value, def.Type, func(value *Value, expected *TypeRef, hasDefault bool) {
+			sc.usages = append(sc.usages, variableUsage{name: value.Raw, expected: expected, hasDefault: hasDefault, loc: value.Loc})
+		}, def.Default != nil)
+		if msg != "" {
+			v.errorf(argument.Value.Loc, "%s", msg)
+		}
+	}
+
+	for _, def := range defs {
+		if def.Type.NonNull && def.Default == nil && !given[def.Name] {
+			v.errorf(loc, "Argument %q of type %q is required on %s, but it was not provided.", def.Name, def.Type, owner)
+		}
+	}
+}
+
+// variableFunc is called for each variable found in a literal with the type expected there
+type variableFunc func(value *Value, expected *TypeRef, hasDefault bool)
+
+// checkLiteral checks that a literal can be coerced to the type, returning an error message or "".
+// Literals without onVariable must be constant.
+func checkLiteral(s *Schema, value *Value, expected *TypeRef, onVariable variableFunc, hasDefault ...bool) string {
+	if value.Kind == VariableValue {
+		if onVariable == nil {
+			return fmt.Sprintf("Unexpected variable \"$%s\" in constant value.", value.Raw)
+		}
+		onVariable(value, expected, len(hasDefault) > 0 && hasDefault[0])
+		return ""
+	}
+
+	if value.Kind == NullValue {
+		if expected.NonNull {
+			return fmt.Sprintf("Expected value of type %q, found null.", expected)
+		}
+		return ""
+	}
+
+	if expected.Elem != nil {
+		if value.Kind != ListValue {
+			return checkLiteral(s, value, expected.Elem, onVariable)
+		}
+		for _, item := range value.List {
+			if msg := checkLiteral(s, item, expected.Elem, onVariable); msg != "" {
+				return msg
+			}
+		}
+		return ""
+	}
+
+	t, ok := s.Types[expected.Name]
+	if !ok {
+		return fmt.Sprintf("Unknown type %q.", expected.Name)
+	}
+
+	switch t.Kind {
+	case KindInputObject:
+		if value.Kind != ObjectValue {
+			return fmt.Sprintf("Expected value of type %q, found %s.", expected, printValue(value))
+		}
+		given := map[string]bool{}
+		for _, field := range value.Fields {
+			def := t.InputField(field.Name)
+			if def == nil {
+				return fmt.Sprintf("Field %q is not defined by type %q.", field.Name, t.Name)
+			}
+			if given[field.Name] {
+				return fmt.Sprintf("There can be only one input field named %q.", field.Name)
+			}
+			given[field.Name] = true
+			if msg := checkLiteral(s, field.Value, def.Type, onVariable, def.Default != nil); msg != "" {
+				return msg
+			}
+		}
+		for _, def := range t.InputFields {
+			if def.Type.NonNull && def.Default == nil && !given[def.Name] {
+				return fmt.Sprintf("Field \"%s.%s\" of required type %q was not provided.", t.Name, def.Name, def.Type)
+			}
+		}
+		return ""
+	case KindEnum:
+		if value.Kind != EnumValue || !t.HasEnumValue(value.Raw) {
+			return fmt.Sprintf("Value %s does not exist in %q enum.", printValue(value), t.Name)
+		}
+		return ""
+	}
+
+	if !scalarLiteralValid(t.Name, value) {
+		return fmt.Sprintf("%s cannot represent value: %s", t.Name, printValue(value))
+	}
+	return ""
+}
+
+// scalarLiteralValid checks a literal against a built-in scalar; custom scalars accept any literal
+func scalarLiteralValid(scalar string, value *Value) bool {
+	switch scalar {
+	case "Int":
+		if value.Kind != IntValue {
+			return false
+		}
+		n, err := strconv.ParseInt(value.Raw, 10, 64)
+		return err == nil && n >= math.MinInt32 && n <= math.MaxInt32
+	case "Float":
+		return value.Kind == IntValue || value.Kind == FloatValue
+	case "String":
+		return value.Kind == StringValue
+	case "Boolean":
+		return value.Kind == BooleanValue
+	case "ID":
+		return value.Kind == StringValue || value.Kind == IntValue
+	}
+	return true
+}
+
+// printValue formats a literal as GraphQL source
+func printValue(value *Value) string {
+	switch value.Kind {
+	case VariableValue:
+		return "$" + value.Raw
+	case StringValue:
+		return strconv.Quote(value.Raw)
+	case ListValue:
+		items := make([]string, len(value.List))
+		for i, item := range value.List {
+			items[i] = printValue(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case ObjectValue:
+		fields := make([]string, len(value.Fields))
+		for i, field := range value.Fields {
+			fields[i] = field.Name + ": " + printValue(field.Value)
+		}
+		return "{" + strings.Join(fields, ", ") + "}"
+	}
+	return value.Raw
+}
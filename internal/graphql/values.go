@@ -0,0 +1,249 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// coerceVariables checks the request's variable values against the operation's definitions
+func (s *Schema) coerceVariables(op *Operation, inputs map[string]interface{}) (map[string]interface{}, []*Error) {
+	values := map[string]interface{}{}
+	var errs []*Error
+	for _, def := range op.Variables {
+		input, ok := inputs[def.Name]
+		switch {
+		case !ok && def.Default != nil:
+			values[def.Name] = s.valueFromLiteral(def.Default, def.Type, nil)
+		case !ok && def.Type.NonNull:
+			errs = append(errs, newError(CodeBadUserInput, &def.Loc, "Variable \"$%s\" of required type %q was not provided.", def.Name, def.Type))
+		case !ok:
+		case input == nil && def.Type.NonNull:
+			errs = append(errs, newError(CodeBadUserInput, &def.Loc, "Variable \"$%s\" of non-null type %q must not be null.", def.Name, def.Type))
+		default:
+			value, err := s.coerceInput(input, def.Type)
+			if err != nil {
+				errs = append(errs, newError(CodeBadUserInput, &def.Loc, "Variable \"$%s\" got invalid value %s; %v", def.Name, describe(input), err))
+				continue
+			}
+			values[def.Name] = value
+		}
+	}
+	return values, errs
+}
+
+// coerceInput converts a JSON variable value to the input type
+func (s *Schema) coerceInput(value interface{}, t *TypeRef) (interface{}, error) {
+	if value == nil {
+		if t.NonNull {
+			return nil, fmt.Errorf("Expected non-nullable type %q not to be null.", t)
+		}
+		return nil, nil
+	}
+
+	if t.Elem != nil {
+		items, ok := value.([]interface{})
+		if !ok {
+			// A single value is a list of one
+			item, err := s.coerceInput(value, t.Elem)
+			if err != nil {
+				return nil, err
+			}
+			return []interface{}{item}, nil
+		}
+		result := make([]interface{}, len(items))
+		for i, item := range items {
+			coerced, err := s.coerceInput(item, t.Elem)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = coerced
+		}
+		return result, nil
+	}
+
+	named := s.Types[t.Name]
+	switch named.Kind {
+	case KindEnum:
+		if name, ok := value.(string); ok && named.HasEnumValue(name) {
+			return name, nil
+		}
+		return nil, fmt.Errorf("Value %s does not exist in %q enum.", describe(value), named.Name)
+	case KindInputObject:
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("Expected type %q to be an object.", named.Name)
+		}
+		for name := range fields {
+			if named.InputField(name) == nil {
+				return nil, fmt.Errorf("Field %q is not defined by type %q.", name, named.Name)
+			}
+		}
+		result := map[string]interface{}{}
+		for _, def := range named.InputFields {
+			field, ok := fields[def.Name]
+			switch {
+			case !ok && def.Default != nil:
+				result[def.Name] = s.valueFromLiteral(def.Default, def.Type, nil)
+			case !ok && def.Type.NonNull:
+				return nil, fmt.Errorf("Field %q of required type %q was not provided.", def.Name, def.Type)
+			case ok:
+				coerced, err := s.coerceInput(field, def.Type)
+				if err != nil {
+					return nil, err
+				}
+				result[def.Name] = coerced
+			}
+		}
+		return result, nil
+	}
+
+	switch named.Name {
+	case "Int":
+		if n, ok := toFloat(value); ok && n == math.Trunc(n) && n >= math.MinInt32 && n <= math.MaxInt32 {
+			return int64(n), nil
+		}
+		return nil, fmt.Errorf("Int cannot represent non 32-bit signed integer value: %s", describe(value))
+	case "Float":
+		if n, ok := toFloat(value); ok {
+			return n, nil
+		}
+		return nil, fmt.Errorf("Float cannot represent non numeric value: %s", describe(value))
+	case "String":
+		if str, ok := value.(string); ok {
+			return str, nil
+		}
+		return nil, fmt.Errorf("String cannot represent a non string value: %s", describe(value))
+	case "Boolean":
+		if b, ok := value.(bool); ok {
+			return b, nil
+		}
+		return nil, fmt.Errorf("Boolean cannot represent a non boolean value: %s", describe(value))
+	case "ID":
+		switch v := value.(type) {
+		case string:
+			return v, nil
+		case json.Number:
+			if _, err := v.Int64(); err == nil {
+				return v.String(), nil
+			}
+		}
+		return nil, fmt.Errorf("ID cannot represent value: %s", describe(value))
+	}
+	return value, nil
+}
+
+// arguments coerces the arguments of a field or directive, applying defaults.
+// Arguments that are neither given nor defaulted are left out.
+func (e *executor) arguments(defs []*InputValueDefinition, arguments []*Argument) map[string]interface{} {
+	values := map[string]interface{}{}
+	for _, def := range defs {
+		var given *Argument
+		for _, argument := range arguments {
+			if argument.Name == def.Name {
+				given = argument
+			}
+		}
+
+		if given != nil {
+			if given.Value.Kind == VariableValue {
+				if value, ok := e.variables[given.Value.Raw]; ok {
+					values[def.Name] = value
+					continue
+				}
+			} else {
+				values[def.Name] = e.schema.valueFromLiteral(given.Value, def.Type, e.variables)
+				continue
+			}
+		}
+		if def.Default != nil {
+			values[def.Name] = e.schema.valueFromLiteral(def.Default, def.Type, nil)
+		}
+	}
+	return values
+}
+
+// valueFromLiteral converts a validated literal to the value of the input type
+func (s *Schema) valueFromLiteral(value *Value, t *TypeRef, variables map[string]interface{}) interface{} {
+	switch value.Kind {
+	case VariableValue:
+		return variables[value.Raw]
+	case NullValue:
+		return nil
+	}
+
+	if t.Elem != nil {
+		if value.Kind != ListValue {
+			return []interface{}{s.valueFromLiteral(value, t.Elem, variables)}
+		}
+		items := make([]interface{}, len(value.List))
+		for i, item := range value.List {
+			items[i] = s.valueFromLiteral(item, t.Elem, variables)
+		}
+		return items
+	}
+
+	named := s.Types[t.Name]
+	if named != nil && named.Kind == KindInputObject {
+		result := map[string]interface{}{}
+		for _, def := range named.InputFields {
+			var given *ObjectField
+			for _, field := range value.Fields {
+				if field.Name == def.Name {
+					given = field
+				}
+			}
+			switch {
+			case given != nil && given.Value.Kind == VariableValue:
+				if v, ok := variables[given.Value.Raw]; ok {
+					result[def.Name] = v
+				} else if def.Default != nil {
+					result[def.Name] = s.valueFromLiteral(def.Default, def.Type, nil)
+				}
+			case given != nil:
+				result[def.Name] = s.valueFromLiteral(given.Value, def.Type, variables)
+			case def.Default != nil:
+				result[def.Name] = s.valueFromLiteral(def.Default, def.Type, nil)
+			}
+		}
+		return result
+	}
+
+	return literalScalar(value, t.Name)
+}
+
+// literalScalar converts a scalar or enum literal
+func literalScalar(value *Value, scalar string) interface{} {
+	switch value.Kind {
+	case IntValue:
+		switch scalar {
+		case "Float":
+			n, _ := strconv.ParseFloat(value.Raw, 64)
+			return n
+		case "ID":
+			return value.Raw
+		}
+		n, _ := strconv.ParseInt(value.Raw, 10, 64)
+		return n
+	case FloatValue:
+		n, _ := strconv.ParseFloat(value.Raw, 64)
+		return n
+	case BooleanValue:
+		return value.Raw == "true"
+	case ListValue:
+		items := make([]interface{}, len(value.List))
+		for i, item := range value.List {
+			items[i] = literalScalar(item, scalar)
+		}
+		return items
+	case ObjectValue:
+		fields := map[string]interface{}{}
+		for _, field := range value.Fields {
+			fields[field.Name] = literalScalar(field.Value, scalar)
+		}
+		return fields
+	}
+	// Strings and enum values
+	return value.Raw
+}
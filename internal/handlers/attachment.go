@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"mockj-go/internal/config"
+	"mockj-go/internal/models"
+	"mockj-go/internal/storage"
+)
+
+// multipartOverhead leaves room for the boundaries and fields around the uploaded file
+const multipartOverhead = 1 << 20
+
+// AttachmentHandler stores binary files attached to mocks and serves them
+type AttachmentHandler struct {
+	*JSONHandler
+	store storage.Store
+	cfg   config.AttachmentConfig
+}
+
+// UploadAttachmentForm describes the multipart form of POST /api/json/{id}/attachments
+type UploadAttachmentForm struct {
+	File     string `json:"file"`
+	Password string `json:"password,omitempty"`
+}
+
+// NewAttachmentHandler serves the attachments kept in store, which the cleanup routine sweeps
+func NewAttachmentHandler(jsonHandler *JSONHandler, store storage.Store, cfg config.AttachmentConfig) *AttachmentHandler {
+	return &AttachmentHandler{
+		JSONHandler: jsonHandler,
+		store:       store,
+		cfg:         cfg,
+	}
+}
+
+// UploadAttachment handles POST /api/json/{id}/attachments - stores the "file" part of a
+// multipart form. The password comes from the X-Mock-Password header or a "password" field.
+func (h *AttachmentHandler) UploadAttachment(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromPath(r.URL.Path)
+	if id == "" {
+		h.writeError(w, http.StatusBadRequest, "invalid_id", "ID is required")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.cfg.MaxSize+multipartOverhead)
+	if err := r.ParseMultipartForm(h.cfg.MaxSize); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			h.writeError(w, http.StatusRequestEntityTooLarge, "too_large", "Attachment exceeds the upload size limit")
+		} else {
+			h.writeError(w, http.StatusBadRequest, "invalid_request", "Invalid multipart form")
+		}
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	password := r.Header.Get(mockPasswordHeader)
+	if password == "" {
+		password = r.FormValue("password")
+	}
+	if _, ok := h.authorize(r.Context(), w, id, password); !ok {
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid_request", "A file part is required")
+		return
+	}
+	defer file.Close()
+
+	if header.Size > h.cfg.MaxSize {
+		h.writeError(w, http.StatusRequestEntityTooLarge, "too_large", "Attachment exceeds the upload size limit")
+		return
+	}
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid_request", "Failed to read file")
+		return
+	}
+
+	filename := filepath.Base(filepath.Clean("/" + header.Filename))
+	if filename == "/" || filename == "." {
+		filename = "attachment"
+	}
+
+	sum := sha256.Sum256(data)
+	attachment := models.NewAttachment(id, filename, detectContentType(header.Header.Get("Content-Type"), filename, data),
+		int64(len(data)), hex.EncodeToString(sum[:]))
+
+	if err := h.store.Create(r.Context(), attachment, data); err != nil {
+		if err.Error() == "attachment quota exceeded" {
+			h.writeError(w, http.StatusRequestEntityTooLarge, "quota_exceeded", "Attachment storage quota exceeded")
+		} else {
+			h.writeError(w, http.StatusInternalServerError, "database_error", "Failed to store attachment")
+		}
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, SuccessResponse{
+		Data:    attachment,
+		Message: "Attachment uploaded successfully",
+	})
+}
+
+// ListAttachments handles GET /api/json/{id}/attachments
+func (h *AttachmentHandler) ListAttachments(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromPath(r.URL.Path)
+	if id == "" {
+		h.writeError(w, http.StatusBadRequest, "invalid_id", "ID is required")
+		return
+	}
+
+	if !h.mockExists(r.Context(), w, id) {
+		return
+	}
+
+	attachments, err := h.db.ListAttachments(r.Context(), id)
+	if err != nil {
+		h.writeError(w, http.StatusInternalServerError, "database_error", "Failed to list attachments")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, SuccessResponse{Data: attachments})
+}
+
+// GetAttachment handles GET /api/json/{id}/attachments/{attachmentId} - serves the file with
+// Range support. It is shown inline unless ?download=true asks for a download.
+func (h *AttachmentHandler) GetAttachment(w http.ResponseWriter, r *http.Request) {
+	id, attachmentID := extractAttachmentPath(r.URL.Path)
+	if id == "" || attachmentID == "" {
+		h.writeError(w, http.StatusBadRequest, "invalid_id", "ID is required")
+		return
+	}
+
+	if !h.mockExists(r.Context(), w, id) {
+		return
+	}
+
+	attachment, ok := h.getAttachment(r.Context(), w, id, attachmentID)
+	if !ok {
+		return
+	}
+
+	content, err := h.store.Open(r.Context(), attachment.ID)
+	if err != nil {
+		h.writeError(w, http.StatusInternalServerError, "storage_error", "Failed to open attachment")
+		return
+	}
+	defer content.Close()
+
+	disposition := "inline"
+	if r.URL.Query().Get("download") == "true" {
+		disposition = "attachment"
+	}
+
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": attachment.Filename}))
+	w.Header().Set("ETag", `"`+attachment.SHA256+`"`)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	// ServeContent answers Range and conditional requests and sets Content-Length
+	http.ServeContent(w, r, "", attachment.CreatedAt, content)
+}
+
+// DeleteAttachment handles DELETE /api/json/{id}/attachments/{attachmentId}
+func (h *AttachmentHandler) DeleteAttachment(w http.ResponseWriter, r *http.Request) {
+	id, attachmentID := extractAttachmentPath(r.URL.Path)
+	if id == "" || attachmentID == "" {
+		h.writeError(w, http.StatusBadRequest, "invalid_id", "ID is required")
+		return
+	}
+
+	var req PasswordRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid_request", "Invalid JSON body")
+		return
+	}
+
+	if _, ok := h.authorize(r.Context(), w, id, req.Password); !ok {
+		return
+	}
+
+	if err := h.store.Delete(r.Context(), id, attachmentID); err != nil {
+		if err.Error() == "attachment not found" {
+			h.writeError(w, http.StatusNotFound, "not_found", "Attachment not found")
+		} else {
+			h.writeError(w, http.StatusInternalServerError, "database_error", "Failed to delete attachment")
+		}
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, SuccessResponse{
+		Message: "Attachment deleted successfully",
+	})
+}
+
+// mockExists writes a 404 when the mock is missing or expired
+func (h *AttachmentHandler) mockExists(ctx context.Context, w http.ResponseWriter, id string) bool {
+	if _, err := h.db.GetJSON(ctx, id); err != nil {
+		if err.Error() == "json not found or expired" {
+			h.writeError(w, http.StatusNotFound, "not_found", "JSON not found or expired")
+		} else {
+			h.writeError(w, http.StatusInternalServerError, "database_error", "Failed to retrieve JSON")
+		}
+		return false
+	}
+	return true
+}
+
+func (h *AttachmentHandler) getAttachment(ctx context.Context, w http.ResponseWriter, id, attachmentID string) (*models.Attachment, bool) {
+	attachment, err := h.db.GetAttachment(ctx, id, attachmentID)
+	if err != nil {
+		if err.Error() == "attachment not found" {
+			h.writeError(w, http.StatusNotFound, "not_found", "Attachment not found")
+		} else {
+			h.writeError(w, http.StatusInternalServerError, "database_error", "Failed to retrieve attachment")
+		}
+		return nil, false
+	}
+	return attachment, true
+}
+
+// detectContentType prefers the type declared by the client, then the file extension,
+// and finally sniffs the content
+func detectContentType(declared, filename string, data []byte) string {
+	if mediaType, _, err := mime.ParseMediaType(declared); err == nil && mediaType != "application/octet-stream" {
+		return declared
+	}
+	if byExtension := mime.TypeByExtension(filepath.Ext(filename)); byExtension != "" {
+		return byExtension
+	}
+	return http.DetectContentType(data)
+}
+
+// extractAttachmentPath extracts the mock and attachment IDs from /api/json/{id}/attachments/{attachmentId}
+func extractAttachmentPath(path string) (string, string) {
+	parts := strings.Split(path, "/")
+	if len(parts) >= 6 {
+		return parts[3], parts[5]
+	}
+	return extractIDFromPath(path), ""
+}
@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"os"
+	"sync"
+	"testing"
+
+	"mockj-go/internal/config"
+	"mockj-go/internal/database"
+	"mockj-go/internal/events"
+	"mockj-go/internal/storage"
+)
+
+func TestAttachments(t *testing.T) {
+	for _, backend := range []string{storage.SQLite, storage.Dir} {
+		t.Run(backend, func(t *testing.T) {
+			testAttachments(t, config.AttachmentConfig{
+				Storage: backend,
+				Dir:     t.TempDir(),
+				MaxSize: 1 << 10,
+				Quota:   2 << 10,
+			})
+		})
+	}
+}
+
+func testAttachments(t *testing.T, cfg config.AttachmentConfig) {
+	db, err := database.NewDatabase(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	store, err := storage.New(db, cfg)
+	if err != nil {
+		t.Fatalf("Failed to create attachment store: %v", err)
+	}
+	handler := NewAttachmentHandler(NewJSONHandler(db, events.NewBus(), nil), store, cfg)
+
+	id := createTestJSON(t, handler.JSONHandler, map[string]interface{}{"json": `{"a": 1}`, "password": "test123"})
+
+	upload := func(t *testing.T, filename, contentType, password string, data []byte) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		if password != "" {
+			_ = writer.WriteField("password", password)
+		}
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Disposition", `form-data; name="file"; filename="`+filename+`"`)
+		if contentType != "" {
+			header.Set("Content-Type", contentType)
+		}
+		part, _ := writer.CreatePart(header)
+		_, _ = part.Write(data)
+		_ = writer.Close()
+
+		req := httptest.NewRequest("POST", "/api/json/"+id+"/attachments", &body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		w := httptest.NewRecorder()
+		handler.UploadAttachment(w, req)
+		return w
+	}
+
+	get := func(t *testing.T, path string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		w := httptest.NewRecorder()
+		handler.GetAttachment(w, req)
+		return w
+	}
+
+	pdf := []byte("%PDF-1.4\n\xff\xfe\x00 some binary content")
+
+	var attachment struct {
+		ID          string `json:"id"`
+		ContentType string `json:"contentType"`
+		Size        int64  `json:"size"`
+	}
+
+	t.Run("Upload", func(t *testing.T) {
+		if w := upload(t, "report.pdf", "", "wrong", pdf); w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status %d, got %d", http.StatusUnauthorized, w.Code)
+		}
+
+		w := upload(t, "report.pdf", "application/octet-stream", "test123", pdf)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected status %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+		}
+
+		var response struct {
+			Data json.RawMessage `json:"data"`
+		}
+		_ = json.NewDecoder(w.Body).Decode(&response)
+		_ = json.Unmarshal(response.Data, &attachment)
+
+		// The extension wins over a generic declared type
+		if attachment.ContentType != "application/pdf" || attachment.Size != int64(len(pdf)) {
+			t.Errorf("Expected a %d byte application/pdf, got %+v", len(pdf), attachment)
+		}
+	})
+
+	t.Run("Serve", func(t *testing.T) {
+		w := get(t, "/api/json/"+id+"/attachments/"+attachment.ID, nil)
+		if w.Code != http.StatusOK || !bytes.Equal(w.Body.Bytes(), pdf) {
+			t.Fatalf("Expected the file, got %d %q", w.Code, w.Body.Bytes())
+		}
+		if w.Header().Get("Content-Type") != "application/pdf" {
+			t.Errorf("Expected application/pdf, got %q", w.Header().Get("Content-Type"))
+		}
+		if w.Header().Get("Content-Disposition") != `inline; filename=report.pdf` {
+			t.Errorf("Unexpected Content-Disposition %q", w.Header().Get("Content-Disposition"))
+		}
+
+		w = get(t, "/api/json/"+id+"/attachments/"+attachment.ID+"?download=true", nil)
+		if w.Header().Get("Content-Disposition") != `attachment; filename=report.pdf` {
+			t.Errorf("Unexpected Content-Disposition %q", w.Header().Get("Content-Disposition"))
+		}
+	})
+
+	t.Run("Range", func(t *testing.T) {
+		w := get(t, "/api/json/"+id+"/attachments/"+attachment.ID, map[string]string{"Range": "bytes=0-7"})
+		if w.Code != http.StatusPartialContent || w.Body.String() != "%PDF-1.4" {
+			t.Errorf("Expected the first 8 bytes, got %d %q", w.Code, w.Body.String())
+		}
+		if w.Header().Get("Content-Length") != "8" {
+			t.Errorf("Expected Content-Length 8, got %q", w.Header().Get("Content-Length"))
+		}
+
+		w = get(t, "/api/json/"+id+"/attachments/"+attachment.ID, map[string]string{"Range": "bytes=1000-"})
+		if w.Code != http.StatusRequestedRangeNotSatisfiable {
+			t.Errorf("Expected status %d, got %d", http.StatusRequestedRangeNotSatisfiable, w.Code)
+		}
+	})
+
+	t.Run("List", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/json/"+id+"/attachments", nil)
+		w := httptest.NewRecorder()
+		handler.ListAttachments(w, req)
+
+		var response struct {
+			Data []json.RawMessage `json:"data"`
+		}
+		_ = json.NewDecoder(w.Body).Decode(&response)
+		if w.Code != http.StatusOK || len(response.Data) != 1 {
+			t.Errorf("Expected one attachment, got %d %d", w.Code, len(response.Data))
+		}
+	})
+
+	t.Run("Limits", func(t *testing.T) {
+		if w := upload(t, "big.bin", "", "test123", make([]byte, cfg.MaxSize+1)); w.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("Expected status %d for a large file, got %d", http.StatusRequestEntityTooLarge, w.Code)
+		}
+
+		// The quota holds two full files, and the PDF already uses some of it
+		if w := upload(t, "a.bin", "", "test123", make([]byte, cfg.MaxSize)); w.Code != http.StatusCreated {
+			t.Fatalf("Expected status %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+		}
+		if w := upload(t, "b.bin", "", "test123", make([]byte, cfg.MaxSize)); w.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("Expected status %d over quota, got %d", http.StatusRequestEntityTooLarge, w.Code)
+		}
+	})
+
+	t.Run("QuotaHoldsConcurrently", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				upload(t, "c.bin", "", "test123", make([]byte, cfg.MaxSize/4))
+			}()
+		}
+		wg.Wait()
+
+		attachments, err := db.ListAttachments(t.Context(), id)
+		if err != nil {
+			t.Fatalf("Failed to list attachments: %v", err)
+		}
+		var usage int64
+		for _, attachment := range attachments {
+			usage += attachment.Size
+		}
+		if usage > cfg.Quota {
+			t.Errorf("Expected usage within the quota of %d, got %d", cfg.Quota, usage)
+		}
+	})
+
+	t.Run("SweepKeepsAttachments", func(t *testing.T) {
+		if err := handler.store.Sweep(t.Context()); err != nil {
+			t.Fatalf("Failed to sweep: %v", err)
+		}
+		if w := get(t, "/api/json/"+id+"/attachments/"+attachment.ID, nil); w.Code != http.StatusOK {
+			t.Errorf("Expected status %d after a sweep, got %d", http.StatusOK, w.Code)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]interface{}{"password": "test123"})
+		req := httptest.NewRequest("DELETE", "/api/json/"+id+"/attachments/"+attachment.ID, bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		handler.DeleteAttachment(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		if w := get(t, "/api/json/"+id+"/attachments/"+attachment.ID, nil); w.Code != http.StatusNotFound {
+			t.Errorf("Expected status %d after delete, got %d", http.StatusNotFound, w.Code)
+		}
+	})
+
+	t.Run("RemovedWithMock", func(t *testing.T) {
+		if err := db.DeleteJSON(t.Context(), id); err != nil {
+			t.Fatalf("Failed to delete mock: %v", err)
+		}
+		if err := handler.store.Sweep(t.Context()); err != nil {
+			t.Fatalf("Failed to sweep: %v", err)
+		}
+		if attachments, _ := db.ListAttachments(t.Context(), id); len(attachments) != 0 {
+			t.Errorf("Expected no attachments left, got %d", len(attachments))
+		}
+		if entries, _ := os.ReadDir(cfg.Dir); len(entries) != 0 {
+			t.Errorf("Expected no files left, got %d", len(entries))
+		}
+	})
+}
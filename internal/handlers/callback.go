@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"mockj-go/internal/callback"
+	"mockj-go/internal/models"
+)
+
+// scheduleCallbacks submits the mock's callbacks for a hit it has just served
+func (h *JSONHandler) scheduleCallbacks(w http.ResponseWriter, r *http.Request, jsonModel *models.JSON) {
+	if h.callbacks == nil || len(jsonModel.Callbacks) == 0 {
+		return
+	}
+
+	// The journal has already consumed the request body
+	var body []byte
+	if jw, ok := w.(*journalWriter); ok {
+		body = jw.body
+	}
+	lookup := callbackValues(r, jsonModel, body)
+
+	for _, cb := range jsonModel.Callbacks {
+		headers := make(map[string]string, len(cb.Headers))
+		for name, value := range cb.Headers {
+			headers[name] = expandCallback(value, lookup, nil)
+		}
+		h.callbacks.Submit(r.Context(), callback.Request{
+			MockID:  jsonModel.ID,
+			Method:  cb.RequestMethod(),
+			URL:     expandCallback(cb.URL, lookup, url.PathEscape),
+			Body:    expandCallback(cb.Body, lookup, nil),
+			Headers: headers,
+			Delay:   time.Duration(cb.DelayMs) * time.Millisecond,
+		})
+	}
+}
+
+// expandCallback fills the placeholders of a callback template, escaping the values with escape.
+// URL templates have a fixed host, so escaped values only ever land in the path or query.
+func expandCallback(template string, lookup func(string) string, escape func(string) string) string {
+	return models.CallbackPlaceholder.ReplaceAllStringFunc(template, func(placeholder string) string {
+		value := lookup(models.CallbackPlaceholder.FindStringSubmatch(placeholder)[1])
+		if escape != nil {
+			return escape(value)
+		}
+		return value
+	})
+}
+
+// callbackValues returns the placeholder values of a hit; unknown or missing values are empty
+func callbackValues(r *http.Request, jsonModel *models.JSON, body []byte) func(string) string {
+	var params map[string]string
+	if jsonModel.Route != "" {
+		params = models.RouteParams(jsonModel.Route, r.URL.Path)
+	}
+
+	var decoded interface{}
+	decodedBody := json.Unmarshal(body, &decoded) == nil
+
+	return func(name string) string {
+		root, key, _ := strings.Cut(name, ".")
+		switch root {
+		case "method":
+			return r.Method
+		case "path":
+			return r.URL.Path
+		case "mockId":
+			return jsonModel.ID
+		case "params":
+			return params[key]
+		case "query":
+			return r.URL.Query().Get(key)
+		case "header":
+			return r.Header.Get(key)
+		case "body":
+			if key == "" {
+				return string(body)
+			}
+			if !decodedBody {
+				return ""
+			}
+			return jsonPathValue(decoded, strings.Split(key, "."))
+		}
+		return ""
+	}
+}
+
+// jsonPathValue walks object keys and array indexes; strings are returned bare, other values as JSON
+func jsonPathValue(value interface{}, path []string) string {
+	for _, segment := range path {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			value = v[segment]
+		case []interface{}:
+			i, err := strconv.Atoi(segment)
+			if err != nil || i < 0 || i >= len(v) {
+				return ""
+			}
+			value = v[i]
+		default:
+			return ""
+		}
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
+
+// ListCallbacks handles GET /api/json/{id}/callbacks - the results of the mock's callbacks,
+// newest first. It takes the mock's password in X-Mock-Password, as the URLs may carry
+// values of the hits.
+func (h *JSONHandler) ListCallbacks(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromPath(r.URL.Path)
+	if id == "" {
+		h.writeError(w, http.StatusBadRequest, "invalid_id", "ID is required")
+		return
+	}
+
+	if _, ok := h.authorize(r.Context(), w, id, r.Header.Get(mockPasswordHeader)); !ok {
+		return
+	}
+
+	limit := defaultJournalLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			h.writeError(w, http.StatusBadRequest, "invalid_filter", "invalid limit parameter")
+			return
+		}
+		limit = min(n, maxJournalLimit)
+	}
+
+	results, err := h.db.ListCallbackResults(r.Context(), id, limit)
+	if err != nil {
+		h.writeError(w, http.StatusInternalServerError, "database_error", "Failed to list callbacks")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, SuccessResponse{
+		Data: results,
+	})
+}
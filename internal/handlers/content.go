@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"mockj-go/internal/events"
+	"mockj-go/internal/models"
+)
+
+// maxContentSize caps raw content uploads
+const maxContentSize = 10 << 20
+
+// mockPasswordHeader carries the mock's password for requests whose body is the content itself
+const mockPasswordHeader = "X-Mock-Password"
+
+// UploadJSONContent handles PUT /api/json/{id}/content - replaces the mock's content with
+// the raw request body. The content type follows ?type= or else the Content-Type header.
+func (h *JSONHandler) UploadJSONContent(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromPath(r.URL.Path)
+	if id == "" {
+		h.writeError(w, http.StatusBadRequest, "invalid_id", "ID is required")
+		return
+	}
+
+	jsonModel, ok := h.authorize(r.Context(), w, id, r.Header.Get(mockPasswordHeader))
+	if !ok {
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxContentSize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			h.writeError(w, http.StatusRequestEntityTooLarge, "too_large", "Content exceeds the upload size limit")
+		} else {
+			h.writeError(w, http.StatusBadRequest, "invalid_request", "Failed to read body")
+		}
+		return
+	}
+
+	if len(body) == 0 {
+		h.writeError(w, http.StatusBadRequest, "invalid_content", "Content cannot be empty")
+		return
+	}
+
+	contentType := r.URL.Query().Get("type")
+	if contentType == "" {
+		contentType = models.ContentTypeOf(r.Header.Get("Content-Type"))
+	}
+
+	// Binary uploads are stored base64-encoded
+	jsonModel.Content, jsonModel.ContentType = models.EncodeContent(body, contentType)
+	if err := models.ValidateContent(jsonModel.ContentType, jsonModel.Content); err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid_content", err.Error())
+		return
+	}
+	if err := validateGraphQL(jsonModel); err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid_schema", err.Error())
+		return
+	}
+
+	// Keep specific media types such as application/pdf that the content type alone would lose
+	if mediaType := r.Header.Get("Content-Type"); mediaType != "" {
+		if models.ContentTypeOf(mediaType) == jsonModel.ContentType && mediaType != jsonModel.MediaType() {
+			if jsonModel.Headers == nil {
+				jsonModel.Headers = map[string]string{}
+			}
+			jsonModel.Headers["Content-Type"] = mediaType
+		} else {
+			delete(jsonModel.Headers, "Content-Type")
+		}
+	}
+
+	if err := h.db.UpdateJSON(r.Context(), jsonModel); err != nil {
+		h.writeError(w, http.StatusInternalServerError, "database_error", "Failed to update JSON")
+		return
+	}
+	h.publishMock(events.Updated, jsonModel)
+
+	jsonModel.Password = ""
+
+	h.writeJSON(w, http.StatusOK, SuccessResponse{
+		Data:    jsonModel,
+		Message: "Content uploaded successfully",
+	})
+}
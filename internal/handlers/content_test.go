@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"mockj-go/internal/database"
+	"mockj-go/internal/events"
+)
+
+func TestContentTypes(t *testing.T) {
+	db, err := database.NewDatabase(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	handler := NewJSONHandler(db, events.NewBus(), nil)
+
+	getContent := func(t *testing.T, id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/json/"+id+"/content", nil)
+		w := httptest.NewRecorder()
+		handler.GetJSONContent(w, req)
+		return w
+	}
+
+	t.Run("ServedWithMediaType", func(t *testing.T) {
+		tests := []struct {
+			contentType string
+			content     string
+			mediaType   string
+			body        string
+		}{
+			{"", `{"a": 1}`, "application/json", `{"a": 1}`},
+			{"yaml", "a: 1\nb: [x, y]\n", "application/yaml", "a: 1\nb: [x, y]\n"},
+			{"xml", `<?xml version="1.0"?><user id="1"><name>John</name></user>`, "application/xml", `<?xml version="1.0"?><user id="1"><name>John</name></user>`},
+			{"text", "hello", "text/plain; charset=utf-8", "hello"},
+			{"csv", "id,name\n1,John\n", "text/csv; charset=utf-8", "id,name\n1,John\n"},
+			{"binary", "iVBORw0KGgo=", "application/octet-stream", "\x89PNG\r\n\x1a\n"},
+		}
+
+		for _, tt := range tests {
+			id := createTestJSON(t, handler, map[string]interface{}{
+				"json":        tt.content,
+				"contentType": tt.contentType,
+				"password":    "test123",
+			})
+
+			w := getContent(t, id)
+			if got := w.Header().Get("Content-Type"); got != tt.mediaType {
+				t.Errorf("%q: expected Content-Type %q, got %q", tt.contentType, tt.mediaType, got)
+			}
+			if w.Body.String() != tt.body {
+				t.Errorf("%q: expected body %q, got %q", tt.contentType, tt.body, w.Body.String())
+			}
+		}
+	})
+
+	t.Run("ValidatedOnCreate", func(t *testing.T) {
+		tests := map[string]string{
+			"":        `{"a": `,
+			"yaml":    "a: [1, 2",
+			"xml":     "<a><b></a>",
+			"csv":     "a,b\n1,2,3\n",
+			"binary":  "not base64!",
+			"unknown": "x",
+		}
+
+		for contentType, content := range tests {
+			body, _ := json.Marshal(map[string]interface{}{"json": content, "contentType": contentType, "password": "test123"})
+			req := httptest.NewRequest("POST", "/api/json", bytes.NewReader(body))
+			w := httptest.NewRecorder()
+			handler.CreateJSON(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("%q: expected status %d, got %d", contentType, http.StatusBadRequest, w.Code)
+			}
+		}
+	})
+
+	t.Run("ValidatedOnUpdate", func(t *testing.T) {
+		id := createTestJSON(t, handler, map[string]interface{}{"json": `{"a": 1}`, "password": "test123"})
+
+		// Switching the type alone validates the stored content against it
+		body, _ := json.Marshal(map[string]interface{}{"contentType": "xml", "password": "test123"})
+		req := httptest.NewRequest("PUT", "/api/json/"+id, bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		handler.UpdateJSON(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+		}
+	})
+
+	t.Run("RawUpload", func(t *testing.T) {
+		id := createTestJSON(t, handler, map[string]interface{}{"json": `{"a": 1}`, "password": "test123"})
+
+		upload := func(t *testing.T, contentType, password string, body []byte) *httptest.ResponseRecorder {
+			req := httptest.NewRequest("PUT", "/api/json/"+id+"/content", bytes.NewReader(body))
+			req.Header.Set("Content-Type", contentType)
+			req.Header.Set(mockPasswordHeader, password)
+			w := httptest.NewRecorder()
+			handler.UploadJSONContent(w, req)
+			return w
+		}
+
+		if w := upload(t, "text/csv", "wrong", []byte("a,b\n")); w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status %d, got %d", http.StatusUnauthorized, w.Code)
+		}
+
+		if w := upload(t, "text/csv", "test123", []byte("id,name\n1,John\n")); w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		if w := getContent(t, id); !strings.HasPrefix(w.Header().Get("Content-Type"), "text/csv") {
+			t.Errorf("Expected CSV content, got %q", w.Header().Get("Content-Type"))
+		}
+
+		pdf := []byte("%PDF-1.4\n\xff\xfe\x00binary")
+		if w := upload(t, "application/pdf", "test123", pdf); w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		w := getContent(t, id)
+		if !bytes.Equal(w.Body.Bytes(), pdf) || w.Header().Get("Content-Type") != "application/pdf" {
+			t.Errorf("Expected binary round trip, got %q %q", w.Header().Get("Content-Type"), w.Body.Bytes())
+		}
+	})
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"mockj-go/internal/database"
+	"mockj-go/internal/models"
+)
+
+// mockEndpoints are the endpoints below /api/json/{id} that serve the mock itself
+var mockEndpoints = map[string]bool{"content": true, "graphql": true, "stream": true, "ws": true}
+
+// MockCORS returns a lookup of the CORS policy of the mock a request is served by, for
+// middleware.CORS. Preflight requests are matched against routes by the method they ask for.
+func MockCORS(db *database.Database) func(r *http.Request) *models.CORS {
+	return func(r *http.Request) *models.CORS {
+		var (
+			jsonModel *models.JSON
+			err       error
+		)
+
+		parts := strings.Split(r.URL.Path, "/")
+		if strings.HasPrefix(r.URL.Path, "/api/") {
+			if len(parts) != 5 || parts[2] != "json" || !mockEndpoints[parts[4]] {
+				return nil
+			}
+			jsonModel, err = db.GetJSON(r.Context(), parts[3])
+		} else {
+			method := r.Method
+			if requested := r.Header.Get("Access-Control-Request-Method"); method == "OPTIONS" && requested != "" {
+				method = requested
+			}
+			jsonModel, err = db.FindJSONByRoute(r.Context(), method, r.URL.Path)
+		}
+
+		if err != nil {
+			if err.Error() != "json not found or expired" {
+				slog.ErrorContext(r.Context(), "Failed to find CORS policy", "method", r.Method, "path", r.URL.Path, "error", err)
+			}
+			return nil
+		}
+		return jsonModel.CORS
+	}
+}
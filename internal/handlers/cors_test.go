@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"mockj-go/internal/database"
+	"mockj-go/internal/events"
+)
+
+func TestMockCORS(t *testing.T) {
+	db, err := database.NewDatabase(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	handler := NewJSONHandler(db, events.NewBus(), nil)
+	cors := map[string]interface{}{"allowedOrigins": []string{"https://legacy.example"}, "allowCredentials": true}
+	id := createTestJSON(t, handler, map[string]interface{}{
+		"json":     `{"ok": true}`,
+		"password": "test123",
+		"method":   "PUT",
+		"route":    "/legacy/{id}",
+		"cors":     cors,
+	})
+	plain := createTestJSON(t, handler, map[string]interface{}{"json": `{}`, "password": "test123"})
+
+	lookup := MockCORS(db)
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		header   map[string]string
+		override bool
+	}{
+		{"Content", "GET", "/api/json/" + id + "/content", nil, true},
+		{"Metadata", "GET", "/api/json/" + id, nil, false},
+		{"Route", "PUT", "/legacy/7", nil, true},
+		{"RoutePreflight", "OPTIONS", "/legacy/7", map[string]string{"Access-Control-Request-Method": "PUT"}, true},
+		{"RouteOtherMethod", "OPTIONS", "/legacy/7", map[string]string{"Access-Control-Request-Method": "GET"}, false},
+		{"MockWithoutOverride", "GET", "/api/json/" + plain + "/content", nil, false},
+		{"Unknown", "GET", "/nothing", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("Origin", "https://app.example")
+			for name, value := range tt.header {
+				req.Header.Set(name, value)
+			}
+			policy := lookup(req)
+			if tt.override && (policy == nil || !policy.AllowCredentials) {
+				t.Errorf("Expected the mock's policy, got %+v", policy)
+			}
+			if !tt.override && policy != nil {
+				t.Errorf("Expected no override, got %+v", policy)
+			}
+		})
+	}
+
+	t.Run("Validation", func(t *testing.T) {
+		for name, invalid := range map[string]map[string]interface{}{
+			"OriginNoScheme": {"allowedOrigins": []string{"legacy.example"}},
+			"AnyOriginCreds": {"allowedOrigins": []string{"*"}, "allowCredentials": true},
+		} {
+			body, _ := json.Marshal(map[string]interface{}{
+				"json":     `{}`,
+				"password": "test123",
+				"cors":     invalid,
+			})
+			w := httptest.NewRecorder()
+			handler.CreateJSON(w, httptest.NewRequest("POST", "/api/json", bytes.NewReader(body)))
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("%s: expected status %d, got %d", name, http.StatusBadRequest, w.Code)
+			}
+		}
+	})
+
+	t.Run("UpdateRemovesOverride", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]interface{}{"password": "test123", "cors": map[string]interface{}{}})
+		w := httptest.NewRecorder()
+		handler.UpdateJSON(w, httptest.NewRequest("PUT", "/api/json/"+id, bytes.NewReader(body)))
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		req := httptest.NewRequest("GET", "/api/json/"+id+"/content", nil)
+		if policy := lookup(req); policy != nil {
+			t.Errorf("Expected the override to be removed, got %+v", policy)
+		}
+	})
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"mockj-go/internal/events"
+	"mockj-go/internal/models"
+)
+
+// watchHeartbeat is how often idle watch streams send a comment to keep the connection open
+const watchHeartbeat = 15 * time.Second
+
+// publishMock announces a change to a mock. Subscribers get a copy without the password hash.
+func (h *JSONHandler) publishMock(eventType string, jsonModel *models.JSON) {
+	mock := *jsonModel
+	mock.Password = ""
+	h.events.Publish(eventType, mock.ID, &mock)
+}
+
+// WatchJSON handles GET /api/json/{id}/watch - streams the mock's change events as
+// Server-Sent Events until it is deleted or expires
+func (h *StreamHandler) WatchJSON(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromPath(r.URL.Path)
+	if id == "" {
+		h.writeError(w, http.StatusBadRequest, "invalid_id", "ID is required")
+		return
+	}
+
+	after, ok := h.lastEventID(w, r)
+	if !ok {
+		return
+	}
+
+	// Subscribing first means no change between the lookup and the stream is missed
+	sub := h.events.Subscribe(after, func(event events.Event) bool { return event.MockID == id })
+
+	// A resuming client may have missed the deletion it is about to be replayed
+	if after == 0 {
+		if _, err := h.db.GetJSON(r.Context(), id); err != nil {
+			sub.Close()
+			if err.Error() == "json not found or expired" {
+				h.writeError(w, http.StatusNotFound, "not_found", "JSON not found or expired")
+			} else {
+				h.writeError(w, http.StatusInternalServerError, "database_error", "Failed to retrieve JSON")
+			}
+			return
+		}
+	}
+
+	h.watch(w, r, sub, true)
+}
+
+// StreamEvents handles GET /api/events - streams the events of every mock as Server-Sent
+// Events, optionally only the ?types= given as a comma-separated list
+func (h *StreamHandler) StreamEvents(w http.ResponseWriter, r *http.Request) {
+	after, ok := h.lastEventID(w, r)
+	if !ok {
+		return
+	}
+
+	var filter func(events.Event) bool
+	if types := r.URL.Query().Get("types"); types != "" {
+		wanted := map[string]bool{}
+		for _, eventType := range strings.Split(types, ",") {
+			wanted[strings.TrimSpace(eventType)] = true
+		}
+		filter = func(event events.Event) bool { return wanted[event.Type] }
+	}
+
+	h.watch(w, r, h.events.Subscribe(after, filter), false)
+}
+
+// lastEventID reads the Last-Event-ID header of a reconnecting client, 0 when there is none
+func (h *StreamHandler) lastEventID(w http.ResponseWriter, r *http.Request) (uint64, bool) {
+	header := r.Header.Get("Last-Event-ID")
+	if header == "" {
+		return 0, true
+	}
+	after, err := strconv.ParseUint(header, 10, 64)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid_stream", "Last-Event-ID must be an event ID of this stream")
+		return 0, false
+	}
+	return after, true
+}
+
+// watch sends the subscription's events until the client disconnects. With untilGone the
+// stream also ends after a deleted or expired event.
+func (h *StreamHandler) watch(w http.ResponseWriter, r *http.Request, sub *events.Subscription, untilGone bool) {
+	defer sub.Close()
+
+	stream := newEventStream(w, h.cfg.WriteTimeout)
+	if err := stream.start(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(watchHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case event, ok := <-sub.C:
+			if !ok {
+				// The client fell behind; it reconnects and resumes with Last-Event-ID
+				return
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			if err := stream.send(strconv.FormatUint(event.ID, 10), event.Type, string(data)); err != nil {
+				return
+			}
+			if untilGone && (event.Type == events.Deleted || event.Type == events.Expired) {
+				return
+			}
+		case <-heartbeat.C:
+			if err := stream.comment("heartbeat"); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
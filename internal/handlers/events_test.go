@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"mockj-go/internal/config"
+	"mockj-go/internal/database"
+	"mockj-go/internal/events"
+)
+
+func TestChangeNotifications(t *testing.T) {
+	db, err := database.NewDatabase(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	bus := events.NewBus()
+	handler := NewStreamHandler(NewJSONHandler(db, bus, nil), config.ServerConfig{})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/json/{id}/watch", handler.WatchJSON)
+	mux.HandleFunc("GET /api/events", handler.StreamEvents)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	// open returns the stream once its headers arrive, which is after it has subscribed
+	open := func(t *testing.T, path string) *http.Response {
+		t.Helper()
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("Failed to open stream: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d", http.StatusOK, resp.StatusCode)
+		}
+		return resp
+	}
+
+	send := func(t *testing.T, method, id string, request interface{}) {
+		t.Helper()
+		body, _ := json.Marshal(request)
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, "/api/json/"+id, bytes.NewReader(body))
+		if method == "PUT" {
+			handler.UpdateJSON(w, req)
+		} else {
+			handler.DeleteJSON(w, req)
+		}
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s failed: %d %s", method, w.Code, w.Body.String())
+		}
+	}
+
+	t.Run("WatchUntilDeleted", func(t *testing.T) {
+		id := createTestJSON(t, handler.JSONHandler, map[string]interface{}{"json": `{"v": 1}`, "password": "test123"})
+
+		resp := open(t, "/api/json/"+id+"/watch")
+		defer resp.Body.Close()
+
+		createTestJSON(t, handler.JSONHandler, map[string]interface{}{"json": `{"other": true}`, "password": "test123"})
+		send(t, "PUT", id, map[string]interface{}{"json": `{"v": 2}`, "password": "test123"})
+		send(t, "DELETE", id, PasswordRequest{Password: "test123"})
+
+		// The stream ends by itself after the deletion
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("Failed to read stream: %v", err)
+		}
+		stream := string(body)
+
+		if !strings.Contains(stream, "event: updated\n") || !strings.Contains(stream, `{\"v\": 2}`) {
+			t.Errorf("Expected the update with the new content, got %q", stream)
+		}
+		if !strings.Contains(stream, "event: deleted\n") || strings.Index(stream, "event: deleted") < strings.Index(stream, "event: updated") {
+			t.Errorf("Expected the deletion after the update, got %q", stream)
+		}
+		if strings.Contains(stream, "other") || strings.Contains(stream, "password") {
+			t.Errorf("Expected only this mock's events without its password, got %q", stream)
+		}
+	})
+
+	t.Run("GlobalStreamWithTypesAndResume", func(t *testing.T) {
+		resp := open(t, "/api/events?types=created,expired")
+		id := createTestJSON(t, handler.JSONHandler, map[string]interface{}{"json": `{}`, "password": "test123"})
+		send(t, "PUT", id, map[string]interface{}{"json": `{"v": 2}`, "password": "test123"})
+		expired := bus.Publish(events.Expired, id, nil)
+
+		var received []events.Event
+		buf := make([]byte, 4096)
+		var stream strings.Builder
+		for len(received) < 2 {
+			n, err := resp.Body.Read(buf)
+			if err != nil {
+				t.Fatalf("Stream ended early after %q: %v", stream.String(), err)
+			}
+			stream.Write(buf[:n])
+			received = received[:0]
+			for _, line := range strings.Split(stream.String(), "\n") {
+				var event events.Event
+				if data, ok := strings.CutPrefix(line, "data: "); ok && json.Unmarshal([]byte(data), &event) == nil {
+					received = append(received, event)
+				}
+			}
+		}
+		resp.Body.Close()
+
+		if received[0].Type != events.Created || received[0].MockID != id || received[1].ID != expired.ID {
+			t.Errorf("Expected the creation and expiry of %s, got %+v", id, received)
+		}
+
+		// Resuming replays what followed the last event received
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		req := httptest.NewRequest("GET", "/api/events", nil).WithContext(ctx)
+		req.Header.Set("Last-Event-ID", strconv.FormatUint(received[0].ID, 10))
+		w := httptest.NewRecorder()
+		handler.StreamEvents(w, req)
+		if !strings.Contains(w.Body.String(), "event: updated\n") || !strings.Contains(w.Body.String(), "event: expired\n") {
+			t.Errorf("Expected the update and expiry to be replayed, got %q", w.Body.String())
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.WatchJSON(w, httptest.NewRequest("GET", "/api/json/missing/watch", nil))
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status %d, got %d", http.StatusNotFound, w.Code)
+		}
+
+		req := httptest.NewRequest("GET", "/api/events", nil)
+		req.Header.Set("Last-Event-ID", "x")
+		w = httptest.NewRecorder()
+		handler.StreamEvents(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+		}
+	})
+}
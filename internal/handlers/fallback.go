@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"time"
+
+	"mockj-go/internal/config"
+)
+
+// FallbackHandler forwards requests that no mock handles to an upstream,
+// so only part of an API needs to be mocked
+type FallbackHandler struct {
+	cfg    config.FallbackConfig
+	target *url.URL
+	proxy  *httputil.ReverseProxy
+}
+
+func NewFallbackHandler(cfg config.FallbackConfig) (*FallbackHandler, error) {
+	target, err := url.Parse(cfg.Target)
+	if err != nil || target.Scheme == "" || target.Host == "" {
+		return nil, fmt.Errorf("invalid fallback target %q", cfg.Target)
+	}
+
+	h := &FallbackHandler{cfg: cfg, target: target}
+
+	h.proxy = &httputil.ReverseProxy{
+		Rewrite: h.rewrite,
+		Transport: &http.Transport{
+			Proxy:                 http.ProxyFromEnvironment,
+			DialContext:           (&net.Dialer{Timeout: 10 * time.Second}).DialContext,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ResponseHeaderTimeout: cfg.Timeout,
+			IdleConnTimeout:       90 * time.Second,
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			slog.ErrorContext(r.Context(), "Fallback proxy failed", "method", r.Method, "path", r.URL.Path, "target", target.String(), "error", err)
+			http.Error(w, "Bad Gateway", http.StatusBadGateway)
+		},
+	}
+
+	return h, nil
+}
+
+// rewrite points the outgoing request at the upstream and applies the header rules
+func (h *FallbackHandler) rewrite(pr *httputil.ProxyRequest) {
+	pr.SetURL(h.target)
+	pr.SetXForwarded()
+
+	if h.cfg.PreserveHost {
+		pr.Out.Host = pr.In.Host
+	}
+
+	for _, name := range h.cfg.RemoveHeaders {
+		pr.Out.Header.Del(name)
+	}
+	for name, value := range h.cfg.SetHeaders {
+		pr.Out.Header.Set(name, value)
+	}
+}
+
+// ServeHTTP proxies the request to the upstream
+func (h *FallbackHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	slog.InfoContext(r.Context(), "Proxied to fallback", "method", r.Method, "path", r.URL.Path, "target", h.target.String())
+	h.proxy.ServeHTTP(w, r)
+}
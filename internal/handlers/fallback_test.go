@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"mockj-go/internal/config"
+)
+
+func TestFallbackProxy(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Seen-Path", r.URL.Path)
+		w.Header().Set("X-Seen-Env", r.Header.Get("X-Env"))
+		w.Header().Set("X-Seen-Cookie", r.Header.Get("Cookie"))
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer upstream.Close()
+
+	handler, err := NewFallbackHandler(config.FallbackConfig{
+		Target:        upstream.URL,
+		SetHeaders:    map[string]string{"X-Env": "staging"},
+		RemoveHeaders: []string{"Cookie"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create fallback handler: %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/orders/7?expand=true", nil)
+	req.Header.Set("Cookie", "session=secret")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusTeapot {
+		t.Errorf("Expected upstream status %d, got %d", http.StatusTeapot, w.Code)
+	}
+	if w.Header().Get("X-Seen-Path") != "/orders/7" {
+		t.Errorf("Expected path to be forwarded, got %q", w.Header().Get("X-Seen-Path"))
+	}
+	if w.Header().Get("X-Seen-Env") != "staging" {
+		t.Errorf("Expected header to be set on the forwarded request")
+	}
+	if w.Header().Get("X-Seen-Cookie") != "" {
+		t.Errorf("Expected header to be removed from the forwarded request")
+	}
+
+	if _, err := NewFallbackHandler(config.FallbackConfig{Target: "not a url"}); err == nil {
+		t.Errorf("Expected invalid target to be rejected")
+	}
+}
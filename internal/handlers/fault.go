@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"mockj-go/internal/models"
+)
+
+// serveFault misbehaves according to the mock's fault configuration
+func (h *JSONHandler) serveFault(w http.ResponseWriter, r *http.Request, jsonModel *models.JSON) {
+	content, err := jsonModel.Body()
+	if err != nil {
+		h.writeError(w, http.StatusInternalServerError, "invalid_content", "Stored content cannot be decoded")
+		return
+	}
+	status := jsonModel.ResponseStatus()
+
+	switch jsonModel.Fault.Type {
+	case models.FaultConnectionReset:
+		conn, ok := hijack(w, r)
+		if !ok {
+			return
+		}
+		// A zero linger makes Close send RST instead of FIN
+		if tcpConn, ok := conn.(*net.TCPConn); ok {
+			_ = tcpConn.SetLinger(0)
+		}
+		_ = conn.Close()
+
+	case models.FaultEmptyResponse:
+		conn, ok := hijack(w, r)
+		if !ok {
+			return
+		}
+		_ = conn.Close()
+
+	case models.FaultTruncatedBody:
+		conn, ok := hijack(w, r)
+		if !ok {
+			return
+		}
+		// Announce the full length but close after sending half of the body
+		header := http.Header{}
+		setMockHeaders(header, jsonModel)
+		header.Set("Content-Length", fmt.Sprint(len(content)))
+		header.Set("Connection", "close")
+		var response bytes.Buffer
+		fmt.Fprintf(&response, "HTTP/1.1 %d %s\r\n", status, http.StatusText(status))
+		_ = header.Write(&response)
+		response.WriteString("\r\n")
+		response.Write(content[:len(content)/2])
+		_, _ = conn.Write(response.Bytes())
+		_ = conn.Close()
+
+	case models.FaultMalformedJSON:
+		setMockHeaders(w.Header(), jsonModel)
+		w.WriteHeader(status)
+		_, _ = w.Write(malformJSON(content))
+
+	case models.FaultSlowBody:
+		setMockHeaders(w.Header(), jsonModel)
+		serveSlowly(w, r, content, status, jsonModel.Fault.Rate())
+	}
+}
+
+// setMockHeaders sets the headers a mock is served with: its media type, unless its
+// configured headers override it
+func setMockHeaders(header http.Header, jsonModel *models.JSON) {
+	header.Set("Content-Type", jsonModel.MediaType())
+	for name, value := range jsonModel.Headers {
+		header.Set(name, value)
+	}
+}
+
+// hijack takes over the underlying connection, writing a 500 if that is not supported
+func hijack(w http.ResponseWriter, r *http.Request) (net.Conn, bool) {
+	conn, _, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Failed to hijack connection for fault injection", "error", err)
+		http.Error(w, "Fault injection not supported on this connection", http.StatusInternalServerError)
+		return nil, false
+	}
+	return conn, true
+}
+
+// malformJSON cuts the document in half and appends a dangling ",}" so it never parses
+func malformJSON(content []byte) []byte {
+	malformed := make([]byte, 0, len(content)/2+2)
+	malformed = append(malformed, content[:len(content)/2]...)
+	return append(malformed, ',', '}')
+}
+
+// serveSlowly trickles the body out in ten chunks per second at the given rate, after the
+// headers already set
+func serveSlowly(w http.ResponseWriter, r *http.Request, content []byte, status, bytesPerSecond int) {
+	rc := http.NewResponseController(w)
+	// The server write timeout would otherwise cut long trickles short
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	chunk := bytesPerSecond / 10
+	if chunk < 1 {
+		chunk = 1
+	}
+	interval := time.Second * time.Duration(chunk) / time.Duration(bytesPerSecond)
+
+	w.Header().Set("Content-Length", fmt.Sprint(len(content)))
+	w.WriteHeader(status)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for len(content) > 0 {
+		n := min(chunk, len(content))
+		if _, err := w.Write(content[:n]); err != nil {
+			return
+		}
+		_ = rc.Flush()
+		content = content[n:]
+
+		if len(content) == 0 {
+			return
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
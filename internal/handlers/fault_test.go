@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"mockj-go/internal/database"
+	"mockj-go/internal/events"
+)
+
+func TestFaultInjection(t *testing.T) {
+	db, err := database.NewDatabase(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	handler := NewJSONHandler(db, events.NewBus(), nil)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/json/{id}/content", handler.GetJSONContent)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	content := `{"name": "John", "age": 30}`
+
+	createWithFault := func(t *testing.T, fault map[string]interface{}) string {
+		return createTestJSON(t, handler, map[string]interface{}{
+			"json":     content,
+			"password": "test123",
+			"fault":    fault,
+		})
+	}
+
+	t.Run("ConnectionReset", func(t *testing.T) {
+		id := createWithFault(t, map[string]interface{}{"type": "connection_reset"})
+
+		if _, err := http.Get(server.URL + "/api/json/" + id + "/content"); err == nil {
+			t.Errorf("Expected request to fail after connection reset")
+		}
+	})
+
+	t.Run("EmptyResponse", func(t *testing.T) {
+		id := createWithFault(t, map[string]interface{}{"type": "empty_response"})
+
+		if _, err := http.Get(server.URL + "/api/json/" + id + "/content"); err == nil {
+			t.Errorf("Expected request to fail on empty reply")
+		}
+	})
+
+	t.Run("TruncatedBody", func(t *testing.T) {
+		id := createWithFault(t, map[string]interface{}{"type": "truncated_body"})
+
+		resp, err := http.Get(server.URL + "/api/json/" + id + "/content")
+		if err != nil {
+			t.Fatalf("Expected headers to arrive, got %v", err)
+		}
+		defer resp.Body.Close()
+
+		if _, err := io.ReadAll(resp.Body); err != io.ErrUnexpectedEOF {
+			t.Errorf("Expected unexpected EOF reading truncated body, got %v", err)
+		}
+	})
+
+	t.Run("TruncatedBodyKeepsStatusAndHeaders", func(t *testing.T) {
+		id := createTestJSON(t, handler, map[string]interface{}{
+			"json":     content,
+			"password": "test123",
+			"status":   201,
+			"headers":  map[string]string{"X-Mock": "yes", "Content-Type": "application/vnd.api+json"},
+			"fault":    map[string]interface{}{"type": "truncated_body"},
+		})
+
+		resp, err := http.Get(server.URL + "/api/json/" + id + "/content")
+		if err != nil {
+			t.Fatalf("Expected headers to arrive, got %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusCreated || resp.Header.Get("X-Mock") != "yes" || resp.Header.Get("Content-Type") != "application/vnd.api+json" {
+			t.Errorf("Expected the mock's status and headers, got %d %v", resp.StatusCode, resp.Header)
+		}
+		if resp.ContentLength != int64(len(content)) {
+			t.Errorf("Expected the full length to be announced, got %d", resp.ContentLength)
+		}
+	})
+
+	t.Run("MalformedJSON", func(t *testing.T) {
+		id := createWithFault(t, map[string]interface{}{"type": "malformed_json"})
+
+		resp, err := http.Get(server.URL + "/api/json/" + id + "/content")
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		defer resp.Body.Close()
+
+		body, _ := io.ReadAll(resp.Body)
+		if json.Valid(body) {
+			t.Errorf("Expected malformed JSON, got %s", body)
+		}
+	})
+
+	t.Run("SlowBody", func(t *testing.T) {
+		id := createWithFault(t, map[string]interface{}{"type": "slow_body", "bytesPerSecond": 100})
+
+		start := time.Now()
+		resp, err := http.Get(server.URL + "/api/json/" + id + "/content")
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		defer resp.Body.Close()
+
+		body, _ := io.ReadAll(resp.Body)
+		if string(body) != content {
+			t.Errorf("Expected full content, got %s", body)
+		}
+		// 27 bytes at 100 B/s is sent in three 10-byte chunks, two intervals apart
+		if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+			t.Errorf("Expected body to trickle out, took only %v", elapsed)
+		}
+	})
+
+	t.Run("ZeroProbability", func(t *testing.T) {
+		id := createWithFault(t, map[string]interface{}{"type": "connection_reset", "probability": 0})
+
+		resp, err := http.Get(server.URL + "/api/json/" + id + "/content")
+		if err != nil {
+			t.Fatalf("Expected a fault with probability 0 never to trigger, got %v", err)
+		}
+		defer resp.Body.Close()
+
+		body, _ := io.ReadAll(resp.Body)
+		if string(body) != content {
+			t.Errorf("Expected full content, got %s", body)
+		}
+	})
+
+	t.Run("InvalidFault", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]interface{}{
+			"json":     content,
+			"password": "test123",
+			"fault":    map[string]interface{}{"type": "explode"},
+		})
+		req := httptest.NewRequest("POST", "/api/json", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		handler.CreateJSON(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+		}
+	})
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"mockj-go/internal/events"
+	"mockj-go/internal/models"
+	"mockj-go/internal/schema"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// maxGenerateCount caps how many items a single generate call produces
+const maxGenerateCount = 1000
+
+// GenerateRequest represents the request body for generating data from a JSON Schema
+type GenerateRequest struct {
+	Schema   map[string]interface{} `json:"schema"`
+	Count    *int                   `json:"count,omitempty"` // Omitted returns a single value, otherwise an array
+	Seed     *int64                 `json:"seed,omitempty"`  // Omitted picks a random seed, reported in the result
+	Store    bool                   `json:"store,omitempty"` // Store the result as a new mock
+	Password string                 `json:"password,omitempty"`
+	Expires  *time.Time             `json:"expires,omitempty"`
+}
+
+// GenerateResult holds generated data and how to reproduce it
+type GenerateResult struct {
+	Seed int64       `json:"seed"`
+	Data interface{} `json:"data"`
+	ID   string      `json:"id,omitempty"` // ID of the stored mock
+}
+
+// Generate handles POST /api/generate
+func (h *JSONHandler) Generate(w http.ResponseWriter, r *http.Request) {
+	var req GenerateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid_request", "Invalid JSON body")
+		return
+	}
+
+	if req.Schema == nil {
+		h.writeError(w, http.StatusBadRequest, "invalid_schema", "Schema is required")
+		return
+	}
+
+	if err := schema.CheckSizes(req.Schema); err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid_schema", err.Error())
+		return
+	}
+
+	if req.Count != nil && (*req.Count < 1 || *req.Count > maxGenerateCount) {
+		h.writeError(w, http.StatusBadRequest, "invalid_count", "Count must be between 1 and 1000")
+		return
+	}
+
+	if req.Store && req.Password == "" {
+		h.writeError(w, http.StatusBadRequest, "invalid_password", "Password is required to store the result")
+		return
+	}
+
+	if req.Expires != nil && (req.Expires.Before(time.Now()) || req.Expires.After(time.Now().AddDate(1, 0, 0))) {
+		h.writeError(w, http.StatusBadRequest, "invalid_expires", "Expiration time must be in the future and less than 1 year from now")
+		return
+	}
+
+	result := GenerateResult{Seed: time.Now().UnixNano()}
+	if req.Seed != nil {
+		result.Seed = *req.Seed
+	}
+
+	generator := schema.NewGenerator(result.Seed, schema.PointerResolver(req.Schema))
+	if req.Count == nil {
+		result.Data = generator.Generate(req.Schema)
+	} else {
+		items := make([]interface{}, *req.Count)
+		for i := range items {
+			items[i] = generator.Generate(req.Schema)
+		}
+		result.Data = items
+	}
+
+	if !req.Store {
+		h.writeJSON(w, http.StatusOK, SuccessResponse{
+			Data: result,
+		})
+		return
+	}
+
+	content, err := json.Marshal(result.Data)
+	if err != nil {
+		h.writeError(w, http.StatusInternalServerError, "generate_error", "Failed to encode generated data")
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		h.writeError(w, http.StatusInternalServerError, "hash_error", "Failed to hash password")
+		return
+	}
+
+	jsonModel := models.NewJSON(string(content), string(hashedPassword))
+	if req.Expires != nil {
+		jsonModel.Expires = *req.Expires
+	}
+
+	if err := h.db.CreateJSON(r.Context(), jsonModel); err != nil {
+		h.writeError(w, http.StatusInternalServerError, "database_error", "Failed to create JSON")
+		return
+	}
+	h.publishMock(events.Created, jsonModel)
+
+	result.ID = jsonModel.ID
+	h.writeJSON(w, http.StatusCreated, SuccessResponse{
+		Data:    result,
+		Message: "JSON generated and stored successfully",
+	})
+}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"testing"
+	"time"
+
+	"mockj-go/internal/database"
+	"mockj-go/internal/events"
+
+	"github.com/google/uuid"
+)
+
+func TestGenerate(t *testing.T) {
+	db, err := database.NewDatabase(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	handler := NewJSONHandler(db, events.NewBus(), nil)
+
+	userSchema := map[string]interface{}{
+		"type":     "object",
+		"required": []interface{}{"id", "email", "createdAt", "name", "age", "role"},
+		"properties": map[string]interface{}{
+			"id":        map[string]interface{}{"type": "string", "format": "uuid"},
+			"email":     map[string]interface{}{"type": "string", "format": "email"},
+			"createdAt": map[string]interface{}{"type": "string", "format": "date-time"},
+			"name":      map[string]interface{}{"type": "string", "x-faker": "name.fullName"},
+			"age":       map[string]interface{}{"type": "integer", "minimum": 18, "maximum": 99},
+			"role":      map[string]interface{}{"$ref": "#/$defs/role"},
+		},
+		"$defs": map[string]interface{}{
+			"role": map[string]interface{}{"enum": []interface{}{"admin", "member"}},
+		},
+	}
+
+	generate := func(t *testing.T, reqBody map[string]interface{}) (int, GenerateResult) {
+		body, _ := json.Marshal(reqBody)
+		req := httptest.NewRequest("POST", "/api/generate", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		handler.Generate(w, req)
+
+		var response struct {
+			Data GenerateResult `json:"data"`
+		}
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		return w.Code, response.Data
+	}
+
+	t.Run("Deterministic", func(t *testing.T) {
+		reqBody := map[string]interface{}{"schema": userSchema, "count": 3, "seed": 42}
+		_, first := generate(t, reqBody)
+		_, second := generate(t, reqBody)
+
+		firstJSON, _ := json.Marshal(first.Data)
+		secondJSON, _ := json.Marshal(second.Data)
+		if string(firstJSON) != string(secondJSON) {
+			t.Errorf("Expected the same seed to generate the same data:\n%s\n%s", firstJSON, secondJSON)
+		}
+
+		_, other := generate(t, map[string]interface{}{"schema": userSchema, "count": 3, "seed": 7})
+		otherJSON, _ := json.Marshal(other.Data)
+		if string(firstJSON) == string(otherJSON) {
+			t.Errorf("Expected a different seed to generate different data")
+		}
+	})
+
+	t.Run("Formats", func(t *testing.T) {
+		code, result := generate(t, map[string]interface{}{"schema": userSchema, "count": 20, "seed": 1})
+		if code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d", http.StatusOK, code)
+		}
+
+		for _, item := range result.Data.([]interface{}) {
+			user := item.(map[string]interface{})
+			if _, err := uuid.Parse(user["id"].(string)); err != nil {
+				t.Errorf("Invalid uuid %v", user["id"])
+			}
+			if _, err := mail.ParseAddress(user["email"].(string)); err != nil {
+				t.Errorf("Invalid email %v", user["email"])
+			}
+			if _, err := time.Parse(time.RFC3339, user["createdAt"].(string)); err != nil {
+				t.Errorf("Invalid date-time %v", user["createdAt"])
+			}
+			if age := user["age"].(float64); age < 18 || age > 99 {
+				t.Errorf("Age %v out of bounds", age)
+			}
+			if role := user["role"]; role != "admin" && role != "member" {
+				t.Errorf("Role %v not in enum", role)
+			}
+		}
+	})
+
+	t.Run("Store", func(t *testing.T) {
+		code, result := generate(t, map[string]interface{}{
+			"schema":   userSchema,
+			"seed":     42,
+			"store":    true,
+			"password": "test123",
+		})
+		if code != http.StatusCreated || result.ID == "" {
+			t.Fatalf("Expected stored mock, got %d %+v", code, result)
+		}
+
+		stored, err := db.GetJSON(t.Context(), result.ID)
+		if err != nil {
+			t.Fatalf("Expected stored mock to exist: %v", err)
+		}
+		generated, _ := json.Marshal(result.Data)
+		if stored.Content != string(generated) {
+			t.Errorf("Expected stored content %s, got %s", generated, stored.Content)
+		}
+	})
+
+	t.Run("StoreWithoutPassword", func(t *testing.T) {
+		code, _ := generate(t, map[string]interface{}{"schema": userSchema, "store": true})
+		if code != http.StatusBadRequest {
+			t.Errorf("Expected status %d, got %d", http.StatusBadRequest, code)
+		}
+	})
+
+	t.Run("NegativeSizes", func(t *testing.T) {
+		for _, s := range []map[string]interface{}{
+			{"type": "array", "minItems": -1},
+			{"type": "array", "maxItems": -5},
+			{"type": "object", "properties": map[string]interface{}{"name": map[string]interface{}{"type": "string", "minLength": -1}}},
+		} {
+			if code, _ := generate(t, map[string]interface{}{"schema": s}); code != http.StatusBadRequest {
+				t.Errorf("%v: expected status %d, got %d", s, http.StatusBadRequest, code)
+			}
+		}
+	})
+
+	t.Run("HugeSizesAreClamped", func(t *testing.T) {
+		code, result := generate(t, map[string]interface{}{"schema": map[string]interface{}{
+			"type":     "array",
+			"minItems": 1e9,
+			"items":    map[string]interface{}{"type": "integer"},
+		}})
+		if items, _ := result.Data.([]interface{}); code != http.StatusOK || len(items) != 1000 {
+			t.Errorf("Expected 1000 items, got %d with status %d", len(items), code)
+		}
+
+		code, result = generate(t, map[string]interface{}{"schema": map[string]interface{}{"type": "string", "minLength": 1e9}})
+		if text, _ := result.Data.(string); code != http.StatusOK || len(text) != 10000 {
+			t.Errorf("Expected 10000 characters, got %d with status %d", len(text), code)
+		}
+
+		// Nested arrays stop growing once the generator's output budget is spent
+		nested := map[string]interface{}{"type": "integer"}
+		for i := 0; i < 5; i++ {
+			nested = map[string]interface{}{"type": "array", "minItems": 1000, "items": nested}
+		}
+		if code, _ := generate(t, map[string]interface{}{"schema": nested}); code != http.StatusOK {
+			t.Errorf("Expected status %d, got %d", http.StatusOK, code)
+		}
+	})
+
+	t.Run("IntegerBoundsAtInt64Limits", func(t *testing.T) {
+		for _, bounds := range []map[string]interface{}{
+			{"minimum": -9.3e18, "maximum": 9.3e18},
+			{"minimum": 9.2e18, "maximum": 9.3e18},
+			{"minimum": -9.3e18, "maximum": -9.2e18},
+		} {
+			s := map[string]interface{}{"type": "integer"}
+			for key, value := range bounds {
+				s[key] = value
+			}
+			code, result := generate(t, map[string]interface{}{"schema": s, "count": 20})
+			if code != http.StatusOK {
+				t.Fatalf("%v: expected status %d, got %d", bounds, http.StatusOK, code)
+			}
+			for _, value := range result.Data.([]interface{}) {
+				if n := value.(float64); n < bounds["minimum"].(float64) || n > bounds["maximum"].(float64) {
+					t.Errorf("%v: %v out of bounds", bounds, n)
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"mockj-go/internal/graphql"
+	"mockj-go/internal/models"
+)
+
+// graphqlResponseType is the media type of the GraphQL over HTTP specification. Clients
+// that accept it get 400 for requests that fail before execution instead of 200.
+const graphqlResponseType = "application/graphql-response+json"
+
+// GraphQLResponse describes the body of GraphQL responses for the OpenAPI document
+type GraphQLResponse struct {
+	Errors []*graphql.Error `json:"errors,omitempty"`
+	Data   interface{}      `json:"data,omitempty"`
+}
+
+// validateGraphQL checks that a mock with a GraphQL schema has a valid schema and JSON
+// object content holding the data its queries are answered from
+func validateGraphQL(jsonModel *models.JSON) error {
+	if jsonModel.GraphQLSchema == "" {
+		return nil
+	}
+	if _, err := graphql.ParseSchema(jsonModel.GraphQLSchema); err != nil {
+		return err
+	}
+	if jsonModel.ContentType != "" && jsonModel.ContentType != models.ContentTypeJSON {
+		return fmt.Errorf("graphql mocks must have json content")
+	}
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(jsonModel.Content), &data); err != nil || data == nil {
+		return fmt.Errorf("graphql data must be a JSON object keyed by root type, such as {\"Query\": {...}}")
+	}
+	return nil
+}
+
+// ServeGraphQL handles GET and POST /api/json/{id}/graphql - answers GraphQL queries from
+// the mock's content, generating values for fields the content leaves out.
+// Mutations are only accepted over POST.
+func (h *JSONHandler) ServeGraphQL(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromPath(r.URL.Path)
+	if id == "" {
+		h.writeError(w, http.StatusBadRequest, "invalid_id", "ID is required")
+		return
+	}
+
+	jsonModel, err := h.db.GetJSON(r.Context(), id)
+	if err != nil {
+		if err.Error() == "json not found or expired" {
+			h.writeError(w, http.StatusNotFound, "not_found", "JSON not found or expired")
+		} else {
+			h.writeError(w, http.StatusInternalServerError, "database_error", "Failed to retrieve JSON")
+		}
+		return
+	}
+
+	w, record := h.startJournal(w, r, id)
+	defer record()
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxContentSize))
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid_request", "Failed to read body")
+		return
+	}
+
+	if jsonModel.GraphQLSchema == "" {
+		h.writeError(w, http.StatusNotFound, "no_schema", "Mock has no GraphQL schema")
+		return
+	}
+
+	req, err := parseGraphQLRequest(r, body)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	if r.Method == http.MethodGet && graphql.OperationType(req) == graphql.Mutation {
+		w.Header().Set("Allow", "POST")
+		h.writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Mutations must be sent with POST")
+		return
+	}
+
+	schema, err := graphql.ParseSchema(jsonModel.GraphQLSchema)
+	if err != nil {
+		h.writeError(w, http.StatusInternalServerError, "invalid_schema", "Stored schema cannot be parsed")
+		return
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(jsonModel.Content))
+	decoder.UseNumber()
+	var data interface{}
+	if err := decoder.Decode(&data); err != nil {
+		h.writeError(w, http.StatusInternalServerError, "invalid_content", "Stored content cannot be decoded")
+		return
+	}
+
+	response := schema.Execute(req, data)
+
+	status := http.StatusOK
+	mediaType := "application/json"
+	if acceptsGraphQLResponse(r) {
+		mediaType = graphqlResponseType
+		if !response.HasData {
+			status = http.StatusBadRequest
+		}
+	}
+
+	w.Header().Set("Content-Type", mediaType)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// parseGraphQLRequest reads a request from the query string of GET requests, or from a
+// JSON body or a raw application/graphql query of POST requests
+func parseGraphQLRequest(r *http.Request, body []byte) (graphql.Request, error) {
+	query := r.URL.Query()
+	req := graphql.Request{
+		Query:         query.Get("query"),
+		OperationName: query.Get("operationName"),
+	}
+
+	if r.Method == http.MethodPost {
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if mediaType == "application/graphql" {
+			req.Query = string(body)
+		} else {
+			decoder := json.NewDecoder(bytes.NewReader(body))
+			decoder.UseNumber()
+			if err := decoder.Decode(&req); err != nil {
+				return req, errors.New("Invalid JSON body")
+			}
+			if req.Query == "" {
+				return req, errors.New("Query is required")
+			}
+			return req, nil
+		}
+	}
+
+	if req.Query == "" {
+		return req, errors.New("Query is required")
+	}
+	if variables := query.Get("variables"); variables != "" {
+		decoder := json.NewDecoder(strings.NewReader(variables))
+		decoder.UseNumber()
+		if err := decoder.Decode(&req.Variables); err != nil {
+			return req, errors.New("Variables must be a JSON object")
+		}
+	}
+	return req, nil
+}
+
+// acceptsGraphQLResponse reports whether the client asked for the GraphQL response media type
+func acceptsGraphQLResponse(r *http.Request) bool {
+	for _, accepted := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err == nil && mediaType == graphqlResponseType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"mockj-go/internal/database"
+	"mockj-go/internal/events"
+)
+
+const testGraphQLSchema = `
+type User { id: ID! name: String! email: String! }
+type Query { user(id: ID!): User users: [User!]! }
+type Mutation { createUser(name: String!): User! }
+`
+
+func TestGraphQL(t *testing.T) {
+	db, err := database.NewDatabase(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	handler := NewJSONHandler(db, events.NewBus(), nil)
+
+	id := createTestJSON(t, handler, map[string]interface{}{
+		"json":          `{"Query": {"user": [{"id": "1", "name": "John"}, {"id": "2", "name": "Jane"}]}, "Mutation": {"createUser": {"id": "3", "name": "Ann"}}}`,
+		"graphqlSchema": testGraphQLSchema,
+		"password":      "test123",
+	})
+
+	serve := func(req *http.Request) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeGraphQL(w, req)
+		return w
+	}
+
+	post := func(body, contentType string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/json/"+id+"/graphql", strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		return serve(req)
+	}
+
+	t.Run("PostJSON", func(t *testing.T) {
+		w := post(`{"query": "query ($id: ID!) { user(id: $id) { name } }", "variables": {"id": 2}}`, "application/json")
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		if got := strings.TrimSpace(w.Body.String()); got != `{"data":{"user":{"name":"Jane"}}}` {
+			t.Errorf("Unexpected response %s", got)
+		}
+	})
+
+	t.Run("PostRawQuery", func(t *testing.T) {
+		w := post(`mutation { createUser(name: "Ann") { id } }`, "application/graphql")
+		if got := strings.TrimSpace(w.Body.String()); got != `{"data":{"createUser":{"id":"3"}}}` {
+			t.Errorf("Unexpected response %s", got)
+		}
+	})
+
+	t.Run("GetGeneratesMissingFields", func(t *testing.T) {
+		query := url.Values{"query": {`{ user(id: "1") { email } users { id } }`}}
+		w := serve(httptest.NewRequest("GET", "/api/json/"+id+"/graphql?"+query.Encode(), nil))
+
+		var response struct {
+			Data struct {
+				User  struct{ Email string }
+				Users []struct{ ID string }
+			}
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if response.Data.User.Email == "" || len(response.Data.Users) == 0 {
+			t.Errorf("Expected generated values, got %s", w.Body.String())
+		}
+	})
+
+	t.Run("GetRejectsMutations", func(t *testing.T) {
+		query := url.Values{"query": {`mutation { createUser(name: "x") { id } }`}}
+		w := serve(httptest.NewRequest("GET", "/api/json/"+id+"/graphql?"+query.Encode(), nil))
+		if w.Code != http.StatusMethodNotAllowed {
+			t.Errorf("Expected status %d, got %d", http.StatusMethodNotAllowed, w.Code)
+		}
+	})
+
+	t.Run("RequestErrors", func(t *testing.T) {
+		body := `{"query": "{ user { name } }"}`
+
+		w := post(body, "application/json")
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"code":"GRAPHQL_VALIDATION_FAILED"`) {
+			t.Errorf("Expected a validation error with status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		req := httptest.NewRequest("POST", "/api/json/"+id+"/graphql", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", graphqlResponseType)
+		w = serve(req)
+		if w.Code != http.StatusBadRequest || w.Header().Get("Content-Type") != graphqlResponseType {
+			t.Errorf("Expected status %d with %s, got %d with %s", http.StatusBadRequest, graphqlResponseType, w.Code, w.Header().Get("Content-Type"))
+		}
+	})
+
+	t.Run("MockWithoutSchema", func(t *testing.T) {
+		plain := createTestJSON(t, handler, map[string]interface{}{"json": `{"a": 1}`, "password": "test123"})
+		req := httptest.NewRequest("POST", "/api/json/"+plain+"/graphql", strings.NewReader(`{"query": "{ a }"}`))
+		if w := serve(req); w.Code != http.StatusNotFound {
+			t.Errorf("Expected status %d, got %d", http.StatusNotFound, w.Code)
+		}
+	})
+
+	t.Run("InvalidSchemaRejected", func(t *testing.T) {
+		tests := map[string]map[string]interface{}{
+			"Syntax":      {"json": `{}`, "graphqlSchema": `type Query {`, "password": "test123"},
+			"NotJSON":     {"json": "a: 1", "contentType": "yaml", "graphqlSchema": testGraphQLSchema, "password": "test123"},
+			"NotAnObject": {"json": `[1]`, "graphqlSchema": testGraphQLSchema, "password": "test123"},
+		}
+		for name, reqBody := range tests {
+			body, _ := json.Marshal(reqBody)
+			w := httptest.NewRecorder()
+			handler.CreateJSON(w, httptest.NewRequest("POST", "/api/json", bytes.NewReader(body)))
+			if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "invalid_schema") {
+				t.Errorf("%s: expected invalid_schema, got %d: %s", name, w.Code, w.Body.String())
+			}
+		}
+	})
+}
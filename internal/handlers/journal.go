@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"mockj-go/internal/capture"
+	"mockj-go/internal/models"
+)
+
+// maxJournalBody caps how much of a request body is kept in the journal
+const maxJournalBody = 64 << 10
+
+// defaultJournalLimit and maxJournalLimit bound the number of entries listed at once
+const (
+	defaultJournalLimit = 100
+	maxJournalLimit     = 1000
+)
+
+// redactedHeaders are credentials replaced in journaled requests, which anyone who knows
+// the mock's ID can list
+var redactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", mockPasswordHeader, transferPasswordHeader}
+
+// redactedValue replaces the values of redacted headers
+const redactedValue = "[REDACTED]"
+
+// journalWriter captures the response status of a journaled request
+type journalWriter struct {
+	*capture.Writer
+	body []byte // The request body read for the journal
+}
+
+// startJournal reads the start of the request body for the journal, leaving r.Body intact,
+// and wraps w to capture the status. The returned function records the request once it has
+// been served. Callers look the mock up first, so requests to unknown IDs are not journaled.
+func (h *JSONHandler) startJournal(w http.ResponseWriter, r *http.Request, mockID string) (*journalWriter, func()) {
+	body, _ := io.ReadAll(io.LimitReader(r.Body, maxJournalBody))
+	r.Body = readCloser{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+	jw := &journalWriter{Writer: capture.NewWriter(w), body: body}
+
+	return jw, func() {
+		headers := r.Header.Clone()
+		for _, name := range redactedHeaders {
+			if len(headers.Values(name)) > 0 {
+				headers.Set(name, redactedValue)
+			}
+		}
+
+		entry := &models.RequestLog{
+			MockID:    mockID,
+			Method:    r.Method,
+			Path:      r.URL.Path,
+			Query:     r.URL.RawQuery,
+			Headers:   headers,
+			Body:      string(body),
+			Status:    jw.Status,
+			Timestamp: time.Now(),
+		}
+		// Requests the client gave up on are journaled too
+		if err := h.db.RecordRequest(context.WithoutCancel(r.Context()), entry); err != nil {
+			slog.ErrorContext(r.Context(), "Failed to record request", "mock_id", mockID, "error", err)
+		}
+	}
+}
+
+// readCloser reads from a reader but closes the original body
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// ListRequests handles GET /api/json/{id}/requests
+func (h *JSONHandler) ListRequests(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromPath(r.URL.Path)
+	if id == "" {
+		h.writeError(w, http.StatusBadRequest, "invalid_id", "ID is required")
+		return
+	}
+
+	filter, err := parseRequestFilter(r)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid_filter", err.Error())
+		return
+	}
+	filter.MockID = id
+
+	entries, err := h.db.ListRequests(r.Context(), filter)
+	if err != nil {
+		h.writeError(w, http.StatusInternalServerError, "database_error", "Failed to list requests")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, SuccessResponse{
+		Data: entries,
+	})
+}
+
+// ClearRequests handles DELETE /api/json/{id}/requests
+func (h *JSONHandler) ClearRequests(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromPath(r.URL.Path)
+	if id == "" {
+		h.writeError(w, http.StatusBadRequest, "invalid_id", "ID is required")
+		return
+	}
+
+	var req PasswordRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid_request", "Invalid JSON body")
+		return
+	}
+
+	if _, ok := h.authorize(r.Context(), w, id, req.Password); !ok {
+		return
+	}
+
+	removed, err := h.db.ClearRequests(r.Context(), id)
+	if err != nil {
+		h.writeError(w, http.StatusInternalServerError, "database_error", "Failed to clear requests")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, SuccessResponse{
+		Data:    map[string]int64{"removed": removed},
+		Message: "Requests cleared successfully",
+	})
+}
+
+// parseRequestFilter reads journal filters from the query string
+func parseRequestFilter(r *http.Request) (models.RequestFilter, error) {
+	query := r.URL.Query()
+	filter := models.RequestFilter{
+		Method: query.Get("method"),
+		Limit:  defaultJournalLimit,
+	}
+
+	if value := query.Get("status"); value != "" {
+		status, err := strconv.Atoi(value)
+		if err != nil {
+			return filter, fmt.Errorf("invalid status parameter")
+		}
+		filter.Status = status
+	}
+
+	if value := query.Get("since"); value != "" {
+		since, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return filter, fmt.Errorf("invalid since parameter")
+		}
+		filter.Since = since
+	}
+
+	if value := query.Get("until"); value != "" {
+		until, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return filter, fmt.Errorf("invalid until parameter")
+		}
+		filter.Until = until
+	}
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			return filter, fmt.Errorf("invalid limit parameter")
+		}
+		filter.Limit = min(limit, maxJournalLimit)
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"mockj-go/internal/database"
+	"mockj-go/internal/events"
+	"mockj-go/internal/models"
+)
+
+// createTestJSON creates a mock through the handler and returns its ID
+func createTestJSON(t *testing.T, handler *JSONHandler, reqBody map[string]interface{}) string {
+	t.Helper()
+
+	body, _ := json.Marshal(reqBody)
+	req := httptest.NewRequest("POST", "/api/json", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	handler.CreateJSON(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+
+	var response map[string]interface{}
+	_ = json.Unmarshal(w.Body.Bytes(), &response)
+	return response["data"].(map[string]interface{})["id"].(string)
+}
+
+func TestRequestJournal(t *testing.T) {
+	db, err := database.NewDatabase(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	handler := NewJSONHandler(db, events.NewBus(), nil)
+
+	id := createTestJSON(t, handler, map[string]interface{}{
+		"json":     `{"name": "John"}`,
+		"password": "test123",
+	})
+
+	listRequests := func(t *testing.T, query string) []map[string]interface{} {
+		req := httptest.NewRequest("GET", "/api/json/"+id+"/requests"+query, nil)
+		w := httptest.NewRecorder()
+		handler.ListRequests(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+
+		var response struct {
+			Data []map[string]interface{} `json:"data"`
+		}
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		return response.Data
+	}
+
+	// Hit the mock twice and a missing mock once
+	for _, method := range []string{"GET", "POST"} {
+		req := httptest.NewRequest(method, "/api/json/"+id+"/content?page=2", bytes.NewReader([]byte(`{"q": 1}`)))
+		req.Header.Set("X-Test", "journal")
+		req.Header.Set("Authorization", "Bearer secret")
+		req.Header.Set(mockPasswordHeader, "test123")
+		handler.GetJSONContent(httptest.NewRecorder(), req)
+	}
+	handler.GetJSONContent(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/json/missing/content", nil))
+
+	t.Run("ListRequests", func(t *testing.T) {
+		entries := listRequests(t, "")
+		if len(entries) != 2 {
+			t.Fatalf("Expected 2 journal entries, got %d", len(entries))
+		}
+
+		latest := entries[0]
+		if latest["method"] != "POST" || latest["query"] != "page=2" || latest["body"] != `{"q": 1}` {
+			t.Errorf("Unexpected journal entry: %v", latest)
+		}
+		if latest["status"] != float64(http.StatusOK) || latest["mockId"] != id {
+			t.Errorf("Expected status 200 for mock %s, got %v", id, latest)
+		}
+		if headers, _ := latest["headers"].(map[string]interface{}); headers["X-Test"] == nil {
+			t.Errorf("Expected recorded headers, got %v", latest["headers"])
+		}
+	})
+
+	t.Run("CredentialsRedacted", func(t *testing.T) {
+		headers, _ := listRequests(t, "")[0]["headers"].(map[string]interface{})
+		for _, name := range []string{"Authorization", mockPasswordHeader} {
+			if values, _ := headers[name].([]interface{}); len(values) != 1 || values[0] != redactedValue {
+				t.Errorf("Expected %s to be redacted, got %v", name, headers[name])
+			}
+		}
+	})
+
+	t.Run("UnknownMockNotJournaled", func(t *testing.T) {
+		entries, err := db.ListRequests(t.Context(), models.RequestFilter{MockID: "missing"})
+		if err != nil {
+			t.Fatalf("Failed to list requests: %v", err)
+		}
+		if len(entries) != 0 {
+			t.Errorf("Expected no entries for a missing mock, got %d", len(entries))
+		}
+	})
+
+	t.Run("BodyLeftForHandler", func(t *testing.T) {
+		content := strings.Repeat("x", maxJournalBody+10)
+		req := httptest.NewRequest("POST", "/api/json/"+id+"/content", strings.NewReader(content))
+		jw, _ := handler.startJournal(httptest.NewRecorder(), req, id)
+
+		body, _ := io.ReadAll(req.Body)
+		if string(body) != content {
+			t.Errorf("Expected the handler to read the whole body, got %d bytes", len(body))
+		}
+		if len(jw.body) != maxJournalBody {
+			t.Errorf("Expected the journal to keep %d bytes, got %d", maxJournalBody, len(jw.body))
+		}
+	})
+
+	t.Run("FilterRequests", func(t *testing.T) {
+		if entries := listRequests(t, "?method=get"); len(entries) != 1 {
+			t.Errorf("Expected 1 GET entry, got %d", len(entries))
+		}
+		if entries := listRequests(t, "?limit=1"); len(entries) != 1 {
+			t.Errorf("Expected limit to apply, got %d entries", len(entries))
+		}
+		if entries := listRequests(t, "?status=404"); len(entries) != 0 {
+			t.Errorf("Expected no 404 entries, got %d", len(entries))
+		}
+	})
+
+	t.Run("ClearRequestsWrongPassword", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]interface{}{"password": "wrongpassword"})
+		req := httptest.NewRequest("DELETE", "/api/json/"+id+"/requests", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		handler.ClearRequests(w, req)
+
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status %d, got %d", http.StatusUnauthorized, w.Code)
+		}
+	})
+
+	t.Run("ClearRequests", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]interface{}{"password": "test123"})
+		req := httptest.NewRequest("DELETE", "/api/json/"+id+"/requests", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		handler.ClearRequests(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+		}
+		if entries := listRequests(t, ""); len(entries) != 0 {
+			t.Errorf("Expected empty journal after clear, got %d entries", len(entries))
+		}
+	})
+
+	t.Run("CleanupJournal", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			handler.GetJSONContent(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/json/"+id+"/content", nil))
+		}
+
+		if err := db.CleanupJournal(t.Context(), 0, 2); err != nil {
+			t.Fatalf("Failed to cleanup journal: %v", err)
+		}
+		if entries := listRequests(t, ""); len(entries) != 2 {
+			t.Errorf("Expected journal trimmed to 2 entries, got %d", len(entries))
+		}
+	})
+
+	t.Run("DeletedWithMock", func(t *testing.T) {
+		if err := db.DeleteJSON(t.Context(), id); err != nil {
+			t.Fatalf("Failed to delete mock: %v", err)
+		}
+		entries, err := db.ListRequests(t.Context(), models.RequestFilter{MockID: id})
+		if err != nil {
+			t.Fatalf("Failed to list requests: %v", err)
+		}
+		if len(entries) != 0 {
+			t.Errorf("Expected the journal to be deleted with the mock, got %d entries", len(entries))
+		}
+	})
+}
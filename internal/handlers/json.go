@@ -1,37 +1,71 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"strings"
 	"time"
 
+	"mockj-go/internal/callback"
 	"mockj-go/internal/database"
+	"mockj-go/internal/events"
 	"mockj-go/internal/models"
+	"mockj-go/internal/websocket"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
 type JSONHandler struct {
-	db *database.Database
+	db        *database.Database
+	events    *events.Bus
+	sockets   *websocket.Hub
+	callbacks *callback.Pool // nil disables mock callbacks
 }
 
-func NewJSONHandler(db *database.Database) *JSONHandler {
-	return &JSONHandler{db: db}
+func NewJSONHandler(db *database.Database, bus *events.Bus, callbacks *callback.Pool) *JSONHandler {
+	return &JSONHandler{db: db, events: bus, sockets: websocket.NewHub(), callbacks: callbacks}
 }
 
 // CreateJSONRequest represents the request body for creating a JSON
 type CreateJSONRequest struct {
-	Content  string     `json:"json"`
-	Password string     `json:"password"`
-	Expires  *time.Time `json:"expires,omitempty"`
+	Content       string                  `json:"json"`
+	ContentType   string                  `json:"contentType,omitempty"` // json (default), yaml, xml, text, csv or base64 binary
+	Password      string                  `json:"password"`
+	Expires       *time.Time              `json:"expires,omitempty"`
+	Fault         *models.Fault           `json:"fault,omitempty"`
+	Method        string                  `json:"method,omitempty"`
+	Route         string                  `json:"route,omitempty"`
+	Status        int                     `json:"status,omitempty"`
+	Headers       map[string]string       `json:"headers,omitempty"`
+	GraphQLSchema string                  `json:"graphqlSchema,omitempty"` // SDL of the GraphQL API the content answers
+	WebSocket     *models.WebSocketScript `json:"websocket,omitempty"`
+	Callbacks     []models.Callback       `json:"callbacks,omitempty"`
+	CORS          *models.CORS            `json:"cors,omitempty"` // Overrides the server's CORS policy
 }
 
 // UpdateJSONRequest represents the request body for updating a JSON
 type UpdateJSONRequest struct {
-	Content  *string    `json:"json,omitempty"`
-	Password string     `json:"password"`
-	Expires  *time.Time `json:"expires,omitempty"`
+	Content       *string                 `json:"json,omitempty"`
+	ContentType   *string                 `json:"contentType,omitempty"`
+	Password      string                  `json:"password"`
+	Expires       *time.Time              `json:"expires,omitempty"`
+	Fault         *models.Fault           `json:"fault,omitempty"` // An empty fault type removes the fault
+	Method        *string                 `json:"method,omitempty"`
+	Route         *string                 `json:"route,omitempty"` // An empty route unbinds the mock
+	Status        *int                    `json:"status,omitempty"`
+	Headers       *map[string]string      `json:"headers,omitempty"`
+	GraphQLSchema *string                 `json:"graphqlSchema,omitempty"` // An empty schema removes the GraphQL endpoint
+	WebSocket     *models.WebSocketScript `json:"websocket,omitempty"`     // A script without messages removes it
+	Callbacks     *[]models.Callback      `json:"callbacks,omitempty"`     // An empty list removes the callbacks
+	CORS          *models.CORS            `json:"cors,omitempty"`          // A policy without origins removes it
+}
+
+// PasswordRequest represents a request body carrying only the password
+type PasswordRequest struct {
+	Password string `json:"password"`
 }
 
 // ErrorResponse represents an error response
@@ -59,6 +93,11 @@ func (h *JSONHandler) CreateJSON(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := models.ValidateContent(req.ContentType, req.Content); err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid_content", err.Error())
+		return
+	}
+
 	if req.Password == "" {
 		h.writeError(w, http.StatusBadRequest, "invalid_password", "Password is required")
 		return
@@ -74,6 +113,38 @@ func (h *JSONHandler) CreateJSON(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.Fault != nil {
+		if err := req.Fault.Validate(); err != nil {
+			h.writeError(w, http.StatusBadRequest, "invalid_fault", err.Error())
+			return
+		}
+	}
+
+	if req.WebSocket != nil {
+		if err := req.WebSocket.Validate(); err != nil {
+			h.writeError(w, http.StatusBadRequest, "invalid_websocket", err.Error())
+			return
+		}
+	}
+
+	if err := models.ValidateCallbacks(req.Callbacks); err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid_callbacks", err.Error())
+		return
+	}
+
+	if req.CORS != nil {
+		if err := req.CORS.Validate(); err != nil {
+			h.writeError(w, http.StatusBadRequest, "invalid_cors", err.Error())
+			return
+		}
+	}
+
+	req.Method = strings.ToUpper(req.Method)
+	if err := validateBinding(req.Method, req.Route, req.Status); err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid_route", err.Error())
+		return
+	}
+
 	// Hash password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
@@ -85,11 +156,27 @@ func (h *JSONHandler) CreateJSON(w http.ResponseWriter, r *http.Request) {
 	if req.Expires != nil {
 		jsonModel.Expires = *req.Expires
 	}
+	jsonModel.ContentType = req.ContentType
+	jsonModel.Fault = req.Fault
+	jsonModel.Method = req.Method
+	jsonModel.Route = req.Route
+	jsonModel.Status = req.Status
+	jsonModel.Headers = req.Headers
+	jsonModel.GraphQLSchema = req.GraphQLSchema
+	jsonModel.WebSocket = req.WebSocket
+	jsonModel.Callbacks = req.Callbacks
+	jsonModel.CORS = req.CORS
+
+	if err := validateGraphQL(jsonModel); err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid_schema", err.Error())
+		return
+	}
 
-	if err := h.db.CreateJSON(jsonModel); err != nil {
+	if err := h.db.CreateJSON(r.Context(), jsonModel); err != nil {
 		h.writeError(w, http.StatusInternalServerError, "database_error", "Failed to create JSON")
 		return
 	}
+	h.publishMock(events.Created, jsonModel)
 
 	h.writeJSON(w, http.StatusCreated, SuccessResponse{
 		Data:    jsonModel,
@@ -105,7 +192,7 @@ func (h *JSONHandler) GetJSON(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	jsonModel, err := h.db.GetJSON(id)
+	jsonModel, err := h.db.GetJSON(r.Context(), id)
 	if err != nil {
 		if err.Error() == "json not found or expired" {
 			h.writeError(w, http.StatusNotFound, "not_found", "JSON not found or expired")
@@ -120,7 +207,8 @@ func (h *JSONHandler) GetJSON(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// GetJSONContent handles GET /api/json/{id}/content - returns raw JSON content
+// GetJSONContent handles GET /api/json/{id}/content - returns the raw content, converted
+// to YAML, XML or CSV when ?format= or the Accept header asks for it
 func (h *JSONHandler) GetJSONContent(w http.ResponseWriter, r *http.Request) {
 	id := extractIDFromPath(r.URL.Path)
 	if id == "" {
@@ -128,7 +216,7 @@ func (h *JSONHandler) GetJSONContent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	jsonModel, err := h.db.GetJSON(id)
+	jsonModel, err := h.db.GetJSON(r.Context(), id)
 	if err != nil {
 		if err.Error() == "json not found or expired" {
 			h.writeError(w, http.StatusNotFound, "not_found", "JSON not found or expired")
@@ -138,10 +226,60 @@ func (h *JSONHandler) GetJSONContent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Set Content-Type to application/json and return the raw content
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	_, _ = w.Write([]byte(jsonModel.Content))
+	w, record := h.startJournal(w, r, id)
+	defer record()
+
+	jsonModel, ok := h.negotiateContent(w, r, jsonModel)
+	if !ok {
+		return
+	}
+
+	h.serveMock(w, r, jsonModel)
+}
+
+// ServeRoute serves the mock bound to the request's method and path, if any.
+// It reports whether a mock was found so callers can fall back otherwise.
+func (h *JSONHandler) ServeRoute(w http.ResponseWriter, r *http.Request) bool {
+	jsonModel, err := h.db.FindJSONByRoute(r.Context(), r.Method, r.URL.Path)
+	if err != nil {
+		if err.Error() != "json not found or expired" {
+			slog.ErrorContext(r.Context(), "Failed to find mock", "method", r.Method, "path", r.URL.Path, "error", err)
+		}
+		return false
+	}
+
+	slog.InfoContext(r.Context(), "Mocked route", "method", r.Method, "path", r.URL.Path, "mock_id", jsonModel.ID)
+
+	w, record := h.startJournal(w, r, jsonModel.ID)
+	defer record()
+
+	h.serveMock(w, r, jsonModel)
+	return true
+}
+
+// serveMock writes the mock's content with its configured status and headers
+func (h *JSONHandler) serveMock(w http.ResponseWriter, r *http.Request, jsonModel *models.JSON) {
+	if jsonModel.WebSocket != nil && websocket.IsUpgrade(r) {
+		h.serveWebSocket(w, r, jsonModel)
+		return
+	}
+
+	if jsonModel.Fault != nil && jsonModel.Fault.ShouldApply() {
+		h.serveFault(w, r, jsonModel)
+		return
+	}
+
+	body, err := jsonModel.Body()
+	if err != nil {
+		h.writeError(w, http.StatusInternalServerError, "invalid_content", "Stored content cannot be decoded")
+		return
+	}
+
+	setMockHeaders(w.Header(), jsonModel)
+	w.WriteHeader(jsonModel.ResponseStatus())
+	_, _ = w.Write(body)
+
+	h.scheduleCallbacks(w, r, jsonModel)
 }
 
 // UpdateJSON handles PUT /api/json/{id}
@@ -158,30 +296,41 @@ func (h *JSONHandler) UpdateJSON(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.Password == "" {
-		h.writeError(w, http.StatusBadRequest, "invalid_password", "Password is required")
-		return
-	}
-
 	if req.Expires != nil && req.Expires.Before(time.Now()) {
 		h.writeError(w, http.StatusBadRequest, "invalid_expires", "Expiration time must be in the future")
 		return
 	}
 
-	// Get existing JSON with password
-	jsonModel, err := h.db.GetJSONWithPassword(id)
-	if err != nil {
-		if err.Error() == "json not found or expired" {
-			h.writeError(w, http.StatusNotFound, "not_found", "JSON not found or expired")
-		} else {
-			h.writeError(w, http.StatusInternalServerError, "database_error", "Failed to retrieve JSON")
+	if req.Fault != nil && req.Fault.Type != "" {
+		if err := req.Fault.Validate(); err != nil {
+			h.writeError(w, http.StatusBadRequest, "invalid_fault", err.Error())
+			return
 		}
-		return
 	}
 
-	// Verify password
-	if err := bcrypt.CompareHashAndPassword([]byte(jsonModel.Password), []byte(req.Password)); err != nil {
-		h.writeError(w, http.StatusUnauthorized, "unauthorized", "Invalid password")
+	if req.WebSocket != nil && len(req.WebSocket.Messages) > 0 {
+		if err := req.WebSocket.Validate(); err != nil {
+			h.writeError(w, http.StatusBadRequest, "invalid_websocket", err.Error())
+			return
+		}
+	}
+
+	if req.Callbacks != nil {
+		if err := models.ValidateCallbacks(*req.Callbacks); err != nil {
+			h.writeError(w, http.StatusBadRequest, "invalid_callbacks", err.Error())
+			return
+		}
+	}
+
+	if req.CORS != nil && len(req.CORS.AllowedOrigins) > 0 {
+		if err := req.CORS.Validate(); err != nil {
+			h.writeError(w, http.StatusBadRequest, "invalid_cors", err.Error())
+			return
+		}
+	}
+
+	jsonModel, ok := h.authorize(r.Context(), w, id, req.Password)
+	if !ok {
 		return
 	}
 
@@ -189,14 +338,74 @@ func (h *JSONHandler) UpdateJSON(w http.ResponseWriter, r *http.Request) {
 	if req.Content != nil {
 		jsonModel.Content = *req.Content
 	}
+	if req.ContentType != nil {
+		jsonModel.ContentType = *req.ContentType
+	}
 	if req.Expires != nil {
 		jsonModel.Expires = *req.Expires
 	}
+	if req.Fault != nil {
+		if req.Fault.Type == "" {
+			jsonModel.Fault = nil
+		} else {
+			jsonModel.Fault = req.Fault
+		}
+	}
+	if req.Method != nil {
+		jsonModel.Method = strings.ToUpper(*req.Method)
+	}
+	if req.Route != nil {
+		jsonModel.Route = *req.Route
+	}
+	if req.Status != nil {
+		jsonModel.Status = *req.Status
+	}
+	if req.Headers != nil {
+		jsonModel.Headers = *req.Headers
+	}
+	if req.GraphQLSchema != nil {
+		jsonModel.GraphQLSchema = *req.GraphQLSchema
+	}
+	if req.WebSocket != nil {
+		if len(req.WebSocket.Messages) == 0 {
+			jsonModel.WebSocket = nil
+		} else {
+			jsonModel.WebSocket = req.WebSocket
+		}
+	}
+	if req.Callbacks != nil {
+		jsonModel.Callbacks = *req.Callbacks
+	}
+	if req.CORS != nil {
+		if len(req.CORS.AllowedOrigins) == 0 {
+			jsonModel.CORS = nil
+		} else {
+			jsonModel.CORS = req.CORS
+		}
+	}
+
+	if req.Content != nil || req.ContentType != nil {
+		if err := models.ValidateContent(jsonModel.ContentType, jsonModel.Content); err != nil {
+			h.writeError(w, http.StatusBadRequest, "invalid_content", err.Error())
+			return
+		}
+	}
+
+	if err := validateGraphQL(jsonModel); err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid_schema", err.Error())
+		return
+	}
 
-	if err := h.db.UpdateJSON(jsonModel); err != nil {
+	if err := validateBinding(jsonModel.Method, jsonModel.Route, jsonModel.Status); err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid_route", err.Error())
+		return
+	}
+
+	if err := h.db.UpdateJSON(r.Context(), jsonModel); err != nil {
 		h.writeError(w, http.StatusInternalServerError, "database_error", "Failed to update JSON")
 		return
 	}
+	h.publishMock(events.Updated, jsonModel)
 
 	// Clear password from response before sending
 	jsonModel.Password = ""
@@ -216,38 +425,18 @@ func (h *JSONHandler) DeleteJSON(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Parse request body to get password
-	var req struct {
-		Password string `json:"password"`
-	}
+	var req PasswordRequest
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.writeError(w, http.StatusBadRequest, "invalid_request", "Invalid JSON body")
 		return
 	}
 
-	if req.Password == "" {
-		h.writeError(w, http.StatusBadRequest, "invalid_password", "Password is required")
-		return
-	}
-
-	// Get existing JSON with password
-	json, err := h.db.GetJSONWithPassword(id)
-	if err != nil {
-		if err.Error() == "json not found or expired" {
-			h.writeError(w, http.StatusNotFound, "not_found", "JSON not found or expired")
-		} else {
-			h.writeError(w, http.StatusInternalServerError, "database_error", "Failed to retrieve JSON")
-		}
+	if _, ok := h.authorize(r.Context(), w, id, req.Password); !ok {
 		return
 	}
 
-	// Verify password
-	if err := bcrypt.CompareHashAndPassword([]byte(json.Password), []byte(req.Password)); err != nil {
-		h.writeError(w, http.StatusUnauthorized, "unauthorized", "Invalid password")
-		return
-	}
-
-	if err := h.db.DeleteJSON(id); err != nil {
+	if err := h.db.DeleteJSON(r.Context(), id); err != nil {
 		if err.Error() == "json not found" {
 			h.writeError(w, http.StatusNotFound, "not_found", "JSON not found")
 		} else {
@@ -255,12 +444,39 @@ func (h *JSONHandler) DeleteJSON(w http.ResponseWriter, r *http.Request) {
 		}
 		return
 	}
+	h.events.Publish(events.Deleted, id, nil)
 
 	h.writeJSON(w, http.StatusOK, SuccessResponse{
 		Message: "JSON deleted successfully",
 	})
 }
 
+// authorize loads a mock with its password hash and verifies the given password,
+// writing the error response itself when it fails
+func (h *JSONHandler) authorize(ctx context.Context, w http.ResponseWriter, id, password string) (*models.JSON, bool) {
+	if password == "" {
+		h.writeError(w, http.StatusBadRequest, "invalid_password", "Password is required")
+		return nil, false
+	}
+
+	jsonModel, err := h.db.GetJSONWithPassword(ctx, id)
+	if err != nil {
+		if err.Error() == "json not found or expired" {
+			h.writeError(w, http.StatusNotFound, "not_found", "JSON not found or expired")
+		} else {
+			h.writeError(w, http.StatusInternalServerError, "database_error", "Failed to retrieve JSON")
+		}
+		return nil, false
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(jsonModel.Password), []byte(password)); err != nil {
+		h.writeError(w, http.StatusUnauthorized, "unauthorized", "Invalid password")
+		return nil, false
+	}
+
+	return jsonModel, true
+}
+
 // writeJSON writes a JSON response
 func (h *JSONHandler) writeJSON(w http.ResponseWriter, status int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
@@ -276,6 +492,22 @@ func (h *JSONHandler) writeError(w http.ResponseWriter, status int, errType, mes
 	})
 }
 
+// validateBinding checks the route binding fields of a mock
+func validateBinding(method, route string, status int) error {
+	if err := models.ValidateMethod(method); err != nil {
+		return err
+	}
+	if route != "" {
+		if err := models.ValidateRoute(route); err != nil {
+			return err
+		}
+	}
+	if status != 0 && (status < 100 || status > 599) {
+		return fmt.Errorf("status must be between 100 and 599")
+	}
+	return nil
+}
+
 // extractIDFromPath extracts the ID from the URL path
 func extractIDFromPath(path string) string {
 	parts := strings.Split(path, "/")
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"mockj-go/internal/database"
+	"mockj-go/internal/events"
 )
 
 func TestJSONHandler(t *testing.T) {
@@ -19,7 +20,7 @@ func TestJSONHandler(t *testing.T) {
 	defer db.Close()
 
 	// Setup handler
-	handler := NewJSONHandler(db)
+	handler := NewJSONHandler(db, events.NewBus(), nil)
 
 	// Test case 1: Create JSON with password
 	t.Run("CreateJSON", func(t *testing.T) {
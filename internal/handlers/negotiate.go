@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+
+	"mockj-go/internal/convert"
+	"mockj-go/internal/models"
+)
+
+// conversionOffers lists the media types a JSON mock can be converted to, preferred first
+var conversionOffers = []string{
+	"application/yaml", "application/x-yaml", "text/yaml",
+	"application/xml", "text/xml",
+	"text/csv",
+}
+
+// Default element names of XML conversions, overridden by ?root= and ?item=
+const (
+	defaultXMLRoot = "root"
+	defaultXMLItem = "item"
+)
+
+// negotiateContent returns the mock to serve for the request: the stored one, or a copy
+// whose content is converted to the format named by ?format= or, when the Accept header
+// does not allow the stored type, the one it prefers. It writes the error response itself and returns false when that is impossible.
+func (h *JSONHandler) negotiateContent(w http.ResponseWriter, r *http.Request, jsonModel *models.JSON) (*models.JSON, bool) {
+	w.Header().Add("Vary", "Accept")
+
+	stored := jsonModel.ContentType
+	if stored == "" {
+		stored = models.ContentTypeJSON
+	}
+
+	target := r.URL.Query().Get("format")
+	switch target {
+	case "":
+		storedType, _, _ := mime.ParseMediaType(jsonModel.MediaType())
+		mediaType := storedType
+		if override, ok := jsonModel.Headers["Content-Type"]; ok {
+			mediaType = override
+			if parsed, _, err := mime.ParseMediaType(override); err == nil {
+				mediaType = parsed
+			}
+		}
+
+		// The mock is served as stored whenever the client accepts it, even through */* next
+		// to types it prefers, as browsers send. An override such as application/vnd.api+json
+		// still satisfies clients asking for the stored type.
+		accept := r.Header.Get("Accept")
+		if convert.Accepts(accept, mediaType) || convert.Accepts(accept, storedType) {
+			return jsonModel, true
+		}
+
+		var chosen string
+		if stored == models.ContentTypeJSON {
+			chosen = convert.Negotiate(accept, conversionOffers)
+		}
+		if chosen == "" {
+			h.writeError(w, http.StatusNotAcceptable, "not_acceptable", "No acceptable representation of this mock")
+			return nil, false
+		}
+		target = models.ContentTypeOf(chosen)
+	case models.ContentTypeJSON, models.ContentTypeYAML, models.ContentTypeXML, models.ContentTypeCSV:
+	default:
+		h.writeError(w, http.StatusBadRequest, "invalid_format", "Format must be json, yaml, xml or csv")
+		return nil, false
+	}
+
+	if target == stored {
+		return jsonModel, true
+	}
+	if stored != models.ContentTypeJSON {
+		h.writeError(w, http.StatusNotAcceptable, "not_acceptable", "Only JSON mocks can be converted")
+		return nil, false
+	}
+
+	value, err := convert.Decode([]byte(jsonModel.Content))
+	if err != nil {
+		h.writeError(w, http.StatusNotAcceptable, "not_acceptable", "Stored content is not valid JSON")
+		return nil, false
+	}
+
+	var content []byte
+	switch target {
+	case models.ContentTypeYAML:
+		content, err = convert.ToYAML(value)
+	case models.ContentTypeXML:
+		root, item := r.URL.Query().Get("root"), r.URL.Query().Get("item")
+		if root == "" {
+			root = defaultXMLRoot
+		}
+		if item == "" {
+			item = defaultXMLItem
+		}
+		content, err = convert.ToXML(value, root, item)
+		if err != nil {
+			h.writeError(w, http.StatusBadRequest, "invalid_format", "root and item must be valid XML element names")
+			return nil, false
+		}
+	case models.ContentTypeCSV:
+		content, err = convert.ToCSV(value)
+	}
+	if err != nil {
+		if errors.Is(err, convert.ErrNotConvertible) {
+			h.writeError(w, http.StatusNotAcceptable, "not_acceptable", err.Error())
+		} else {
+			h.writeError(w, http.StatusInternalServerError, "conversion_error", "Failed to convert content")
+		}
+		return nil, false
+	}
+
+	// The converted copy is served with its own media type rather than a configured override
+	converted := *jsonModel
+	converted.Content = string(content)
+	converted.ContentType = target
+	converted.Headers = make(map[string]string, len(jsonModel.Headers))
+	for name, value := range jsonModel.Headers {
+		if http.CanonicalHeaderKey(name) != "Content-Type" {
+			converted.Headers[name] = value
+		}
+	}
+	return &converted, true
+}
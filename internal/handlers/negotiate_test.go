@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"mockj-go/internal/database"
+	"mockj-go/internal/events"
+)
+
+func TestContentNegotiation(t *testing.T) {
+	db, err := database.NewDatabase(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	handler := NewJSONHandler(db, events.NewBus(), nil)
+
+	users := createTestJSON(t, handler, map[string]interface{}{
+		"json":     `[{"name": "John", "age": 30, "admin": true}, {"name": "Jane, Doe", "email": null}]`,
+		"password": "test123",
+	})
+	nested := createTestJSON(t, handler, map[string]interface{}{
+		"json":     `{"user": {"name": "John", "tags": ["a", "b"], "2fa": false}, "note": "yes"}`,
+		"password": "test123",
+	})
+	text := createTestJSON(t, handler, map[string]interface{}{
+		"json":        "plain",
+		"contentType": "text",
+		"password":    "test123",
+	})
+	vendor := createTestJSON(t, handler, map[string]interface{}{
+		"json":     `{"data": []}`,
+		"headers":  map[string]string{"Content-Type": "application/vnd.api+json"},
+		"password": "test123",
+	})
+
+	get := func(t *testing.T, id, query, accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/json/"+id+"/content"+query, nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		w := httptest.NewRecorder()
+		handler.GetJSONContent(w, req)
+		return w
+	}
+
+	t.Run("DefaultIsStored", func(t *testing.T) {
+		w := get(t, users, "", "text/html, */*;q=0.8")
+		if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/json" {
+			t.Errorf("Expected stored JSON, got %d %q", w.Code, w.Header().Get("Content-Type"))
+		}
+	})
+
+	t.Run("YAML", func(t *testing.T) {
+		w := get(t, nested, "", "application/yaml")
+		expected := "user:\n  name: John\n  tags:\n    - a\n    - b\n  2fa: false\nnote: \"yes\"\n"
+		if w.Code != http.StatusOK || w.Body.String() != expected {
+			t.Errorf("Expected YAML\n%s\ngot %d\n%s", expected, w.Code, w.Body.String())
+		}
+		if w.Header().Get("Content-Type") != "application/yaml" {
+			t.Errorf("Expected application/yaml, got %q", w.Header().Get("Content-Type"))
+		}
+	})
+
+	t.Run("XML", func(t *testing.T) {
+		w := get(t, nested, "?format=xml&root=doc", "")
+		expected := `<?xml version="1.0" encoding="UTF-8"?>
+<doc>
+  <user>
+    <name>John</name>
+    <tags>
+      <item>a</item>
+      <item>b</item>
+    </tags>
+    <item name="2fa">false</item>
+  </user>
+  <note>yes</note>
+</doc>
+`
+		if w.Code != http.StatusOK || w.Body.String() != expected {
+			t.Errorf("Expected XML\n%s\ngot %d\n%s", expected, w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("CSV", func(t *testing.T) {
+		w := get(t, users, "", "text/csv")
+		expected := "name,age,admin,email\nJohn,30,true,\n\"Jane, Doe\",,,\n"
+		if w.Code != http.StatusOK || w.Body.String() != expected {
+			t.Errorf("Expected CSV\n%s\ngot %d\n%s", expected, w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("QualityValues", func(t *testing.T) {
+		w := get(t, users, "", "application/json;q=0.5, application/xml")
+		if w.Header().Get("Content-Type") != "application/json" {
+			t.Errorf("Expected the acceptable stored type, got %q", w.Header().Get("Content-Type"))
+		}
+		w = get(t, users, "", "application/json;q=0, text/csv;q=0.5, application/xml")
+		if w.Header().Get("Content-Type") != "application/xml" {
+			t.Errorf("Expected XML to be preferred, got %q", w.Header().Get("Content-Type"))
+		}
+	})
+
+	t.Run("Browser", func(t *testing.T) {
+		w := get(t, users, "", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+		if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/json" {
+			t.Errorf("Expected stored JSON, got %d %q", w.Code, w.Header().Get("Content-Type"))
+		}
+		w = get(t, users, "?format=xml", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+		if w.Header().Get("Content-Type") != "application/xml" {
+			t.Errorf("Expected ?format= to convert, got %q", w.Header().Get("Content-Type"))
+		}
+	})
+
+	t.Run("ContentTypeOverride", func(t *testing.T) {
+		for _, accept := range []string{"application/vnd.api+json", "application/json"} {
+			w := get(t, vendor, "", accept)
+			if w.Code != http.StatusOK || w.Body.String() != `{"data": []}` {
+				t.Errorf("Accept %s: expected the stored content, got %d %s", accept, w.Code, w.Body.String())
+			}
+		}
+		if w := get(t, vendor, "", "application/yaml"); w.Header().Get("Content-Type") != "application/yaml" {
+			t.Errorf("Expected the override to still convert to YAML, got %q", w.Header().Get("Content-Type"))
+		}
+	})
+
+	t.Run("NotAcceptable", func(t *testing.T) {
+		if w := get(t, nested, "?format=csv", ""); w.Code != http.StatusNotAcceptable {
+			t.Errorf("Expected status %d for nested CSV, got %d", http.StatusNotAcceptable, w.Code)
+		}
+		if w := get(t, text, "", "application/xml"); w.Code != http.StatusNotAcceptable {
+			t.Errorf("Expected status %d for converting text, got %d", http.StatusNotAcceptable, w.Code)
+		}
+		if w := get(t, users, "", "image/png"); w.Code != http.StatusNotAcceptable {
+			t.Errorf("Expected status %d for an unsupported Accept, got %d", http.StatusNotAcceptable, w.Code)
+		}
+	})
+
+	t.Run("InvalidFormat", func(t *testing.T) {
+		if w := get(t, users, "?format=toml", ""); w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+		}
+	})
+}
@@ -0,0 +1,345 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"mockj-go/internal/graphql"
+	"mockj-go/internal/models"
+	"mockj-go/internal/openapi"
+	"mockj-go/internal/websocket"
+)
+
+// APIVersion is the version reported in the OpenAPI document
+const APIVersion = "1.0.0"
+
+// Common responses shared by several operations
+var (
+	badRequest   = openapi.Response{Status: http.StatusBadRequest, Description: "Invalid request", Body: ErrorResponse{}}
+	unauthorized = openapi.Response{Status: http.StatusUnauthorized, Description: "Invalid password", Body: ErrorResponse{}}
+	notFound     = openapi.Response{Status: http.StatusNotFound, Description: "JSON not found or expired", Body: ErrorResponse{}}
+	serverError  = openapi.Response{Status: http.StatusInternalServerError, Description: "Internal error", Body: ErrorResponse{}}
+
+	webhookNotFound = openapi.Response{Status: http.StatusNotFound, Description: "Webhook not found", Body: ErrorResponse{}}
+)
+
+// graphqlResponses are the responses of both GraphQL transports
+var graphqlResponses = []openapi.Response{
+	{Status: http.StatusOK, Description: "Result with data, errors or both", Body: GraphQLResponse{}},
+	{Status: http.StatusBadRequest, Description: "Invalid request, or a GraphQL request error when application/graphql-response+json is accepted", Body: ErrorResponse{}},
+	{Status: http.StatusNotFound, Description: "JSON not found or expired, or the mock has no GraphQL schema", Body: ErrorResponse{}},
+	{Status: http.StatusMethodNotAllowed, Description: "Mutation sent with GET", Body: ErrorResponse{}},
+	serverError,
+}
+
+// APIOperations describes every endpoint the server registers, in terms of the
+// request and response types the handlers actually decode and encode
+func APIOperations() []openapi.Operation {
+	return []openapi.Operation{
+		{
+			Method: "POST", Path: "/api/json", Summary: "Create a mock",
+			Request: CreateJSONRequest{},
+			Responses: []openapi.Response{
+				{Status: http.StatusCreated, Description: "Mock created", Body: SuccessResponse{}, Data: models.JSON{}},
+				badRequest, serverError,
+			},
+		},
+		{
+			Method: "GET", Path: "/api/json/{id}", Summary: "Get a mock",
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Description: "The mock", Body: SuccessResponse{}, Data: models.JSON{}},
+				notFound, serverError,
+			},
+		},
+		{
+			Method: "GET", Path: "/api/json/{id}/content", Summary: "Serve the raw content of a mock",
+			Query: []string{"format", "root", "item"},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Description: "The stored content, or its conversion to the negotiated format", Body: json.RawMessage{}},
+				badRequest, notFound,
+				{Status: http.StatusNotAcceptable, Description: "Content cannot be converted to an acceptable format", Body: ErrorResponse{}},
+				serverError,
+			},
+		},
+		{
+			Method: "PUT", Path: "/api/json/{id}/content", Summary: "Replace the content of a mock with the raw body",
+			Query:   []string{"type"},
+			Headers: []string{mockPasswordHeader},
+			Request: "", RequestContentType: "application/octet-stream",
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Description: "Content uploaded", Body: SuccessResponse{}, Data: models.JSON{}},
+				badRequest, unauthorized, notFound,
+				{Status: http.StatusRequestEntityTooLarge, Description: "Content exceeds the upload size limit", Body: ErrorResponse{}},
+				serverError,
+			},
+		},
+		{
+			Method: "PUT", Path: "/api/json/{id}", Summary: "Update a mock",
+			Request: UpdateJSONRequest{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Description: "Mock updated", Body: SuccessResponse{}, Data: models.JSON{}},
+				badRequest, unauthorized, notFound, serverError,
+			},
+		},
+		{
+			Method: "DELETE", Path: "/api/json/{id}", Summary: "Delete a mock",
+			Request: PasswordRequest{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Description: "Mock deleted", Body: SuccessResponse{}},
+				badRequest, unauthorized, notFound, serverError,
+			},
+		},
+		{
+			Method: "GET", Path: "/api/json/{id}/requests", Summary: "List requests served by a mock",
+			Query: []string{"method", "status", "since", "until", "limit"},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Description: "Journal entries, newest first", Body: SuccessResponse{}, Data: []models.RequestLog{}},
+				badRequest, serverError,
+			},
+		},
+		{
+			Method: "GET", Path: "/api/json/{id}/callbacks", Summary: "List the results of a mock's callbacks",
+			Query:   []string{"limit"},
+			Headers: []string{mockPasswordHeader},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Description: "Callback results, newest first", Body: SuccessResponse{}, Data: []models.CallbackResult{}},
+				badRequest, unauthorized, notFound, serverError,
+			},
+		},
+		{
+			Method: "DELETE", Path: "/api/json/{id}/requests", Summary: "Clear the request journal of a mock",
+			Request: PasswordRequest{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Description: "Journal cleared", Body: SuccessResponse{}, Data: map[string]int64{}},
+				badRequest, unauthorized, notFound, serverError,
+			},
+		},
+		{
+			Method: "GET", Path: "/api/json/{id}/schema", Summary: "Infer a JSON Schema from mock content",
+			Query: []string{"merge"},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Description: "Inferred JSON Schema", Body: map[string]interface{}{}, ContentType: "application/schema+json"},
+				badRequest, notFound,
+				{Status: http.StatusUnprocessableEntity, Description: "Content is not valid JSON", Body: ErrorResponse{}},
+				serverError,
+			},
+		},
+		{
+			Method: "GET", Path: "/api/json/{id}/types", Summary: "Generate typed models from mock content",
+			Query: []string{"lang", "name", "merge"},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Description: "Type definitions", Body: "", ContentType: "text/plain"},
+				badRequest, notFound,
+				{Status: http.StatusUnprocessableEntity, Description: "Content is not valid JSON", Body: ErrorResponse{}},
+				serverError,
+			},
+		},
+		{
+			Method: "GET", Path: "/api/json/{id}/graphql", Summary: "Run a GraphQL query against a mock",
+			Query:     []string{"query", "operationName", "variables"},
+			Responses: graphqlResponses,
+		},
+		{
+			Method: "POST", Path: "/api/json/{id}/graphql", Summary: "Run a GraphQL operation against a mock",
+			Request:   graphql.Request{},
+			Responses: graphqlResponses,
+		},
+		{
+			Method: "GET", Path: "/api/json/{id}/ws", Summary: "Open a WebSocket that plays the mock's script",
+			Headers: []string{"Connection", "Upgrade", "Sec-WebSocket-Key", "Sec-WebSocket-Version"},
+			Responses: []openapi.Response{
+				{Status: http.StatusSwitchingProtocols, Description: "Switched to the WebSocket protocol"},
+				badRequest,
+				{Status: http.StatusNotFound, Description: "JSON not found or expired, or the mock has no WebSocket script", Body: ErrorResponse{}},
+				{Status: http.StatusUpgradeRequired, Description: "Not a WebSocket version 13 upgrade request", Body: "", ContentType: "text/plain"},
+				serverError,
+			},
+		},
+		{
+			Method: "GET", Path: "/api/json/{id}/stream", Summary: "Stream the elements of an array mock as Server-Sent Events",
+			Query:   []string{"interval", "event", "repeat", "loop"},
+			Headers: []string{"Last-Event-ID"},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Description: "One event per array element", Body: "", ContentType: "text/event-stream"},
+				{Status: http.StatusNoContent, Description: "Last-Event-ID is the last event of a finite stream, or past it"},
+				badRequest, notFound,
+				{Status: http.StatusUnprocessableEntity, Description: "Content is not a JSON array", Body: ErrorResponse{}},
+				serverError,
+			},
+		},
+		{
+			Method: "GET", Path: "/api/json/{id}/watch", Summary: "Stream the changes of a mock as Server-Sent Events until it is deleted or expires",
+			Headers: []string{"Last-Event-ID"},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Description: "One event per change, named after its type", Body: "", ContentType: "text/event-stream"},
+				badRequest, notFound, serverError,
+			},
+		},
+		{
+			Method: "GET", Path: "/api/events", Summary: "Stream the changes of every mock as Server-Sent Events",
+			Query:   []string{"types"},
+			Headers: []string{"Last-Event-ID", transferPasswordHeader},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Description: "One event per change, named after its type", Body: "", ContentType: "text/event-stream"},
+				badRequest, unauthorized,
+			},
+		},
+		{
+			Method: "GET", Path: "/api/websockets", Summary: "List open WebSocket connections",
+			Query: []string{"mockId"},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Description: "Connections, oldest first", Body: SuccessResponse{}, Data: []websocket.ConnectionInfo{}},
+			},
+		},
+		{
+			Method: "DELETE", Path: "/api/websockets/{connectionId}", Summary: "Disconnect a WebSocket client",
+			Request: PasswordRequest{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Description: "Connection closed", Body: SuccessResponse{}},
+				badRequest, unauthorized,
+				{Status: http.StatusNotFound, Description: "Connection not found", Body: ErrorResponse{}},
+				serverError,
+			},
+		},
+		{
+			Method: "POST", Path: "/api/webhooks", Summary: "Subscribe a webhook to mock lifecycle events",
+			Headers: []string{transferPasswordHeader},
+			Request: CreateWebhookRequest{},
+			Responses: []openapi.Response{
+				{Status: http.StatusCreated, Description: "Webhook subscribed", Body: SuccessResponse{}, Data: models.Webhook{}},
+				badRequest, unauthorized, notFound, serverError,
+			},
+		},
+		{
+			Method: "GET", Path: "/api/webhooks/{webhookId}", Summary: "Get a webhook",
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Description: "The webhook", Body: SuccessResponse{}, Data: models.Webhook{}},
+				webhookNotFound, serverError,
+			},
+		},
+		{
+			Method: "DELETE", Path: "/api/webhooks/{webhookId}", Summary: "Unsubscribe a webhook and drop its deliveries",
+			Request: PasswordRequest{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Description: "Webhook deleted", Body: SuccessResponse{}},
+				badRequest, unauthorized, webhookNotFound, serverError,
+			},
+		},
+		{
+			Method: "GET", Path: "/api/webhooks/{webhookId}/deliveries", Summary: "List the delivery log of a webhook",
+			Query: []string{"state", "limit"},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Description: "Deliveries, newest first", Body: SuccessResponse{}, Data: []models.WebhookDelivery{}},
+				badRequest, webhookNotFound, serverError,
+			},
+		},
+		{
+			Method: "POST", Path: "/api/json/{id}/attachments", Summary: "Attach a file to a mock",
+			Request: UploadAttachmentForm{}, RequestContentType: "multipart/form-data",
+			Responses: []openapi.Response{
+				{Status: http.StatusCreated, Description: "Attachment stored", Body: SuccessResponse{}, Data: models.Attachment{}},
+				badRequest, unauthorized, notFound,
+				{Status: http.StatusRequestEntityTooLarge, Description: "File exceeds the size limit or the storage quota", Body: ErrorResponse{}},
+				serverError,
+			},
+		},
+		{
+			Method: "GET", Path: "/api/json/{id}/attachments", Summary: "List the attachments of a mock",
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Description: "Attachments, oldest first", Body: SuccessResponse{}, Data: []models.Attachment{}},
+				notFound, serverError,
+			},
+		},
+		{
+			Method: "GET", Path: "/api/json/{id}/attachments/{attachmentId}", Summary: "Download an attachment",
+			Query: []string{"download"},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Description: "The file", Body: "", ContentType: "application/octet-stream"},
+				{Status: http.StatusPartialContent, Description: "The requested byte range", Body: "", ContentType: "application/octet-stream"},
+				notFound,
+				{Status: http.StatusRequestedRangeNotSatisfiable, Description: "Range outside the file", Body: ""},
+				serverError,
+			},
+		},
+		{
+			Method: "DELETE", Path: "/api/json/{id}/attachments/{attachmentId}", Summary: "Delete an attachment",
+			Request: PasswordRequest{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Description: "Attachment deleted", Body: SuccessResponse{}},
+				badRequest, unauthorized, notFound, serverError,
+			},
+		},
+		{
+			Method: "POST", Path: "/api/verify", Summary: "Verify journaled requests against a pattern",
+			Request: VerifyRequest{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Description: "Verification result", Body: SuccessResponse{}, Data: VerifyResult{}},
+				badRequest, serverError,
+			},
+		},
+		{
+			Method: "POST", Path: "/api/import/openapi", Summary: "Create mocks from an OpenAPI 3.x spec",
+			Request: ImportOpenAPIRequest{},
+			Responses: []openapi.Response{
+				{Status: http.StatusCreated, Description: "Spec imported", Body: SuccessResponse{}, Data: ImportOpenAPIResult{}},
+				badRequest, serverError,
+			},
+		},
+		{
+			Method: "GET", Path: "/api/export", Summary: "Export every mock as an NDJSON or tar archive",
+			Query:   []string{"format"},
+			Headers: []string{transferPasswordHeader},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Description: "One exported mock per line, or a tar archive with format=tar", Body: ExportedMock{}, ContentType: ndjsonContentType},
+				badRequest, unauthorized, serverError,
+			},
+		},
+		{
+			Method: "POST", Path: "/api/import", Summary: "Restore mocks from an export archive",
+			Query:   []string{"strategy"},
+			Headers: []string{transferPasswordHeader},
+			Request: ExportedMock{}, RequestContentType: ndjsonContentType,
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Description: "Archive imported", Body: SuccessResponse{}, Data: ImportResult{}},
+				badRequest, unauthorized,
+				{Status: http.StatusRequestEntityTooLarge, Description: "Archive exceeds the import size limit", Body: ErrorResponse{}},
+				{Status: http.StatusUnsupportedMediaType, Description: "Unsupported archive type", Body: ErrorResponse{}},
+				serverError,
+			},
+		},
+		{
+			Method: "POST", Path: "/api/generate", Summary: "Generate data from a JSON Schema",
+			Request: GenerateRequest{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Description: "Generated data", Body: SuccessResponse{}, Data: GenerateResult{}},
+				{Status: http.StatusCreated, Description: "Generated data stored as a mock", Body: SuccessResponse{}, Data: GenerateResult{}},
+				badRequest, serverError,
+			},
+		},
+		{
+			Method: "GET", Path: "/api/proxy", Summary: "Get the record-and-playback proxy status",
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Description: "Proxy status", Body: SuccessResponse{}, Data: ProxyStatus{}},
+			},
+		},
+		{
+			Method: "PUT", Path: "/api/proxy/mode", Summary: "Switch the proxy between record and replay",
+			Request: ProxyModeRequest{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Description: "Mode switched", Body: SuccessResponse{}, Data: ProxyStatus{}},
+				badRequest, unauthorized,
+			},
+		},
+		{
+			Method: "GET", Path: "/api/openapi.json", Summary: "This OpenAPI document",
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Description: "OpenAPI 3.1 document", Body: map[string]interface{}{}},
+			},
+		},
+	}
+}
+
+// GetOpenAPI handles GET /api/openapi.json
+func (h *JSONHandler) GetOpenAPI(w http.ResponseWriter, r *http.Request) {
+	h.writeJSON(w, http.StatusOK, openapi.Document("MockJ-Go API", APIVersion, APIOperations()))
+}
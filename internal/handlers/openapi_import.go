@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"mockj-go/internal/database"
+	"mockj-go/internal/events"
+	"mockj-go/internal/models"
+	"mockj-go/internal/openapi"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ImportOpenAPIRequest represents the request body for importing an OpenAPI spec.
+// Spec holds the document either as a YAML/JSON string or as a JSON object.
+type ImportOpenAPIRequest struct {
+	Spec     json.RawMessage `json:"spec"`
+	Password string          `json:"password"`
+	Prefix   string          `json:"prefix,omitempty"` // Prepended to every imported route
+	Expires  *time.Time      `json:"expires,omitempty"`
+}
+
+// ImportedMock describes a mock created from an OpenAPI response
+type ImportedMock struct {
+	ID string `json:"id"`
+	openapi.MockResponse
+	Route string `json:"route,omitempty"` // Empty unless the mock is served on the operation's route
+}
+
+// ImportOpenAPIResult reports the mocks created by an import and what was skipped
+type ImportOpenAPIResult struct {
+	Created []ImportedMock    `json:"created"`
+	Skipped []openapi.Skipped `json:"skipped"`
+}
+
+// ImportOpenAPI handles POST /api/import/openapi
+func (h *JSONHandler) ImportOpenAPI(w http.ResponseWriter, r *http.Request) {
+	var req ImportOpenAPIRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid_request", "Invalid JSON body")
+		return
+	}
+
+	if len(req.Spec) == 0 {
+		h.writeError(w, http.StatusBadRequest, "invalid_spec", "Spec is required")
+		return
+	}
+
+	if req.Password == "" {
+		h.writeError(w, http.StatusBadRequest, "invalid_password", "Password is required")
+		return
+	}
+
+	if req.Expires != nil && (req.Expires.Before(time.Now()) || req.Expires.After(time.Now().AddDate(1, 0, 0))) {
+		h.writeError(w, http.StatusBadRequest, "invalid_expires", "Expiration time must be in the future and less than 1 year from now")
+		return
+	}
+
+	prefix := strings.TrimSuffix(req.Prefix, "/")
+	if prefix != "" {
+		if err := models.ValidateRoute(prefix); err != nil {
+			h.writeError(w, http.StatusBadRequest, "invalid_prefix", err.Error())
+			return
+		}
+	}
+
+	// A string holds YAML or JSON text, anything else is the JSON document itself
+	document := []byte(req.Spec)
+	var text string
+	if err := json.Unmarshal(req.Spec, &text); err == nil {
+		document = []byte(text)
+	}
+
+	spec, err := openapi.Parse(document)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid_spec", err.Error())
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		h.writeError(w, http.StatusInternalServerError, "hash_error", "Failed to hash password")
+		return
+	}
+
+	responses, skipped := spec.Responses()
+	result := ImportOpenAPIResult{
+		Created: []ImportedMock{},
+		Skipped: skipped,
+	}
+	if result.Skipped == nil {
+		result.Skipped = []openapi.Skipped{}
+	}
+
+	var jsons []*models.JSON
+	for _, response := range responses {
+		jsonModel := models.NewJSON(response.Content, string(hashedPassword))
+		if req.Expires != nil {
+			jsonModel.Expires = *req.Expires
+		}
+		jsonModel.Status = response.Status
+		if response.ContentType != "" && response.ContentType != "application/json" && response.ContentType != "*/*" {
+			jsonModel.Headers = map[string]string{"Content-Type": response.ContentType}
+		}
+
+		// Only the preferred response of an operation is bound to its route,
+		// the others stay reachable through /api/json/{id}/content
+		route := prefix + response.Path
+		if response.Primary {
+			if err := models.ValidateRoute(route); err != nil {
+				result.Skipped = append(result.Skipped, openapi.Skipped{
+					Method: response.Method, Path: response.Path, Reason: err.Error(),
+				})
+				continue
+			}
+			jsonModel.Method = response.Method
+			jsonModel.Route = route
+		}
+
+		jsons = append(jsons, jsonModel)
+		result.Created = append(result.Created, ImportedMock{
+			ID:           jsonModel.ID,
+			MockResponse: response,
+			Route:        jsonModel.Route,
+		})
+	}
+
+	// The spec is imported whole or not at all
+	err = h.db.RunInTx(r.Context(), func(tx *database.Database) error {
+		for _, jsonModel := range jsons {
+			if err := tx.CreateJSON(r.Context(), jsonModel); err != nil {
+				return fmt.Errorf("failed to create json %s: %w", jsonModel.ID, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		h.writeError(w, http.StatusInternalServerError, "database_error", "Failed to create JSON")
+		return
+	}
+	for _, jsonModel := range jsons {
+		h.publishMock(events.Created, jsonModel)
+	}
+
+	h.writeJSON(w, http.StatusCreated, SuccessResponse{
+		Data:    result,
+		Message: "OpenAPI spec imported successfully",
+	})
+}
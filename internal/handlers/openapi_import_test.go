@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"mockj-go/internal/database"
+	"mockj-go/internal/events"
+)
+
+const petstoreSpec = `
+openapi: 3.0.3
+info:
+  title: Petstore
+  version: "1.0"
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      responses:
+        200:
+          description: A list of pets
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: '#/components/schemas/Pet'
+        default:
+          description: Unexpected error
+    post:
+      operationId: createPet
+      responses:
+        "201":
+          description: Created
+          content:
+            application/json:
+              examples:
+                rex:
+                  value: {id: 1, name: Rex}
+        "400":
+          description: Bad request
+          content:
+            application/json:
+              example: {error: invalid}
+  /pets/{petId}:
+    delete:
+      responses:
+        "204":
+          description: Deleted
+    get:
+      responses:
+        "200":
+          description: Pet as text
+          content:
+            text/plain:
+              example: Rex
+components:
+  schemas:
+    Pet:
+      type: object
+      required: [id, name]
+      properties:
+        id:
+          type: integer
+          format: int64
+        name:
+          type: string
+          example: Fido
+        tag:
+          type: string
+          enum: [dog, cat]
+`
+
+func TestImportOpenAPI(t *testing.T) {
+	db, err := database.NewDatabase(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	handler := NewJSONHandler(db, events.NewBus(), nil)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"spec":     petstoreSpec,
+		"password": "test123",
+		"prefix":   "/v1",
+	})
+	req := httptest.NewRequest("POST", "/api/import/openapi", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	handler.ImportOpenAPI(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+
+	var response struct {
+		Data ImportOpenAPIResult `json:"data"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &response)
+
+	// listPets 200, createPet 201 and 400, delete 204
+	if len(response.Data.Created) != 4 {
+		t.Errorf("Expected 4 created mocks, got %d: %+v", len(response.Data.Created), response.Data.Created)
+	}
+	// listPets default and the text/plain get
+	if len(response.Data.Skipped) != 2 {
+		t.Errorf("Expected 2 skipped responses, got %d: %+v", len(response.Data.Skipped), response.Data.Skipped)
+	}
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		if !handler.ServeRoute(w, httptest.NewRequest(method, path, nil)) {
+			t.Fatalf("Expected a mock bound to %s %s", method, path)
+		}
+		return w
+	}
+
+	t.Run("SchemaSample", func(t *testing.T) {
+		w := serve("GET", "/v1/pets")
+		if w.Body.String() != `[{"id":0,"name":"Fido","tag":"dog"}]` {
+			t.Errorf("Unexpected generated sample: %s", w.Body.String())
+		}
+	})
+
+	t.Run("NamedExampleIsPrimary", func(t *testing.T) {
+		w := serve("POST", "/v1/pets")
+		if w.Code != http.StatusCreated || w.Body.String() != `{"id":1,"name":"Rex"}` {
+			t.Errorf("Expected 201 example response, got %d %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("PathParameters", func(t *testing.T) {
+		w := serve("DELETE", "/v1/pets/42")
+		if w.Code != http.StatusNoContent {
+			t.Errorf("Expected status %d, got %d", http.StatusNoContent, w.Code)
+		}
+	})
+
+	t.Run("InvalidSpec", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]interface{}{
+			"spec":     map[string]interface{}{"swagger": "2.0"},
+			"password": "test123",
+		})
+		req := httptest.NewRequest("POST", "/api/import/openapi", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		handler.ImportOpenAPI(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+		}
+	})
+}
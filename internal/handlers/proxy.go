@@ -0,0 +1,277 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"mockj-go/internal/config"
+	"mockj-go/internal/events"
+	"mockj-go/internal/models"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// maxRecordBody caps the size of upstream responses captured as mocks
+const maxRecordBody = 10 << 20
+
+// hopByHopHeaders are never recorded since they describe the upstream connection
+var hopByHopHeaders = []string{
+	"Connection", "Keep-Alive", "Proxy-Authenticate", "Proxy-Authorization",
+	"Te", "Trailer", "Transfer-Encoding", "Upgrade",
+}
+
+// routeContextKey carries the recorded route from the proxied request to its response
+type routeContextKey struct{}
+
+// ProxyHandler forwards requests to an upstream and records the responses as mocks,
+// or replays previously recorded mocks without contacting the upstream
+type ProxyHandler struct {
+	*JSONHandler
+	cfg      config.ProxyConfig
+	target   *url.URL
+	password []byte
+	proxy    *httputil.ReverseProxy
+
+	mu   sync.RWMutex
+	mode string
+}
+
+// ProxyModeRequest represents the request body for switching the proxy mode
+type ProxyModeRequest struct {
+	Mode     string `json:"mode"`
+	Password string `json:"password"`
+}
+
+// ProxyStatus describes the current proxy configuration
+type ProxyStatus struct {
+	Target string `json:"target"`
+	Prefix string `json:"prefix"`
+	Mode   string `json:"mode"`
+}
+
+func NewProxyHandler(jsonHandler *JSONHandler, cfg config.ProxyConfig) (*ProxyHandler, error) {
+	target, err := url.Parse(cfg.Target)
+	if err != nil || target.Scheme == "" || target.Host == "" {
+		return nil, fmt.Errorf("invalid proxy target %q", cfg.Target)
+	}
+
+	// The password is used both for recorded mocks and for switching modes
+	password, err := bcrypt.GenerateFromPassword([]byte(cfg.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash proxy password: %w", err)
+	}
+
+	h := &ProxyHandler{
+		JSONHandler: jsonHandler,
+		cfg:         cfg,
+		target:      target,
+		password:    password,
+		mode:        cfg.Mode,
+	}
+
+	h.proxy = &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.SetURL(target)
+			pr.SetXForwarded()
+			// Ask for an uncompressed body so the recording is readable
+			pr.Out.Header.Del("Accept-Encoding")
+		},
+		Transport:      &http.Transport{Proxy: http.ProxyFromEnvironment, ResponseHeaderTimeout: cfg.Timeout},
+		ModifyResponse: h.record,
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			slog.ErrorContext(r.Context(), "Proxy request failed", "target", target.String(), "error", err)
+			h.writeError(w, http.StatusBadGateway, "proxy_error", "Failed to reach proxy target")
+		},
+	}
+
+	return h, nil
+}
+
+// Mode returns the current proxy mode
+func (h *ProxyHandler) Mode() string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.mode
+}
+
+// ServeHTTP handles requests below the proxy prefix
+func (h *ProxyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	route := strings.TrimPrefix(r.URL.Path, h.cfg.Prefix)
+	if !strings.HasPrefix(route, "/") {
+		route = "/" + route
+	}
+
+	if h.Mode() == config.ProxyModeReplay {
+		h.replay(w, r, route)
+		return
+	}
+
+	outReq := r.Clone(context.WithValue(r.Context(), routeContextKey{}, route))
+	outReq.URL.Path = route
+	outReq.URL.RawPath = ""
+	h.proxy.ServeHTTP(w, outReq)
+}
+
+// replay serves the mock recorded for the route without contacting the upstream
+func (h *ProxyHandler) replay(w http.ResponseWriter, r *http.Request, route string) {
+	jsonModel, err := h.db.FindJSONByRoute(r.Context(), r.Method, route)
+	if err != nil {
+		if err.Error() == "json not found or expired" {
+			h.writeError(w, http.StatusNotFound, "not_recorded", "No recording for "+r.Method+" "+route)
+		} else {
+			h.writeError(w, http.StatusInternalServerError, "database_error", "Failed to retrieve JSON")
+		}
+		return
+	}
+
+	w, record := h.startJournal(w, r, jsonModel.ID)
+	defer record()
+
+	h.serveMock(w, r, jsonModel)
+}
+
+// record stores the upstream response as a mock bound to the proxied route
+func (h *ProxyHandler) record(resp *http.Response) error {
+	ctx := resp.Request.Context()
+	route, _ := ctx.Value(routeContextKey{}).(string)
+	method := resp.Request.Method
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxRecordBody+1))
+	if err != nil {
+		return err
+	}
+
+	if len(body) > maxRecordBody {
+		slog.WarnContext(ctx, "Not recording response larger than the limit", "method", method, "route", route, "limit", maxRecordBody)
+		// Stream the rest of the body after what has already been read
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		return nil
+	}
+
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	headers := h.recordedHeaders(resp.Header)
+	content, contentType := models.EncodeContent(body, models.ContentTypeOf(resp.Header.Get("Content-Type")))
+
+	// Only the proxy's own recording is updated; mocks created on the same route are left alone
+	existing, err := h.db.GetRecordedJSON(ctx, method, route)
+	if err != nil {
+		jsonModel := models.NewJSON(content, string(h.password))
+		jsonModel.ContentType = contentType
+		jsonModel.Method = method
+		jsonModel.Route = route
+		jsonModel.Status = resp.StatusCode
+		jsonModel.Headers = headers
+
+		err := h.db.CreateRecordedJSON(ctx, jsonModel)
+		if err == nil {
+			h.publishMock(events.Created, jsonModel)
+			slog.InfoContext(ctx, "Recorded", "method", method, "route", route, "mock_id", jsonModel.ID)
+			return nil
+		}
+		if err.Error() != "json already recorded for route" {
+			slog.ErrorContext(ctx, "Failed to record", "method", method, "route", route, "error", err)
+			return nil
+		}
+
+		// A concurrent capture of the same route created the mock first
+		if existing, err = h.db.GetRecordedJSON(ctx, method, route); err != nil {
+			slog.ErrorContext(ctx, "Failed to record", "method", method, "route", route, "error", err)
+			return nil
+		}
+	}
+
+	existing.Content = content
+	existing.ContentType = contentType
+	existing.Status = resp.StatusCode
+	existing.Headers = headers
+	// A recording that expired before cleanup removed it is recorded afresh
+	if existing.IsExpired() {
+		existing.Expires = models.DefaultExpires(time.Now())
+	}
+	if err := h.db.UpdateJSON(ctx, existing); err != nil {
+		slog.ErrorContext(ctx, "Failed to update recording", "method", method, "route", route, "error", err)
+		return nil
+	}
+	h.publishMock(events.Updated, existing)
+	return nil
+}
+
+// recordedHeaders keeps the response headers that are not excluded from recordings
+func (h *ProxyHandler) recordedHeaders(header http.Header) map[string]string {
+	headers := make(map[string]string)
+	for name, values := range header {
+		if h.excluded(name) {
+			continue
+		}
+		headers[name] = strings.Join(values, ", ")
+	}
+	return headers
+}
+
+func (h *ProxyHandler) excluded(name string) bool {
+	for _, list := range [][]string{hopByHopHeaders, h.cfg.ExcludeHeaders} {
+		for _, excluded := range list {
+			if strings.EqualFold(name, excluded) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// GetProxy handles GET /api/proxy
+func (h *ProxyHandler) GetProxy(w http.ResponseWriter, r *http.Request) {
+	h.writeJSON(w, http.StatusOK, SuccessResponse{
+		Data: ProxyStatus{Target: h.target.String(), Prefix: h.cfg.Prefix, Mode: h.Mode()},
+	})
+}
+
+// SetProxyMode handles PUT /api/proxy/mode
+func (h *ProxyHandler) SetProxyMode(w http.ResponseWriter, r *http.Request) {
+	var req ProxyModeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid_request", "Invalid JSON body")
+		return
+	}
+
+	if req.Mode != config.ProxyModeRecord && req.Mode != config.ProxyModeReplay {
+		h.writeError(w, http.StatusBadRequest, "invalid_mode", "Mode must be record or replay")
+		return
+	}
+
+	if req.Password == "" {
+		h.writeError(w, http.StatusBadRequest, "invalid_password", "Password is required")
+		return
+	}
+
+	if err := bcrypt.CompareHashAndPassword(h.password, []byte(req.Password)); err != nil {
+		h.writeError(w, http.StatusUnauthorized, "unauthorized", "Invalid password")
+		return
+	}
+
+	h.mu.Lock()
+	h.mode = req.Mode
+	h.mu.Unlock()
+
+	slog.InfoContext(r.Context(), "Proxy switched mode", "mode", req.Mode)
+
+	h.writeJSON(w, http.StatusOK, SuccessResponse{
+		Data:    ProxyStatus{Target: h.target.String(), Prefix: h.cfg.Prefix, Mode: req.Mode},
+		Message: "Proxy mode updated successfully",
+	})
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"mockj-go/internal/config"
+	"mockj-go/internal/database"
+	"mockj-go/internal/events"
+	"mockj-go/internal/models"
+)
+
+func TestProxyRecordAndReplay(t *testing.T) {
+	db, err := database.NewDatabase(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	upstreamHits := 0
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstreamHits++
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Upstream", "yes")
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"path": "` + r.URL.Path + `"}`))
+	}))
+	defer upstream.Close()
+
+	jsonHandler := NewJSONHandler(db, events.NewBus(), nil)
+	proxyHandler, err := NewProxyHandler(jsonHandler, config.ProxyConfig{
+		Target:         upstream.URL,
+		Mode:           config.ProxyModeRecord,
+		Prefix:         "/proxy",
+		Password:       "proxy123",
+		ExcludeHeaders: []string{"Date", "Set-Cookie", "Content-Length"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create proxy handler: %v", err)
+	}
+
+	t.Run("Record", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/proxy/users/42", nil)
+		w := httptest.NewRecorder()
+		proxyHandler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusAccepted || w.Body.String() != `{"path": "/users/42"}` {
+			t.Fatalf("Expected upstream response, got %d %s", w.Code, w.Body.String())
+		}
+
+		recorded, err := db.GetJSONByRoute(t.Context(), "GET", "/users/42")
+		if err != nil {
+			t.Fatalf("Expected recorded mock, got %v", err)
+		}
+		if recorded.Status != http.StatusAccepted || recorded.Headers["X-Upstream"] != "yes" {
+			t.Errorf("Unexpected recording: %+v", recorded)
+		}
+		if _, ok := recorded.Headers["Set-Cookie"]; ok {
+			t.Errorf("Expected excluded header not to be recorded")
+		}
+	})
+
+	t.Run("OneRecordingPerRoute", func(t *testing.T) {
+		duplicate := models.NewJSON(`{}`, "proxy123")
+		duplicate.Method = "GET"
+		duplicate.Route = "/users/42"
+		if err := db.CreateRecordedJSON(t.Context(), duplicate); err == nil || err.Error() != "json already recorded for route" {
+			t.Errorf("Expected a second recording of the route to be refused, got %v", err)
+		}
+		if exists, _ := db.JSONExists(t.Context(), duplicate.ID); exists {
+			t.Errorf("Expected the refused recording not to be stored")
+		}
+	})
+
+	t.Run("UserMockOnRecordedRoute", func(t *testing.T) {
+		owned := createTestJSON(t, jsonHandler, map[string]interface{}{
+			"json":     `{"mine": true}`,
+			"password": "user123",
+			"method":   "GET",
+			"route":    "/orders/1",
+		})
+
+		// The first capture creates the recording, the second updates it
+		for i := 0; i < 2; i++ {
+			req := httptest.NewRequest("GET", "/proxy/orders/1", nil)
+			proxyHandler.ServeHTTP(httptest.NewRecorder(), req)
+		}
+
+		mine, err := db.GetJSON(t.Context(), owned)
+		if err != nil {
+			t.Fatalf("Expected the user's mock to remain, got %v", err)
+		}
+		if mine.Content != `{"mine": true}` || mine.Status != 0 || len(mine.Headers) != 0 {
+			t.Errorf("Expected the user's mock to be untouched, got %+v", mine)
+		}
+
+		recorded, err := db.GetRecordedJSON(t.Context(), "GET", "/orders/1")
+		if err != nil {
+			t.Fatalf("Expected a recording next to the user's mock, got %v", err)
+		}
+		if recorded.ID == owned || recorded.Content != `{"path": "/orders/1"}` {
+			t.Errorf("Unexpected recording: %+v", recorded)
+		}
+	})
+
+	t.Run("SetModeWrongPassword", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]interface{}{"mode": "replay", "password": "wrong"})
+		req := httptest.NewRequest("PUT", "/api/proxy/mode", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		proxyHandler.SetProxyMode(w, req)
+
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status %d, got %d", http.StatusUnauthorized, w.Code)
+		}
+	})
+
+	t.Run("Replay", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]interface{}{"mode": "replay", "password": "proxy123"})
+		req := httptest.NewRequest("PUT", "/api/proxy/mode", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		proxyHandler.SetProxyMode(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+		}
+
+		hits := upstreamHits
+		req = httptest.NewRequest("GET", "/proxy/users/42", nil)
+		w = httptest.NewRecorder()
+		proxyHandler.ServeHTTP(w, req)
+
+		body, _ = io.ReadAll(w.Body)
+		if w.Code != http.StatusAccepted || string(body) != `{"path": "/users/42"}` {
+			t.Errorf("Expected replayed response, got %d %s", w.Code, body)
+		}
+		if w.Header().Get("X-Upstream") != "yes" {
+			t.Errorf("Expected recorded header to be replayed")
+		}
+		if upstreamHits != hits {
+			t.Errorf("Expected replay not to contact upstream")
+		}
+
+		// Recorded mocks are also served on their route outside the proxy prefix
+		req = httptest.NewRequest("GET", "/users/42", nil)
+		w = httptest.NewRecorder()
+		if !jsonHandler.ServeRoute(w, req) || w.Code != http.StatusAccepted {
+			t.Errorf("Expected recording to be served on its route, got %d", w.Code)
+		}
+	})
+
+	t.Run("ReplayMissing", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/proxy/unknown", nil)
+		w := httptest.NewRecorder()
+		proxyHandler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status %d, got %d", http.StatusNotFound, w.Code)
+		}
+	})
+}
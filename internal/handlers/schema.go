@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"mockj-go/internal/schema"
+)
+
+// maxSchemaSamples caps how many mocks can be merged into one inferred schema
+const maxSchemaSamples = 50
+
+// GetJSONSchema handles GET /api/json/{id}/schema - infers a JSON Schema from the
+// mock's content, merged with the mocks listed in ?merge=id1,id2
+func (h *JSONHandler) GetJSONSchema(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromPath(r.URL.Path)
+	if id == "" {
+		h.writeError(w, http.StatusBadRequest, "invalid_id", "ID is required")
+		return
+	}
+
+	samples, ok := h.loadSamples(w, r, id)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/schema+json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(schema.Infer(samples...))
+}
+
+// loadSamples decodes the content of the mock id and of those listed in ?merge=id1,id2,
+// writing an error response and returning false when one cannot be used
+func (h *JSONHandler) loadSamples(w http.ResponseWriter, r *http.Request, id string) ([]interface{}, bool) {
+	ids := []string{id}
+	if merge := r.URL.Query().Get("merge"); merge != "" {
+		for _, other := range strings.Split(merge, ",") {
+			if other = strings.TrimSpace(other); other != "" && other != id {
+				ids = append(ids, other)
+			}
+		}
+	}
+
+	if len(ids) > maxSchemaSamples {
+		h.writeError(w, http.StatusBadRequest, "invalid_merge", "Too many mocks to merge")
+		return nil, false
+	}
+
+	samples := make([]interface{}, 0, len(ids))
+	for _, sampleID := range ids {
+		jsonModel, err := h.db.GetJSON(r.Context(), sampleID)
+		if err != nil {
+			if err.Error() == "json not found or expired" {
+				h.writeError(w, http.StatusNotFound, "not_found", "JSON "+sampleID+" not found or expired")
+			} else {
+				h.writeError(w, http.StatusInternalServerError, "database_error", "Failed to retrieve JSON")
+			}
+			return nil, false
+		}
+
+		sample, err := decodeContent(jsonModel.Content)
+		if err != nil {
+			h.writeError(w, http.StatusUnprocessableEntity, "invalid_content", "JSON "+sampleID+" does not hold valid JSON")
+			return nil, false
+		}
+		samples = append(samples, sample)
+	}
+
+	return samples, true
+}
+
+// decodeContent parses stored content, keeping numbers as json.Number
+func decodeContent(content string) (interface{}, error) {
+	decoder := json.NewDecoder(strings.NewReader(content))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	// Trailing data after the first document makes the content invalid
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after JSON document")
+	}
+	return value, nil
+}
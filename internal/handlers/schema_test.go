@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"mockj-go/internal/database"
+	"mockj-go/internal/events"
+)
+
+func TestGetJSONSchema(t *testing.T) {
+	db, err := database.NewDatabase(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	handler := NewJSONHandler(db, events.NewBus(), nil)
+
+	first := createTestJSON(t, handler, map[string]interface{}{
+		"json": `{
+			"id": "6f1c2a9e-8a3b-4c1d-9e2f-0a1b2c3d4e5f",
+			"email": "john@example.com",
+			"createdAt": "2024-01-01T10:00:00Z",
+			"age": 30,
+			"tags": ["a", 1],
+			"nickname": null
+		}`,
+		"password": "test123",
+	})
+	second := createTestJSON(t, handler, map[string]interface{}{
+		"json":     `{"id": "0b0c6a55-1d2e-4f3a-8b9c-aabbccddeeff", "email": "jane@example.com", "createdAt": "2024-02-01T10:00:00Z", "age": 25.5, "nickname": "J"}`,
+		"password": "test123",
+	})
+
+	getSchema := func(t *testing.T, path string) (int, map[string]interface{}) {
+		req := httptest.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		handler.GetJSONSchema(w, req)
+
+		var schema map[string]interface{}
+		_ = json.Unmarshal(w.Body.Bytes(), &schema)
+		return w.Code, schema
+	}
+
+	t.Run("SingleSample", func(t *testing.T) {
+		code, schema := getSchema(t, "/api/json/"+first+"/schema")
+		if code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d", http.StatusOK, code)
+		}
+
+		properties := schema["properties"].(map[string]interface{})
+		formats := map[string]string{"id": "uuid", "email": "email", "createdAt": "date-time"}
+		for name, format := range formats {
+			if got := properties[name].(map[string]interface{})["format"]; got != format {
+				t.Errorf("Expected %s to have format %s, got %v", name, format, got)
+			}
+		}
+		if got := properties["age"].(map[string]interface{})["type"]; got != "integer" {
+			t.Errorf("Expected age to be an integer, got %v", got)
+		}
+
+		items := properties["tags"].(map[string]interface{})["items"].(map[string]interface{})
+		if anyOf, ok := items["anyOf"].([]interface{}); !ok || len(anyOf) != 2 {
+			t.Errorf("Expected a union of array item types, got %v", items)
+		}
+	})
+
+	t.Run("MergedSamples", func(t *testing.T) {
+		code, schema := getSchema(t, "/api/json/"+first+"/schema?merge="+second)
+		if code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d", http.StatusOK, code)
+		}
+
+		required := schema["required"].([]interface{})
+		expected := []interface{}{"age", "createdAt", "email", "id", "nickname"}
+		if !reflect.DeepEqual(required, expected) {
+			t.Errorf("Expected required %v, got %v", expected, required)
+		}
+
+		properties := schema["properties"].(map[string]interface{})
+		if got := properties["age"].(map[string]interface{})["type"]; got != "number" {
+			t.Errorf("Expected merged age to be a number, got %v", got)
+		}
+		nickname := properties["nickname"].(map[string]interface{})["type"]
+		if !reflect.DeepEqual(nickname, []interface{}{"string", "null"}) {
+			t.Errorf("Expected nullable string nickname, got %v", nickname)
+		}
+	})
+
+	t.Run("MergeMissing", func(t *testing.T) {
+		if code, _ := getSchema(t, "/api/json/"+first+"/schema?merge=missing"); code != http.StatusNotFound {
+			t.Errorf("Expected status %d, got %d", http.StatusNotFound, code)
+		}
+	})
+}
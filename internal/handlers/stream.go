@@ -0,0 +1,230 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"mockj-go/internal/config"
+	"mockj-go/internal/models"
+)
+
+// Stream pacing bounds
+const (
+	defaultStreamInterval = time.Second
+	minStreamInterval     = 10 * time.Millisecond
+)
+
+// StreamHandler streams the elements of array mocks as Server-Sent Events
+type StreamHandler struct {
+	*JSONHandler
+	cfg config.ServerConfig
+}
+
+// StreamOptions are the query parameters of GET /api/json/{id}/stream
+type StreamOptions struct {
+	Interval time.Duration
+	Event    string
+	Repeat   int  // Passes over the array
+	Loop     bool // Repeat until the client disconnects
+}
+
+func NewStreamHandler(jsonHandler *JSONHandler, cfg config.ServerConfig) *StreamHandler {
+	return &StreamHandler{
+		JSONHandler: jsonHandler,
+		cfg:         cfg,
+	}
+}
+
+// StreamJSON handles GET /api/json/{id}/stream - sends each element of the mock's array as an
+// event, every ?interval=, named ?event=, for ?repeat= passes or forever with ?loop=true.
+// Event IDs count the events sent, so Last-Event-ID resumes after the last one received, and
+// a finite stream answers 204 No Content once it has nothing left to send.
+func (h *StreamHandler) StreamJSON(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromPath(r.URL.Path)
+	if id == "" {
+		h.writeError(w, http.StatusBadRequest, "invalid_id", "ID is required")
+		return
+	}
+
+	opts, err := parseStreamOptions(r)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid_stream", err.Error())
+		return
+	}
+
+	next := 0
+	if lastEventID := r.Header.Get("Last-Event-ID"); lastEventID != "" {
+		last, err := strconv.Atoi(lastEventID)
+		if err != nil || last < 0 {
+			h.writeError(w, http.StatusBadRequest, "invalid_stream", "Last-Event-ID must be an event ID of this stream")
+			return
+		}
+		next = last + 1
+	}
+
+	jsonModel, err := h.db.GetJSON(r.Context(), id)
+	if err != nil {
+		if err.Error() == "json not found or expired" {
+			h.writeError(w, http.StatusNotFound, "not_found", "JSON not found or expired")
+		} else {
+			h.writeError(w, http.StatusInternalServerError, "database_error", "Failed to retrieve JSON")
+		}
+		return
+	}
+
+	w, record := h.startJournal(w, r, id)
+	defer record()
+
+	var items []json.RawMessage
+	if (jsonModel.ContentType != "" && jsonModel.ContentType != models.ContentTypeJSON) ||
+		json.Unmarshal([]byte(jsonModel.Content), &items) != nil {
+		h.writeError(w, http.StatusUnprocessableEntity, "invalid_content", "Content must be a JSON array")
+		return
+	}
+
+	total := len(items) * opts.Repeat
+	if opts.Loop {
+		total = -1
+	}
+	// A client that already received every event is told not to reconnect
+	if total >= 0 && next >= total {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	stream := newEventStream(w, h.cfg.WriteTimeout)
+	if err := stream.start(); err != nil {
+		return
+	}
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+
+	for ; len(items) > 0 && (total < 0 || next < total); next++ {
+		var data bytes.Buffer
+		_ = json.Compact(&data, items[next%len(items)])
+		if err := stream.send(strconv.Itoa(next), opts.Event, data.String()); err != nil {
+			return
+		}
+
+		if total >= 0 && next+1 == total {
+			return
+		}
+		select {
+		case <-ticker.C:
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func parseStreamOptions(r *http.Request) (StreamOptions, error) {
+	query := r.URL.Query()
+	opts := StreamOptions{
+		Interval: defaultStreamInterval,
+		Event:    query.Get("event"),
+		Repeat:   1,
+		Loop:     query.Get("loop") == "true",
+	}
+
+	if interval := query.Get("interval"); interval != "" {
+		d, err := time.ParseDuration(interval)
+		if err != nil || d < minStreamInterval {
+			return opts, fmt.Errorf("interval must be a duration of at least %s", minStreamInterval)
+		}
+		opts.Interval = d
+	}
+
+	if repeat := query.Get("repeat"); repeat != "" {
+		n, err := strconv.Atoi(repeat)
+		if err != nil || n < 1 {
+			return opts, fmt.Errorf("repeat must be a positive integer")
+		}
+		opts.Repeat = n
+	}
+
+	if strings.ContainsAny(opts.Event, "\r\n") {
+		return opts, fmt.Errorf("event must be a single line")
+	}
+
+	return opts, nil
+}
+
+// eventStream writes Server-Sent Events. Each event gets a fresh write deadline, so streams
+// outlive the server's write timeout while clients that stop reading are still cut off.
+type eventStream struct {
+	w            http.ResponseWriter
+	rc           *http.ResponseController
+	writeTimeout time.Duration
+	started      bool
+}
+
+func newEventStream(w http.ResponseWriter, writeTimeout time.Duration) *eventStream {
+	return &eventStream{w: w, rc: http.NewResponseController(w), writeTimeout: writeTimeout}
+}
+
+// start sends the response headers, once
+func (s *eventStream) start() error {
+	if s.started {
+		return nil
+	}
+	s.started = true
+
+	s.extendDeadline()
+	s.w.Header().Set("Content-Type", "text/event-stream")
+	s.w.Header().Set("Cache-Control", "no-cache")
+	// Keep reverse proxies such as nginx from buffering the stream
+	s.w.Header().Set("X-Accel-Buffering", "no")
+	s.w.WriteHeader(http.StatusOK)
+	return s.rc.Flush()
+}
+
+// send writes one event; empty id and name fields are left out
+func (s *eventStream) send(id, name, data string) error {
+	if err := s.start(); err != nil {
+		return err
+	}
+
+	var event strings.Builder
+	if id != "" {
+		fmt.Fprintf(&event, "id: %s\n", id)
+	}
+	if name != "" {
+		fmt.Fprintf(&event, "event: %s\n", name)
+	}
+	for _, line := range strings.Split(data, "\n") {
+		fmt.Fprintf(&event, "data: %s\n", line)
+	}
+	event.WriteString("\n")
+
+	s.extendDeadline()
+	if _, err := s.w.Write([]byte(event.String())); err != nil {
+		return err
+	}
+	return s.rc.Flush()
+}
+
+// comment writes a comment line, which clients ignore, to keep idle connections open
+func (s *eventStream) comment(text string) error {
+	if err := s.start(); err != nil {
+		return err
+	}
+	s.extendDeadline()
+	if _, err := fmt.Fprintf(s.w, ": %s\n\n", text); err != nil {
+		return err
+	}
+	return s.rc.Flush()
+}
+
+func (s *eventStream) extendDeadline() {
+	deadline := time.Time{}
+	if s.writeTimeout > 0 {
+		deadline = time.Now().Add(s.writeTimeout)
+	}
+	// Writers that cannot set deadlines, such as test recorders, stream without one
+	_ = s.rc.SetWriteDeadline(deadline)
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"mockj-go/internal/config"
+	"mockj-go/internal/database"
+	"mockj-go/internal/events"
+)
+
+func TestStreamJSON(t *testing.T) {
+	db, err := database.NewDatabase(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	handler := NewStreamHandler(NewJSONHandler(db, events.NewBus(), nil), config.ServerConfig{WriteTimeout: 50 * time.Millisecond})
+
+	id := createTestJSON(t, handler.JSONHandler, map[string]interface{}{
+		"json":     `[{"n": 1}, {"n": 2}, "multi\nline"]`,
+		"password": "test123",
+	})
+
+	stream := func(t *testing.T, query string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/json/"+id+"/stream"+query, nil)
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		w := httptest.NewRecorder()
+		handler.StreamJSON(w, req)
+		return w
+	}
+
+	t.Run("EventsInOrder", func(t *testing.T) {
+		w := stream(t, "?interval=10ms&event=item", nil)
+		if got := w.Header().Get("Content-Type"); got != "text/event-stream" {
+			t.Errorf("Expected text/event-stream, got %q", got)
+		}
+		expected := "id: 0\nevent: item\ndata: {\"n\":1}\n\n" +
+			"id: 1\nevent: item\ndata: {\"n\":2}\n\n" +
+			"id: 2\nevent: item\ndata: \"multi\\nline\"\n\n"
+		if w.Body.String() != expected {
+			t.Errorf("Expected %q, got %q", expected, w.Body.String())
+		}
+	})
+
+	t.Run("RepeatAndResume", func(t *testing.T) {
+		w := stream(t, "?interval=10ms&repeat=2", http.Header{"Last-Event-Id": {"3"}})
+		expected := "id: 4\ndata: {\"n\":2}\n\nid: 5\ndata: \"multi\\nline\"\n\n"
+		if w.Body.String() != expected {
+			t.Errorf("Expected %q, got %q", expected, w.Body.String())
+		}
+	})
+
+	t.Run("ResumeAtEnd", func(t *testing.T) {
+		for _, last := range []string{"5", "9"} {
+			w := stream(t, "?interval=10ms&repeat=2", http.Header{"Last-Event-Id": {last}})
+			if w.Code != http.StatusNoContent || w.Body.Len() != 0 {
+				t.Errorf("Last-Event-ID %s: expected status %d and no body, got %d %q", last, http.StatusNoContent, w.Code, w.Body.String())
+			}
+		}
+	})
+
+	t.Run("LoopUntilDisconnect", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		req := httptest.NewRequest("GET", "/api/json/"+id+"/stream?interval=10ms&loop=true", nil).WithContext(ctx)
+		w := httptest.NewRecorder()
+		handler.StreamJSON(w, req)
+
+		if events := strings.Count(w.Body.String(), "\n\n"); events <= 3 {
+			t.Errorf("Expected the array to loop, got %d events", events)
+		}
+	})
+
+	t.Run("OutlivesWriteTimeout", func(t *testing.T) {
+		server := httptest.NewUnstartedServer(http.HandlerFunc(handler.StreamJSON))
+		server.Config.WriteTimeout = handler.cfg.WriteTimeout
+		server.Start()
+		defer server.Close()
+
+		resp, err := http.Get(server.URL + "/api/json/" + id + "/stream?interval=30ms&repeat=2")
+		if err != nil {
+			t.Fatalf("Failed to open stream: %v", err)
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("Stream was cut off after %q: %v", body, err)
+		}
+		if events := strings.Count(string(body), "\n\n"); events != 6 {
+			t.Errorf("Expected 6 events, got %d", events)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		object := createTestJSON(t, handler.JSONHandler, map[string]interface{}{"json": `{"a": 1}`, "password": "test123"})
+
+		tests := []struct {
+			name   string
+			path   string
+			header http.Header
+			status int
+		}{
+			{"NotAnArray", "/api/json/" + object + "/stream", nil, http.StatusUnprocessableEntity},
+			{"InvalidInterval", "/api/json/" + id + "/stream?interval=1ms", nil, http.StatusBadRequest},
+			{"InvalidRepeat", "/api/json/" + id + "/stream?repeat=0", nil, http.StatusBadRequest},
+			{"InvalidLastEventID", "/api/json/" + id + "/stream", http.Header{"Last-Event-Id": {"x"}}, http.StatusBadRequest},
+			{"NotFound", "/api/json/missing/stream", nil, http.StatusNotFound},
+		}
+		for _, tt := range tests {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			for name, values := range tt.header {
+				req.Header[name] = values
+			}
+			w := httptest.NewRecorder()
+			handler.StreamJSON(w, req)
+			if w.Code != tt.status {
+				t.Errorf("%s: expected status %d, got %d", tt.name, tt.status, w.Code)
+			}
+		}
+	})
+}
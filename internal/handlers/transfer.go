@@ -0,0 +1,538 @@
+package handlers
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"mockj-go/internal/config"
+	"mockj-go/internal/database"
+	"mockj-go/internal/events"
+	"mockj-go/internal/models"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Archive formats of GET /api/export and POST /api/import
+const (
+	exportFormatNDJSON = "ndjson"
+	exportFormatTar    = "tar"
+
+	ndjsonContentType = "application/x-ndjson"
+	tarContentType    = "application/x-tar"
+
+	// exportManifest names the manifest entry of tar archives
+	exportManifest = "manifest.json"
+	exportVersion  = 1
+)
+
+// Conflict strategies for mocks whose ID is already stored
+const (
+	ImportSkip      = "skip"
+	ImportOverwrite = "overwrite"
+	ImportRename    = "rename"
+)
+
+// transferPasswordHeader carries the transfer password, as GET /api/export has no body
+const transferPasswordHeader = "X-Transfer-Password"
+
+// TransferHandler exports every stored mock to an archive and restores such archives
+type TransferHandler struct {
+	*JSONHandler
+	cfg      config.TransferConfig
+	password []byte
+}
+
+// ExportedMock is one mock of an export archive. It carries the password hash so
+// restored mocks keep their password. In tar archives the content lives in File.
+type ExportedMock struct {
+	ID            string                  `json:"id"`
+	Content       string                  `json:"json,omitempty"`
+	ContentType   string                  `json:"contentType,omitempty"`
+	File          string                  `json:"file,omitempty"`
+	PasswordHash  string                  `json:"passwordHash"`
+	CreatedAt     time.Time               `json:"createdAt"`
+	ModifiedAt    time.Time               `json:"modifiedAt"`
+	Expires       time.Time               `json:"expires"`
+	Fault         *models.Fault           `json:"fault,omitempty"`
+	Method        string                  `json:"method,omitempty"`
+	Route         string                  `json:"route,omitempty"`
+	Status        int                     `json:"status,omitempty"`
+	Headers       map[string]string       `json:"headers,omitempty"`
+	GraphQLSchema string                  `json:"graphqlSchema,omitempty"`
+	WebSocket     *models.WebSocketScript `json:"websocket,omitempty"`
+	Callbacks     []models.Callback       `json:"callbacks,omitempty"`
+	CORS          *models.CORS            `json:"cors,omitempty"`
+}
+
+// ExportManifest lists the mocks of a tar archive
+type ExportManifest struct {
+	Version    int            `json:"version"`
+	ExportedAt time.Time      `json:"exportedAt"`
+	Mocks      []ExportedMock `json:"mocks"`
+}
+
+// ImportedRecord describes a restored mock
+type ImportedRecord struct {
+	ID         string `json:"id"`
+	OriginalID string `json:"originalId,omitempty"` // Set when the mock was renamed
+	Replaced   bool   `json:"replaced,omitempty"`   // Set when an existing mock was overwritten
+}
+
+// SkippedRecord describes a mock of the archive that was not restored
+type SkippedRecord struct {
+	ID     string `json:"id"`
+	Reason string `json:"reason"`
+}
+
+// ImportResult reports the outcome of POST /api/import
+type ImportResult struct {
+	Imported []ImportedRecord `json:"imported"`
+	Skipped  []SkippedRecord  `json:"skipped"`
+}
+
+func NewTransferHandler(jsonHandler *JSONHandler, cfg config.TransferConfig) (*TransferHandler, error) {
+	password, err := bcrypt.GenerateFromPassword([]byte(cfg.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash transfer password: %w", err)
+	}
+
+	return &TransferHandler{
+		JSONHandler: jsonHandler,
+		cfg:         cfg,
+		password:    password,
+	}, nil
+}
+
+// Export handles GET /api/export?format=ndjson|tar
+func (h *TransferHandler) Export(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeTransfer(w, r) {
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = exportFormatNDJSON
+	}
+	if format != exportFormatNDJSON && format != exportFormatTar {
+		h.writeError(w, http.StatusBadRequest, "invalid_format", "Format must be ndjson or tar")
+		return
+	}
+
+	jsons, err := h.db.ListJSON(r.Context())
+	if err != nil {
+		h.writeError(w, http.StatusInternalServerError, "database_error", "Failed to list JSON")
+		return
+	}
+
+	mocks := make([]ExportedMock, len(jsons))
+	for i, jsonModel := range jsons {
+		mocks[i] = exportMock(jsonModel)
+	}
+
+	// Archives are built in memory so failures still produce a proper error response
+	var buf bytes.Buffer
+	contentType := ndjsonContentType
+	if format == exportFormatTar {
+		contentType = tarContentType
+		err = writeTarArchive(&buf, mocks)
+	} else {
+		err = writeNDJSONArchive(&buf, mocks)
+	}
+	if err != nil {
+		h.writeError(w, http.StatusInternalServerError, "export_error", "Failed to write archive")
+		return
+	}
+
+	filename := fmt.Sprintf("mockj-export-%s.%s", time.Now().UTC().Format("20060102-150405"), format)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(buf.Bytes())
+}
+
+// Import handles POST /api/import?strategy=skip|overwrite|rename. The archive format
+// follows the Content-Type. Either every mock is restored or none is.
+func (h *TransferHandler) Import(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeTransfer(w, r) {
+		return
+	}
+
+	strategy := r.URL.Query().Get("strategy")
+	if strategy == "" {
+		strategy = ImportSkip
+	}
+	if strategy != ImportSkip && strategy != ImportOverwrite && strategy != ImportRename {
+		h.writeError(w, http.StatusBadRequest, "invalid_strategy", "Strategy must be skip, overwrite or rename")
+		return
+	}
+
+	body := r.Body
+	if h.cfg.MaxImportSize > 0 {
+		body = http.MaxBytesReader(w, r.Body, h.cfg.MaxImportSize)
+	}
+
+	var (
+		mocks []ExportedMock
+		err   error
+	)
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case ndjsonContentType:
+		mocks, err = readNDJSONArchive(body)
+	case tarContentType:
+		mocks, err = readTarArchive(body)
+	default:
+		h.writeError(w, http.StatusUnsupportedMediaType, "invalid_content_type", "Content-Type must be "+ndjsonContentType+" or "+tarContentType)
+		return
+	}
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			h.writeError(w, http.StatusRequestEntityTooLarge, "too_large", "Archive exceeds the import size limit")
+			return
+		}
+		h.writeError(w, http.StatusBadRequest, "invalid_archive", err.Error())
+		return
+	}
+
+	jsons := make([]*models.JSON, len(mocks))
+	for i, mock := range mocks {
+		if jsons[i], err = importMock(mock); err != nil {
+			h.writeError(w, http.StatusBadRequest, "invalid_archive", fmt.Sprintf("Mock %d (%s): %v", i+1, mock.ID, err))
+			return
+		}
+	}
+
+	result := ImportResult{Imported: []ImportedRecord{}, Skipped: []SkippedRecord{}}
+	err = h.db.RunInTx(r.Context(), func(tx *database.Database) error {
+		for _, jsonModel := range jsons {
+			if jsonModel.IsExpired() {
+				result.Skipped = append(result.Skipped, SkippedRecord{ID: jsonModel.ID, Reason: "expired"})
+				continue
+			}
+
+			exists, err := tx.JSONExists(r.Context(), jsonModel.ID)
+			if err != nil {
+				return err
+			}
+
+			record := ImportedRecord{ID: jsonModel.ID}
+			if exists {
+				switch strategy {
+				case ImportSkip:
+					result.Skipped = append(result.Skipped, SkippedRecord{ID: jsonModel.ID, Reason: "already exists"})
+					continue
+				case ImportOverwrite:
+					// Archives do not carry attachments, which deleting the mock would lose
+					attachments, err := tx.ListAttachments(r.Context(), jsonModel.ID)
+					if err != nil {
+						return err
+					}
+					if len(attachments) > 0 {
+						result.Skipped = append(result.Skipped, SkippedRecord{ID: jsonModel.ID, Reason: "has attachments"})
+						continue
+					}
+					// Deleting and recreating keeps the archived timestamps, unlike UpdateJSON
+					if err := tx.DeleteJSON(r.Context(), jsonModel.ID); err != nil {
+						return err
+					}
+					record.Replaced = true
+				case ImportRename:
+					record.OriginalID = jsonModel.ID
+					jsonModel.ID = uuid.New().String()
+					record.ID = jsonModel.ID
+				}
+			}
+
+			if err := tx.CreateJSON(r.Context(), jsonModel); err != nil {
+				return fmt.Errorf("failed to create json %s: %w", jsonModel.ID, err)
+			}
+			result.Imported = append(result.Imported, record)
+		}
+		return nil
+	})
+	if err != nil {
+		h.writeError(w, http.StatusInternalServerError, "database_error", "Failed to import mocks")
+		return
+	}
+
+	imported := map[string]bool{}
+	for _, record := range result.Imported {
+		imported[record.ID] = record.Replaced
+	}
+	for _, jsonModel := range jsons {
+		if replaced, ok := imported[jsonModel.ID]; ok {
+			if replaced {
+				h.publishMock(events.Updated, jsonModel)
+			} else {
+				h.publishMock(events.Created, jsonModel)
+			}
+		}
+	}
+
+	h.writeJSON(w, http.StatusOK, SuccessResponse{
+		Data:    result,
+		Message: fmt.Sprintf("Imported %d mocks, skipped %d", len(result.Imported), len(result.Skipped)),
+	})
+}
+
+// CreateWebhook handles POST /api/webhooks like JSONHandler.CreateWebhook, and also accepts
+// subscriptions to every mock. Those reveal as much as /api/events, so they take the transfer
+// password in the X-Transfer-Password header, which also deletes them.
+func (h *TransferHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	h.createWebhook(w, r, func(w http.ResponseWriter, r *http.Request) (string, bool) {
+		return string(h.password), h.authorizeTransfer(w, r)
+	})
+}
+
+// RequireTransferPassword guards a handler that reveals every mock, such as the stream of
+// all changes, with the transfer password
+func (h *TransferHandler) RequireTransferPassword(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if h.authorizeTransfer(w, r) {
+			next(w, r)
+		}
+	}
+}
+
+// authorizeTransfer checks the transfer password header, writing the error response itself
+func (h *TransferHandler) authorizeTransfer(w http.ResponseWriter, r *http.Request) bool {
+	password := r.Header.Get(transferPasswordHeader)
+	if password == "" {
+		h.writeError(w, http.StatusBadRequest, "invalid_password", transferPasswordHeader+" header is required")
+		return false
+	}
+
+	if err := bcrypt.CompareHashAndPassword(h.password, []byte(password)); err != nil {
+		h.writeError(w, http.StatusUnauthorized, "unauthorized", "Invalid password")
+		return false
+	}
+
+	return true
+}
+
+func exportMock(jsonModel *models.JSON) ExportedMock {
+	return ExportedMock{
+		ID:            jsonModel.ID,
+		Content:       jsonModel.Content,
+		ContentType:   jsonModel.ContentType,
+		PasswordHash:  jsonModel.Password,
+		CreatedAt:     jsonModel.CreatedAt,
+		ModifiedAt:    jsonModel.ModifiedAt,
+		Expires:       jsonModel.Expires,
+		Fault:         jsonModel.Fault,
+		Method:        jsonModel.Method,
+		Route:         jsonModel.Route,
+		Status:        jsonModel.Status,
+		Headers:       jsonModel.Headers,
+		GraphQLSchema: jsonModel.GraphQLSchema,
+		WebSocket:     jsonModel.WebSocket,
+		Callbacks:     jsonModel.Callbacks,
+		CORS:          jsonModel.CORS,
+	}
+}
+
+// importMock validates an archived mock the way CreateJSON validates a new one
+func importMock(mock ExportedMock) (*models.JSON, error) {
+	if mock.ID == "" {
+		return nil, fmt.Errorf("id is required")
+	}
+	// Content may be empty, as for the 204 responses of imported OpenAPI specs
+	// Archives from before content types were stored unchecked, so only typed content is validated
+	if mock.ContentType != "" {
+		if err := models.ValidateContent(mock.ContentType, mock.Content); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := bcrypt.Cost([]byte(mock.PasswordHash)); err != nil {
+		return nil, fmt.Errorf("passwordHash is not a bcrypt hash")
+	}
+	if mock.Fault != nil {
+		if err := mock.Fault.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	if mock.WebSocket != nil {
+		if err := mock.WebSocket.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	if err := models.ValidateCallbacks(mock.Callbacks); err != nil {
+		return nil, err
+	}
+	if mock.CORS != nil {
+		if err := mock.CORS.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	method := strings.ToUpper(mock.Method)
+	if err := validateBinding(method, mock.Route, mock.Status); err != nil {
+		return nil, err
+	}
+
+	jsonModel := &models.JSON{
+		ID:            mock.ID,
+		Content:       mock.Content,
+		ContentType:   mock.ContentType,
+		Password:      mock.PasswordHash,
+		CreatedAt:     mock.CreatedAt,
+		ModifiedAt:    mock.ModifiedAt,
+		Expires:       mock.Expires,
+		Fault:         mock.Fault,
+		Method:        method,
+		Route:         mock.Route,
+		Status:        mock.Status,
+		Headers:       mock.Headers,
+		GraphQLSchema: mock.GraphQLSchema,
+		WebSocket:     mock.WebSocket,
+		Callbacks:     mock.Callbacks,
+		CORS:          mock.CORS,
+	}
+	if err := validateGraphQL(jsonModel); err != nil {
+		return nil, err
+	}
+	return jsonModel, nil
+}
+
+// writeNDJSONArchive writes one mock per line
+func writeNDJSONArchive(w io.Writer, mocks []ExportedMock) error {
+	encoder := json.NewEncoder(w)
+	for _, mock := range mocks {
+		if err := encoder.Encode(mock); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readNDJSONArchive(r io.Reader) ([]ExportedMock, error) {
+	var mocks []ExportedMock
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64<<10), maxRecordBody)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var mock ExportedMock
+		if err := json.Unmarshal(scanner.Bytes(), &mock); err != nil {
+			return nil, fmt.Errorf("line %d is not a valid mock: %v", line, err)
+		}
+		mocks = append(mocks, mock)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return mocks, nil
+}
+
+// writeTarArchive writes the manifest followed by one file per mock
+func writeTarArchive(w io.Writer, mocks []ExportedMock) error {
+	manifest := ExportManifest{
+		Version:    exportVersion,
+		ExportedAt: time.Now(),
+		Mocks:      make([]ExportedMock, len(mocks)),
+	}
+	for i, mock := range mocks {
+		mock.File = "mocks/" + mock.ID + ".json"
+		mock.Content = ""
+		manifest.Mocks[i] = mock
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tw := tar.NewWriter(w)
+	writeFile := func(name string, content []byte, modTime time.Time) error {
+		header := &tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), ModTime: modTime}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		_, err := tw.Write(content)
+		return err
+	}
+
+	if err := writeFile(exportManifest, data, manifest.ExportedAt); err != nil {
+		return err
+	}
+	for i, mock := range mocks {
+		if err := writeFile(manifest.Mocks[i].File, []byte(mock.Content), mock.ModifiedAt); err != nil {
+			return err
+		}
+	}
+
+	return tw.Close()
+}
+
+func readTarArchive(r io.Reader) ([]ExportedMock, error) {
+	var (
+		manifest *ExportManifest
+		files    = map[string]string{}
+	)
+
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+
+		name := path.Clean(header.Name)
+		if name == exportManifest {
+			manifest = &ExportManifest{}
+			if err := json.Unmarshal(content, manifest); err != nil {
+				return nil, fmt.Errorf("invalid %s: %v", exportManifest, err)
+			}
+			continue
+		}
+		files[name] = string(content)
+	}
+
+	if manifest == nil {
+		return nil, fmt.Errorf("archive has no %s", exportManifest)
+	}
+	if manifest.Version != exportVersion {
+		return nil, fmt.Errorf("unsupported archive version %d", manifest.Version)
+	}
+
+	for i, mock := range manifest.Mocks {
+		if mock.File == "" {
+			continue
+		}
+		content, ok := files[path.Clean(mock.File)]
+		if !ok {
+			return nil, fmt.Errorf("archive has no file %s for mock %s", mock.File, mock.ID)
+		}
+		manifest.Mocks[i].Content = content
+	}
+
+	return manifest.Mocks, nil
+}
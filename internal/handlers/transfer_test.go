@@ -0,0 +1,261 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"mockj-go/internal/config"
+	"mockj-go/internal/database"
+	"mockj-go/internal/events"
+	"mockj-go/internal/models"
+)
+
+func TestExportImport(t *testing.T) {
+	source, err := database.NewDatabase(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer source.Close()
+
+	sourceHandler, err := NewTransferHandler(NewJSONHandler(source, events.NewBus(), nil), config.TransferConfig{Password: "transfer123"})
+	if err != nil {
+		t.Fatalf("Failed to create transfer handler: %v", err)
+	}
+
+	first := createTestJSON(t, sourceHandler.JSONHandler, map[string]interface{}{
+		"json":     `{"name": "John"}`,
+		"password": "test123",
+		"method":   "GET",
+		"route":    "/users/{id}",
+		"headers":  map[string]string{"X-Test": "yes"},
+	})
+	second := createTestJSON(t, sourceHandler.JSONHandler, map[string]interface{}{
+		"json":     `[1, 2, 3]`,
+		"password": "other456",
+	})
+
+	export := func(t *testing.T, format string) []byte {
+		req := httptest.NewRequest("GET", "/api/export?format="+format, nil)
+		req.Header.Set(transferPasswordHeader, "transfer123")
+		w := httptest.NewRecorder()
+		sourceHandler.Export(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		return w.Body.Bytes()
+	}
+
+	importInto := func(t *testing.T, handler *TransferHandler, contentType, strategy string, archive []byte) (int, ImportResult) {
+		req := httptest.NewRequest("POST", "/api/import?strategy="+strategy, bytes.NewReader(archive))
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set(transferPasswordHeader, "transfer123")
+		w := httptest.NewRecorder()
+		handler.Import(w, req)
+
+		var response struct {
+			Data ImportResult `json:"data"`
+		}
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		return w.Code, response.Data
+	}
+
+	newTarget := func(t *testing.T) (*database.Database, *TransferHandler) {
+		db, err := database.NewDatabase(":memory:")
+		if err != nil {
+			t.Fatalf("Failed to create test database: %v", err)
+		}
+		t.Cleanup(func() { db.Close() })
+
+		handler, err := NewTransferHandler(NewJSONHandler(db, events.NewBus(), nil), config.TransferConfig{Password: "transfer123"})
+		if err != nil {
+			t.Fatalf("Failed to create transfer handler: %v", err)
+		}
+		return db, handler
+	}
+
+	t.Run("RequiresPassword", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/export", nil)
+		req.Header.Set(transferPasswordHeader, "wrong")
+		w := httptest.NewRecorder()
+		sourceHandler.Export(w, req)
+
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status %d, got %d", http.StatusUnauthorized, w.Code)
+		}
+
+		served := false
+		guarded := sourceHandler.RequireTransferPassword(func(w http.ResponseWriter, r *http.Request) { served = true })
+		for password, status := range map[string]int{"": http.StatusBadRequest, "wrong": http.StatusUnauthorized} {
+			req := httptest.NewRequest("GET", "/api/events", nil)
+			req.Header.Set(transferPasswordHeader, password)
+			w := httptest.NewRecorder()
+			guarded(w, req)
+			if w.Code != status || served {
+				t.Errorf("%q: expected status %d without serving, got %d", password, status, w.Code)
+			}
+		}
+		req = httptest.NewRequest("GET", "/api/events", nil)
+		req.Header.Set(transferPasswordHeader, "transfer123")
+		guarded(httptest.NewRecorder(), req)
+		if !served {
+			t.Error("Expected the guarded handler to serve with the transfer password")
+		}
+	})
+
+	t.Run("NDJSONRoundTrip", func(t *testing.T) {
+		archive := export(t, "ndjson")
+		if lines := strings.Count(string(archive), "\n"); lines != 2 {
+			t.Fatalf("Expected 2 lines, got %d: %s", lines, archive)
+		}
+
+		db, handler := newTarget(t)
+		code, result := importInto(t, handler, "application/x-ndjson", "", archive)
+		if code != http.StatusOK || len(result.Imported) != 2 {
+			t.Fatalf("Expected 2 imported mocks, got %d %+v", code, result)
+		}
+
+		restored, err := db.GetJSONByRoute(t.Context(), "GET", "/users/{id}")
+		if err != nil || restored.ID != first || restored.Headers["X-Test"] != "yes" {
+			t.Fatalf("Expected restored route binding, got %+v %v", restored, err)
+		}
+
+		// The archived password hash still authorizes updates
+		body, _ := json.Marshal(map[string]interface{}{"json": `{"name": "Jane"}`, "password": "test123"})
+		req := httptest.NewRequest("PUT", "/api/json/"+first, bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		handler.UpdateJSON(w, req)
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status %d with the original password, got %d", http.StatusOK, w.Code)
+		}
+
+		// Importing again skips the existing mocks by default
+		code, result = importInto(t, handler, "application/x-ndjson", "", archive)
+		if code != http.StatusOK || len(result.Imported) != 0 || len(result.Skipped) != 2 {
+			t.Errorf("Expected 2 skipped mocks, got %d %+v", code, result)
+		}
+
+		code, result = importInto(t, handler, "application/x-ndjson", ImportOverwrite, archive)
+		if code != http.StatusOK || len(result.Imported) != 2 || !result.Imported[0].Replaced {
+			t.Fatalf("Expected 2 overwritten mocks, got %d %+v", code, result)
+		}
+		if restored, _ := db.GetJSON(t.Context(), first); restored.Content != `{"name": "John"}` {
+			t.Errorf("Expected overwritten content, got %s", restored.Content)
+		}
+	})
+
+	t.Run("TarRoundTripWithRename", func(t *testing.T) {
+		archive := export(t, "tar")
+
+		db, handler := newTarget(t)
+		if code, _ := importInto(t, handler, "application/x-tar", "", archive); code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d", http.StatusOK, code)
+		}
+
+		code, result := importInto(t, handler, "application/x-tar", ImportRename, archive)
+		if code != http.StatusOK || len(result.Imported) != 2 {
+			t.Fatalf("Expected 2 renamed mocks, got %d %+v", code, result)
+		}
+		for _, record := range result.Imported {
+			if record.OriginalID != first && record.OriginalID != second {
+				t.Errorf("Expected an original ID, got %+v", record)
+			}
+
+			renamed, err := db.GetJSON(t.Context(), record.ID)
+			if err != nil {
+				t.Fatalf("Expected renamed mock %s, got %v", record.ID, err)
+			}
+			original, _ := db.GetJSON(t.Context(), record.OriginalID)
+			if renamed.Content != original.Content {
+				t.Errorf("Expected renamed content %s, got %s", original.Content, renamed.Content)
+			}
+		}
+	})
+
+	t.Run("OverwriteKeepsMocksWithAttachments", func(t *testing.T) {
+		archive := export(t, "ndjson")
+
+		db, handler := newTarget(t)
+		if code, _ := importInto(t, handler, "application/x-ndjson", "", archive); code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d", http.StatusOK, code)
+		}
+		attachment := models.NewAttachment(first, "notes.txt", "text/plain", 2, "")
+		if err := db.CreateAttachment(t.Context(), attachment, []byte("hi"), 0); err != nil {
+			t.Fatalf("Failed to create attachment: %v", err)
+		}
+
+		code, result := importInto(t, handler, "application/x-ndjson", ImportOverwrite, archive)
+		if code != http.StatusOK || len(result.Imported) != 1 || len(result.Skipped) != 1 || result.Skipped[0].ID != first {
+			t.Fatalf("Expected the mock with attachments to be skipped, got %d %+v", code, result)
+		}
+		if _, err := db.GetAttachment(t.Context(), first, attachment.ID); err != nil {
+			t.Errorf("Expected the attachment to remain, got %v", err)
+		}
+	})
+
+	t.Run("EmptyContentRoundTrip", func(t *testing.T) {
+		// OpenAPI imports store no content for 204 responses
+		emptySource, emptyHandler := newTarget(t)
+		spec := map[string]interface{}{
+			"openapi": "3.0.0",
+			"info":    map[string]interface{}{"title": "Items", "version": "1"},
+			"paths": map[string]interface{}{
+				"/items/{id}": map[string]interface{}{
+					"delete": map[string]interface{}{
+						"responses": map[string]interface{}{"204": map[string]interface{}{"description": "Deleted"}},
+					},
+				},
+			},
+		}
+		body, _ := json.Marshal(map[string]interface{}{"spec": spec, "password": "test123"})
+		w := httptest.NewRecorder()
+		emptyHandler.ImportOpenAPI(w, httptest.NewRequest("POST", "/api/import/openapi", bytes.NewReader(body)))
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected status %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+		}
+		imported, err := emptySource.GetJSONByRoute(t.Context(), "DELETE", "/items/{id}")
+		if err != nil || imported.Content != "" {
+			t.Fatalf("Expected an empty 204 mock, got %+v %v", imported, err)
+		}
+
+		for _, format := range []string{"ndjson", "tar"} {
+			req := httptest.NewRequest("GET", "/api/export?format="+format, nil)
+			req.Header.Set(transferPasswordHeader, "transfer123")
+			w := httptest.NewRecorder()
+			emptyHandler.Export(w, req)
+
+			db, handler := newTarget(t)
+			code, result := importInto(t, handler, "application/x-"+format, "", w.Body.Bytes())
+			if code != http.StatusOK || len(result.Imported) != 1 {
+				t.Fatalf("%s: expected the 204 mock to import, got %d %+v", format, code, result)
+			}
+			restored, err := db.GetJSON(t.Context(), imported.ID)
+			if err != nil || restored.Content != "" || restored.Status != http.StatusNoContent {
+				t.Errorf("%s: expected the restored 204 mock, got %+v %v", format, restored, err)
+			}
+		}
+	})
+
+	t.Run("InvalidRecordImportsNothing", func(t *testing.T) {
+		archive := append(export(t, "ndjson"), []byte(`{"id": "bad", "json": "{}", "passwordHash": "plain"}`+"\n")...)
+
+		db, handler := newTarget(t)
+		if code, _ := importInto(t, handler, "application/x-ndjson", "", archive); code != http.StatusBadRequest {
+			t.Fatalf("Expected status %d, got %d", http.StatusBadRequest, code)
+		}
+		if _, err := db.GetJSON(t.Context(), first); err == nil {
+			t.Errorf("Expected no mock to be imported")
+		}
+	})
+
+	t.Run("UnsupportedContentType", func(t *testing.T) {
+		_, handler := newTarget(t)
+		if code, _ := importInto(t, handler, "text/plain", "", nil); code != http.StatusUnsupportedMediaType {
+			t.Errorf("Expected status %d, got %d", http.StatusUnsupportedMediaType, code)
+		}
+	})
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"net/http"
+
+	"mockj-go/internal/codegen"
+	"mockj-go/internal/schema"
+)
+
+// GetJSONTypes handles GET /api/json/{id}/types - emits type definitions for the mock's
+// content in ?lang=go|typescript|kotlin|swift. Mocks listed in ?merge=id1,id2 are treated as
+// further samples, so fields missing from some of them become optional.
+func (h *JSONHandler) GetJSONTypes(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromPath(r.URL.Path)
+	if id == "" {
+		h.writeError(w, http.StatusBadRequest, "invalid_id", "ID is required")
+		return
+	}
+
+	lang := r.URL.Query().Get("lang")
+	switch lang {
+	case codegen.Go, codegen.TypeScript, codegen.Kotlin, codegen.Swift:
+	case "":
+		h.writeError(w, http.StatusBadRequest, "invalid_lang", "lang is required: go, typescript, kotlin or swift")
+		return
+	default:
+		h.writeError(w, http.StatusBadRequest, "invalid_lang", "Unsupported lang "+lang+": use go, typescript, kotlin or swift")
+		return
+	}
+
+	samples, ok := h.loadSamples(w, r, id)
+	if !ok {
+		return
+	}
+
+	source, err := codegen.Generate(lang, r.URL.Query().Get("name"), schema.Infer(samples...))
+	if err != nil {
+		h.writeError(w, http.StatusInternalServerError, "codegen_error", "Failed to generate types")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(source))
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"mockj-go/internal/database"
+	"mockj-go/internal/events"
+)
+
+func TestGetJSONTypes(t *testing.T) {
+	db, err := database.NewDatabase(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	handler := NewJSONHandler(db, events.NewBus(), nil)
+
+	id := createTestJSON(t, handler, map[string]interface{}{
+		"json": `{
+			"user_id": 7,
+			"profile_url": "https://example.com/u/7",
+			"addresses": [{"street": "Main St", "zip": "12345"}, {"street": "High St"}],
+			"nickname": null
+		}`,
+		"password": "test123",
+	})
+
+	getTypes := func(t *testing.T, query string) (int, string) {
+		req := httptest.NewRequest("GET", "/api/json/"+id+"/types?"+query, nil)
+		w := httptest.NewRecorder()
+		handler.GetJSONTypes(w, req)
+		return w.Code, w.Body.String()
+	}
+
+	t.Run("Go", func(t *testing.T) {
+		code, source := getTypes(t, "lang=go&name=user")
+		if code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, code, source)
+		}
+
+		expected := []string{
+			"type User struct",
+			"UserID     int64",
+			"ProfileURL string",
+			"Addresses  []Address",
+			"type Address struct",
+			"Zip    *string `json:\"zip,omitempty\"`",
+		}
+		for _, fragment := range expected {
+			if !strings.Contains(source, fragment) {
+				t.Errorf("Expected Go source to contain %q, got:\n%s", fragment, source)
+			}
+		}
+	})
+
+	t.Run("TypeScript", func(t *testing.T) {
+		code, source := getTypes(t, "lang=typescript")
+		if code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d", http.StatusOK, code)
+		}
+
+		for _, fragment := range []string{"export interface Root {", "addresses: Address[];", "zip?: string;", "nickname: unknown;"} {
+			if !strings.Contains(source, fragment) {
+				t.Errorf("Expected TypeScript source to contain %q, got:\n%s", fragment, source)
+			}
+		}
+	})
+
+	t.Run("Kotlin", func(t *testing.T) {
+		_, source := getTypes(t, "lang=kotlin")
+		if !strings.Contains(source, `@SerialName("user_id") val userId: Long,`) {
+			t.Errorf("Expected renamed Kotlin property, got:\n%s", source)
+		}
+	})
+
+	t.Run("Swift", func(t *testing.T) {
+		_, source := getTypes(t, "lang=swift")
+		if !strings.Contains(source, `case userId = "user_id"`) || !strings.Contains(source, "let zip: String?") {
+			t.Errorf("Expected Swift coding keys and optional zip, got:\n%s", source)
+		}
+	})
+
+	t.Run("UnsupportedLanguage", func(t *testing.T) {
+		if code, _ := getTypes(t, "lang=cobol"); code != http.StatusBadRequest {
+			t.Errorf("Expected status %d, got %d", http.StatusBadRequest, code)
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/json/missing/types?lang=go", nil)
+		w := httptest.NewRecorder()
+		handler.GetJSONTypes(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status %d, got %d", http.StatusNotFound, w.Code)
+		}
+	})
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"mockj-go/internal/models"
+)
+
+// maxNearMisses caps how many non-matching requests are reported on failure
+const maxNearMisses = 10
+
+// VerifyRequest represents the request body for verifying journaled requests
+type VerifyRequest struct {
+	Request models.RequestPattern   `json:"request"`
+	Count   models.CountExpectation `json:"count"`
+}
+
+// VerifyResult reports the outcome of a verification
+type VerifyResult struct {
+	Passed     bool        `json:"passed"`
+	Expected   string      `json:"expected"`
+	Actual     int         `json:"actual"`
+	Matched    []int64     `json:"matched"`
+	NearMisses []*NearMiss `json:"nearMisses,omitempty"`
+}
+
+// NearMiss is a journaled request that failed some of the pattern's conditions
+type NearMiss struct {
+	Request    *models.RequestLog `json:"request"`
+	Mismatches []string           `json:"mismatches"`
+}
+
+// Verify handles POST /api/verify
+func (h *JSONHandler) Verify(w http.ResponseWriter, r *http.Request) {
+	var req VerifyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid_request", "Invalid JSON body")
+		return
+	}
+
+	if err := req.Request.Compile(); err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid_pattern", err.Error())
+		return
+	}
+
+	entries, err := h.db.ListRequests(r.Context(), models.RequestFilter{MockID: req.Request.MockID})
+	if err != nil {
+		h.writeError(w, http.StatusInternalServerError, "database_error", "Failed to list requests")
+		return
+	}
+
+	result := &VerifyResult{
+		Expected: req.Count.String(),
+		Matched:  []int64{},
+	}
+
+	var nearMisses []*NearMiss
+	for _, entry := range entries {
+		mismatches := req.Request.Mismatches(entry)
+		if len(mismatches) == 0 {
+			result.Matched = append(result.Matched, entry.ID)
+			continue
+		}
+		nearMisses = append(nearMisses, &NearMiss{Request: entry, Mismatches: mismatches})
+	}
+
+	result.Actual = len(result.Matched)
+	result.Passed = req.Count.Satisfied(result.Actual)
+
+	if !result.Passed {
+		// Closest requests first; the stable sort keeps newest first among equals
+		sort.SliceStable(nearMisses, func(i, j int) bool {
+			return len(nearMisses[i].Mismatches) < len(nearMisses[j].Mismatches)
+		})
+		if len(nearMisses) > maxNearMisses {
+			nearMisses = nearMisses[:maxNearMisses]
+		}
+		result.NearMisses = nearMisses
+	}
+
+	h.writeJSON(w, http.StatusOK, SuccessResponse{
+		Data: result,
+	})
+}
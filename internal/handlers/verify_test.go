@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"mockj-go/internal/database"
+	"mockj-go/internal/events"
+)
+
+func TestVerify(t *testing.T) {
+	db, err := database.NewDatabase(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	handler := NewJSONHandler(db, events.NewBus(), nil)
+
+	id := createTestJSON(t, handler, map[string]interface{}{
+		"json":     `{"name": "John"}`,
+		"password": "test123",
+	})
+
+	hit := func(token, body string) {
+		req := httptest.NewRequest("POST", "/api/json/"+id+"/content", bytes.NewReader([]byte(body)))
+		req.Header.Set("X-Token", token)
+		handler.GetJSONContent(httptest.NewRecorder(), req)
+	}
+	hit("abc", `{"order": "Z-1"}`)
+	hit("abc", `{"order": "Z-2"}`)
+	hit("other", `{"order": "Z-3"}`)
+
+	verify := func(t *testing.T, reqBody map[string]interface{}) VerifyResult {
+		body, _ := json.Marshal(reqBody)
+		req := httptest.NewRequest("POST", "/api/verify", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		handler.Verify(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+
+		var response struct {
+			Data VerifyResult `json:"data"`
+		}
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		return response.Data
+	}
+
+	t.Run("VerifyPasses", func(t *testing.T) {
+		result := verify(t, map[string]interface{}{
+			"request": map[string]interface{}{
+				"mockId":  id,
+				"method":  "POST",
+				"headers": map[string]interface{}{"X-Token": map[string]interface{}{"equalTo": "abc"}},
+				"body":    map[string]interface{}{"contains": "Z-"},
+			},
+			"count": map[string]interface{}{"exactly": 2},
+		})
+
+		if !result.Passed || result.Actual != 2 {
+			t.Errorf("Expected verification to pass with 2 matches, got %+v", result)
+		}
+		if len(result.NearMisses) != 0 {
+			t.Errorf("Expected no near misses on success, got %d", len(result.NearMisses))
+		}
+	})
+
+	t.Run("VerifyFailsWithNearMisses", func(t *testing.T) {
+		result := verify(t, map[string]interface{}{
+			"request": map[string]interface{}{
+				"mockId":  id,
+				"headers": map[string]interface{}{"X-Token": map[string]interface{}{"equalTo": "other"}},
+				"body":    map[string]interface{}{"matches": `Z-[12]`},
+			},
+			"count": map[string]interface{}{"atLeast": 1},
+		})
+
+		if result.Passed || result.Actual != 0 {
+			t.Fatalf("Expected verification to fail, got %+v", result)
+		}
+		if len(result.NearMisses) != 3 {
+			t.Fatalf("Expected 3 near misses, got %d", len(result.NearMisses))
+		}
+		// Each request fails exactly one condition
+		for _, miss := range result.NearMisses {
+			if len(miss.Mismatches) != 1 {
+				t.Errorf("Expected a single mismatch, got %v", miss.Mismatches)
+			}
+		}
+	})
+
+	t.Run("VerifyInvalidPattern", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]interface{}{
+			"request": map[string]interface{}{"body": map[string]interface{}{"matches": "("}},
+		})
+		req := httptest.NewRequest("POST", "/api/verify", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		handler.Verify(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+		}
+	})
+}
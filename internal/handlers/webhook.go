@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"mockj-go/internal/models"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// defaultDeliveryLimit and maxDeliveryLimit bound the number of deliveries listed at once
+const (
+	defaultDeliveryLimit = 100
+	maxDeliveryLimit     = 1000
+)
+
+// CreateWebhookRequest represents the request body for subscribing a webhook
+type CreateWebhookRequest struct {
+	URL      string   `json:"url"`
+	Events   []string `json:"events,omitempty"` // created, updated, deleted or expired; empty for all
+	MockID   string   `json:"mockId,omitempty"` // The mock whose events are delivered, empty for every mock
+	Secret   string   `json:"secret"`           // Key of the HMAC-SHA256 signature of each delivery
+	Password string   `json:"password"`         // The mock's password, unless subscribing to every mock
+}
+
+// CreateWebhook handles POST /api/webhooks - subscribes to the events of a mock, which
+// takes the mock's password. Subscriptions to every mock need the transfer password and
+// are handled by TransferHandler.CreateWebhook when it is configured.
+func (h *JSONHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	h.createWebhook(w, r, nil)
+}
+
+// createWebhook subscribes a webhook. authorizeEveryMock checks subscriptions to every mock
+// and returns the password hash they are deleted with; without it they are refused.
+func (h *JSONHandler) createWebhook(w http.ResponseWriter, r *http.Request, authorizeEveryMock func(http.ResponseWriter, *http.Request) (string, bool)) {
+	var req CreateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid_request", "Invalid JSON body")
+		return
+	}
+
+	webhook := models.NewWebhook(req.URL, req.Events, req.MockID, req.Secret, "")
+	if err := webhook.Validate(); err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid_webhook", err.Error())
+		return
+	}
+
+	if req.MockID == "" {
+		if authorizeEveryMock == nil {
+			h.writeError(w, http.StatusBadRequest, "invalid_webhook", "mockId is required unless TRANSFER_PASSWORD is set")
+			return
+		}
+		password, ok := authorizeEveryMock(w, r)
+		if !ok {
+			return
+		}
+		webhook.Password = password
+	} else {
+		jsonModel, ok := h.authorize(r.Context(), w, req.MockID, req.Password)
+		if !ok {
+			return
+		}
+		// The subscription keeps the mock's password, so it can be removed after the mock is gone
+		webhook.Password = jsonModel.Password
+	}
+
+	if err := h.db.CreateWebhook(r.Context(), webhook); err != nil {
+		h.writeError(w, http.StatusInternalServerError, "database_error", "Failed to create webhook")
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, SuccessResponse{
+		Data:    webhook,
+		Message: "Webhook created successfully",
+	})
+}
+
+// GetWebhook handles GET /api/webhooks/{webhookId}
+func (h *JSONHandler) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	webhook, ok := h.lookupWebhook(r.Context(), w, extractIDFromPath(r.URL.Path))
+	if !ok {
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, SuccessResponse{
+		Data: webhook,
+	})
+}
+
+// DeleteWebhook handles DELETE /api/webhooks/{webhookId} - unsubscribes and drops the
+// delivery log, including deliveries still queued
+func (h *JSONHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	webhook, ok := h.lookupWebhook(r.Context(), w, extractIDFromPath(r.URL.Path))
+	if !ok {
+		return
+	}
+
+	var req PasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid_request", "Invalid JSON body")
+		return
+	}
+	if req.Password == "" {
+		h.writeError(w, http.StatusBadRequest, "invalid_password", "Password is required")
+		return
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(webhook.Password), []byte(req.Password)); err != nil {
+		h.writeError(w, http.StatusUnauthorized, "unauthorized", "Invalid password")
+		return
+	}
+
+	if err := h.db.DeleteWebhook(r.Context(), webhook.ID); err != nil {
+		h.writeError(w, http.StatusInternalServerError, "database_error", "Failed to delete webhook")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, SuccessResponse{
+		Message: "Webhook deleted successfully",
+	})
+}
+
+// ListWebhookDeliveries handles GET /api/webhooks/{webhookId}/deliveries - the delivery log,
+// newest first, optionally only in ?state= pending, delivered or failed
+func (h *JSONHandler) ListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	webhook, ok := h.lookupWebhook(r.Context(), w, extractIDFromPath(r.URL.Path))
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	state := query.Get("state")
+	if state != "" && state != models.DeliveryPending && state != models.DeliveryDelivered && state != models.DeliveryFailed {
+		h.writeError(w, http.StatusBadRequest, "invalid_filter", "invalid state parameter")
+		return
+	}
+
+	limit := defaultDeliveryLimit
+	if value := query.Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			h.writeError(w, http.StatusBadRequest, "invalid_filter", "invalid limit parameter")
+			return
+		}
+		limit = min(n, maxDeliveryLimit)
+	}
+
+	deliveries, err := h.db.ListDeliveries(r.Context(), webhook.ID, state, limit)
+	if err != nil {
+		h.writeError(w, http.StatusInternalServerError, "database_error", "Failed to list deliveries")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, SuccessResponse{
+		Data: deliveries,
+	})
+}
+
+// lookupWebhook retrieves a webhook, writing the error response when it does not exist
+func (h *JSONHandler) lookupWebhook(ctx context.Context, w http.ResponseWriter, id string) (*models.Webhook, bool) {
+	if id == "" {
+		h.writeError(w, http.StatusBadRequest, "invalid_id", "ID is required")
+		return nil, false
+	}
+
+	webhook, err := h.db.GetWebhook(ctx, id)
+	if err != nil {
+		if err.Error() == "webhook not found" {
+			h.writeError(w, http.StatusNotFound, "not_found", "Webhook not found")
+		} else {
+			h.writeError(w, http.StatusInternalServerError, "database_error", "Failed to retrieve webhook")
+		}
+		return nil, false
+	}
+
+	return webhook, true
+}
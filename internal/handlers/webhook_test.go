@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"mockj-go/internal/config"
+	"mockj-go/internal/database"
+	"mockj-go/internal/events"
+	"mockj-go/internal/models"
+)
+
+func TestWebhooks(t *testing.T) {
+	db, err := database.NewDatabase(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	handler := NewJSONHandler(db, events.NewBus(), nil)
+
+	create := func(req CreateWebhookRequest) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(req)
+		w := httptest.NewRecorder()
+		handler.CreateWebhook(w, httptest.NewRequest("POST", "/api/webhooks", bytes.NewReader(body)))
+		return w
+	}
+
+	mockID := createTestJSON(t, handler, map[string]interface{}{"json": `{}`, "password": "test123"})
+	w := create(CreateWebhookRequest{URL: "http://ci.example/hook", Events: []string{"updated"}, MockID: mockID, Secret: "s3cret", Password: "test123"})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), "s3cret") {
+		t.Errorf("Expected the secret to be left out of the response, got %s", w.Body.String())
+	}
+	var created struct {
+		Data models.Webhook `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	id := created.Data.ID
+
+	t.Run("Validation", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			req    CreateWebhookRequest
+			status int
+		}{
+			{"MissingPassword", CreateWebhookRequest{URL: "http://ci.example/hook", MockID: mockID, Secret: "s"}, http.StatusBadRequest},
+			{"WrongPassword", CreateWebhookRequest{URL: "http://ci.example/hook", MockID: mockID, Secret: "s", Password: "p"}, http.StatusUnauthorized},
+			{"EveryMock", CreateWebhookRequest{URL: "http://ci.example/hook", Secret: "s", Password: "test123"}, http.StatusBadRequest},
+			{"UnknownMock", CreateWebhookRequest{URL: "http://ci.example/hook", MockID: "missing", Secret: "s", Password: "test123"}, http.StatusNotFound},
+			{"RelativeURL", CreateWebhookRequest{URL: "/hook", MockID: mockID, Secret: "s", Password: "test123"}, http.StatusBadRequest},
+			{"UnknownEvent", CreateWebhookRequest{URL: "http://ci.example/hook", Events: []string{"viewed"}, MockID: mockID, Secret: "s", Password: "test123"}, http.StatusBadRequest},
+			{"MissingSecret", CreateWebhookRequest{URL: "http://ci.example/hook", MockID: mockID, Password: "test123"}, http.StatusBadRequest},
+		}
+		for _, tt := range tests {
+			if w := create(tt.req); w.Code != tt.status {
+				t.Errorf("%s: expected status %d, got %d", tt.name, tt.status, w.Code)
+			}
+		}
+	})
+
+	t.Run("GetAndDeliveries", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.GetWebhook(w, httptest.NewRequest("GET", "/api/webhooks/"+id, nil))
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "http://ci.example/hook") {
+			t.Errorf("Expected the webhook, got %d: %s", w.Code, w.Body.String())
+		}
+
+		delivery := &models.WebhookDelivery{WebhookID: id, EventID: 1, EventType: "updated", Payload: "{}", State: models.DeliveryPending}
+		if err := db.CreateDelivery(t.Context(), delivery); err != nil {
+			t.Fatalf("Failed to create delivery: %v", err)
+		}
+
+		for query, count := range map[string]int{"": 1, "?state=pending": 1, "?state=failed": 0} {
+			w := httptest.NewRecorder()
+			handler.ListWebhookDeliveries(w, httptest.NewRequest("GET", "/api/webhooks/"+id+"/deliveries"+query, nil))
+			var response struct {
+				Data []models.WebhookDelivery `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if len(response.Data) != count {
+				t.Errorf("%q: expected %d deliveries, got %d", query, count, len(response.Data))
+			}
+		}
+
+		w = httptest.NewRecorder()
+		handler.ListWebhookDeliveries(w, httptest.NewRequest("GET", "/api/webhooks/"+id+"/deliveries?state=lost", nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		remove := func(password string) *httptest.ResponseRecorder {
+			body, _ := json.Marshal(PasswordRequest{Password: password})
+			w := httptest.NewRecorder()
+			handler.DeleteWebhook(w, httptest.NewRequest("DELETE", "/api/webhooks/"+id, bytes.NewReader(body)))
+			return w
+		}
+		if w := remove("wrong"); w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status %d, got %d", http.StatusUnauthorized, w.Code)
+		}
+		if w := remove("test123"); w.Code != http.StatusOK {
+			t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+		}
+		if w := remove("test123"); w.Code != http.StatusNotFound {
+			t.Errorf("Expected status %d, got %d", http.StatusNotFound, w.Code)
+		}
+		if deliveries, _ := db.ListDeliveries(t.Context(), id, "", 10); len(deliveries) != 0 {
+			t.Errorf("Expected the delivery log to be dropped, got %d entries", len(deliveries))
+		}
+	})
+	t.Run("EveryMock", func(t *testing.T) {
+		transfer, err := NewTransferHandler(handler, config.TransferConfig{Password: "transfer123"})
+		if err != nil {
+			t.Fatalf("Failed to create transfer handler: %v", err)
+		}
+		create := func(password string) *httptest.ResponseRecorder {
+			body, _ := json.Marshal(CreateWebhookRequest{URL: "http://ci.example/every", Secret: "s"})
+			req := httptest.NewRequest("POST", "/api/webhooks", bytes.NewReader(body))
+			if password != "" {
+				req.Header.Set(transferPasswordHeader, password)
+			}
+			w := httptest.NewRecorder()
+			transfer.CreateWebhook(w, req)
+			return w
+		}
+		if w := create(""); w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+		}
+		if w := create("wrong"); w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status %d, got %d", http.StatusUnauthorized, w.Code)
+		}
+		w := create("transfer123")
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected status %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+		}
+		var created struct {
+			Data models.Webhook `json:"data"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if created.Data.MockID != "" || !created.Data.Matches("updated", mockID) || !created.Data.Matches("updated", "other") {
+			t.Errorf("Expected a subscription to every mock, got %+v", created.Data)
+		}
+
+		body, _ := json.Marshal(PasswordRequest{Password: "transfer123"})
+		w = httptest.NewRecorder()
+		handler.DeleteWebhook(w, httptest.NewRequest("DELETE", "/api/webhooks/"+created.Data.ID, bytes.NewReader(body)))
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected the transfer password to delete it, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+}
@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"mockj-go/internal/models"
+	"mockj-go/internal/websocket"
+)
+
+// ServeWebSocket handles GET /api/json/{id}/ws - upgrades to a WebSocket that plays the
+// mock's script
+func (h *JSONHandler) ServeWebSocket(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromPath(r.URL.Path)
+	if id == "" {
+		h.writeError(w, http.StatusBadRequest, "invalid_id", "ID is required")
+		return
+	}
+
+	jsonModel, err := h.db.GetJSON(r.Context(), id)
+	if err != nil {
+		if err.Error() == "json not found or expired" {
+			h.writeError(w, http.StatusNotFound, "not_found", "JSON not found or expired")
+		} else {
+			h.writeError(w, http.StatusInternalServerError, "database_error", "Failed to retrieve JSON")
+		}
+		return
+	}
+
+	w, record := h.startJournal(w, r, id)
+	defer record()
+
+	if jsonModel.WebSocket == nil {
+		h.writeError(w, http.StatusNotFound, "no_websocket", "Mock has no WebSocket script")
+		return
+	}
+
+	h.serveWebSocket(w, r, jsonModel)
+}
+
+// serveWebSocket upgrades the request and plays the mock's script until either side closes
+func (h *JSONHandler) serveWebSocket(w http.ResponseWriter, r *http.Request, jsonModel *models.JSON) {
+	conn, err := websocket.Upgrade(w, r, jsonModel.ID)
+	if err != nil {
+		slog.WarnContext(r.Context(), "WebSocket upgrade failed", "mock_id", jsonModel.ID, "error", err)
+		return
+	}
+	// The hijacked connection never reports a status to the journal
+	if jw, ok := w.(*journalWriter); ok {
+		jw.Status = http.StatusSwitchingProtocols
+	}
+
+	h.sockets.Add(conn, r.URL.Path)
+	defer h.sockets.Remove(conn)
+
+	newWebSocketPlayer(r.Context(), conn, jsonModel).play()
+}
+
+// maxPendingReplies caps the replies of one connection waiting for their delay or being
+// sent. Further incoming messages are not read until one finishes.
+const maxPendingReplies = 16
+
+// webSocketPlayer sends the scripted messages of one connection
+type webSocketPlayer struct {
+	ctx     context.Context // The upgraded request's, for logging
+	conn    *websocket.Conn
+	script  *models.WebSocketScript
+	content []byte
+	binary  bool
+	matches []*regexp.Regexp
+	replies chan struct{} // Holds a token per pending reply
+	done    chan struct{}
+}
+
+func newWebSocketPlayer(ctx context.Context, conn *websocket.Conn, jsonModel *models.JSON) *webSocketPlayer {
+	// Scripts were validated when stored, so the patterns compile
+	matches := make([]*regexp.Regexp, len(jsonModel.WebSocket.Messages))
+	for i, message := range jsonModel.WebSocket.Messages {
+		matches[i] = regexp.MustCompile(message.Match)
+	}
+
+	content, err := jsonModel.Body()
+	if err != nil {
+		content = []byte(jsonModel.Content)
+	}
+
+	return &webSocketPlayer{
+		ctx:     ctx,
+		conn:    conn,
+		script:  jsonModel.WebSocket,
+		content: content,
+		binary:  jsonModel.ContentType == models.ContentTypeBinary,
+		matches: matches,
+		replies: make(chan struct{}, maxPendingReplies),
+		done:    make(chan struct{}),
+	}
+}
+
+func (p *webSocketPlayer) play() {
+	defer close(p.done)
+
+	for _, message := range p.script.Messages {
+		switch message.On {
+		case models.WebSocketOnConnect:
+			go func() {
+				if p.wait(message.DelayMs) {
+					p.send(message, nil)
+				}
+			}()
+		case models.WebSocketOnInterval:
+			go p.repeat(message)
+		}
+	}
+
+	for {
+		messageType, data, err := p.conn.ReadMessage()
+		if err != nil {
+			if err != websocket.ErrClosed {
+				slog.InfoContext(p.ctx, "WebSocket closed", "connection_id", p.conn.ID, "mock_id", p.conn.MockID, "error", err)
+			}
+			return
+		}
+		if messageType != websocket.TextMessage {
+			continue
+		}
+
+		for i, message := range p.script.Messages {
+			if message.On != models.WebSocketOnMessage {
+				continue
+			}
+			groups := p.matches[i].FindSubmatchIndex(data)
+			if groups == nil {
+				continue
+			}
+			p.replies <- struct{}{}
+			go func() {
+				defer func() { <-p.replies }()
+				if p.wait(message.DelayMs) {
+					p.send(message, p.matches[i].Expand([]byte{}, []byte(message.Data), data, groups))
+				}
+			}()
+		}
+	}
+}
+
+// repeat sends an interval message until its repeat count is reached or the connection ends
+func (p *webSocketPlayer) repeat(message models.WebSocketMessage) {
+	if !p.wait(message.DelayMs) {
+		return
+	}
+	ticker := time.NewTicker(time.Duration(message.IntervalMs) * time.Millisecond)
+	defer ticker.Stop()
+
+	for sent := 0; message.Repeat == 0 || sent < message.Repeat; sent++ {
+		select {
+		case <-ticker.C:
+			p.send(message, nil)
+		case <-p.done:
+			return
+		}
+	}
+}
+
+// wait sleeps for delayMs, reporting false when the connection ended meanwhile
+func (p *webSocketPlayer) wait(delayMs int) bool {
+	if delayMs == 0 {
+		return true
+	}
+	timer := time.NewTimer(time.Duration(delayMs) * time.Millisecond)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-p.done:
+		return false
+	}
+}
+
+// send writes a scripted message. Replies pass their data with match groups expanded.
+func (p *webSocketPlayer) send(message models.WebSocketMessage, expanded []byte) {
+	messageType := websocket.TextMessage
+	var data []byte
+	switch {
+	case message.Data == "":
+		data = p.content
+		if p.binary {
+			messageType = websocket.BinaryMessage
+		}
+	case message.Binary:
+		// Validated as base64 when stored
+		data, _ = base64.StdEncoding.DecodeString(message.Data)
+		messageType = websocket.BinaryMessage
+	case expanded != nil:
+		data = expanded
+	default:
+		data = []byte(message.Data)
+	}
+
+	if err := p.conn.WriteMessage(messageType, data); err != nil {
+		return
+	}
+	if message.Close {
+		p.conn.Close(websocket.CloseNormal, "")
+	}
+}
+
+// ListWebSocketConnections handles GET /api/websockets - lists open WebSocket connections,
+// optionally only those of ?mockId=
+func (h *JSONHandler) ListWebSocketConnections(w http.ResponseWriter, r *http.Request) {
+	h.writeJSON(w, http.StatusOK, SuccessResponse{
+		Data: h.sockets.List(r.URL.Query().Get("mockId")),
+	})
+}
+
+// CloseWebSocketConnection handles DELETE /api/websockets/{connectionId} - disconnects a
+// client. It takes the password of the connection's mock.
+func (h *JSONHandler) CloseWebSocketConnection(w http.ResponseWriter, r *http.Request) {
+	conn, ok := h.sockets.Get(strings.TrimPrefix(r.URL.Path, "/api/websockets/"))
+	if !ok {
+		h.writeError(w, http.StatusNotFound, "not_found", "Connection not found")
+		return
+	}
+
+	var req PasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid_request", "Invalid JSON body")
+		return
+	}
+	if _, ok := h.authorize(r.Context(), w, conn.MockID, req.Password); !ok {
+		return
+	}
+
+	conn.Close(websocket.CloseNormal, "closed by admin")
+
+	h.writeJSON(w, http.StatusOK, SuccessResponse{
+		Message: "Connection closed successfully",
+	})
+}
+
+// CloseWebSockets disconnects every WebSocket client, for server shutdown
+func (h *JSONHandler) CloseWebSockets() {
+	h.sockets.CloseAll()
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"mockj-go/internal/database"
+	"mockj-go/internal/events"
+	"mockj-go/internal/websocket"
+)
+
+// testWebSocket is a minimal client for the scripted mock connections
+type testWebSocket struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func dialTestWebSocket(t *testing.T, serverURL, path string) *testWebSocket {
+	t.Helper()
+	conn, err := net.Dial("tcp", strings.TrimPrefix(serverURL, "http://"))
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	req, _ := http.NewRequest("GET", serverURL+path, nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	if err := req.Write(conn); err != nil {
+		t.Fatalf("Failed to send handshake: %v", err)
+	}
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		t.Fatalf("Failed to read handshake: %v", err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols || resp.Header.Get("Sec-WebSocket-Accept") != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("Unexpected handshake response %d %v", resp.StatusCode, resp.Header)
+	}
+
+	return &testWebSocket{conn: conn, reader: reader}
+}
+
+func (c *testWebSocket) send(t *testing.T, text string) {
+	t.Helper()
+	frame := []byte{0x81, 0x80 | byte(len(text)), 0, 0, 0, 0}
+	if _, err := c.conn.Write(append(frame, text...)); err != nil {
+		t.Fatalf("Failed to send: %v", err)
+	}
+}
+
+// receive returns the opcode and payload of the next frame
+func (c *testWebSocket) receive(t *testing.T) (byte, string) {
+	t.Helper()
+	header := make([]byte, 2)
+	if _, err := c.reader.Read(header[:1]); err != nil {
+		t.Fatalf("Failed to receive: %v", err)
+	}
+	if _, err := c.reader.Read(header[1:]); err != nil {
+		t.Fatalf("Failed to receive: %v", err)
+	}
+	payload := make([]byte, header[1]&0x7f)
+	for read := 0; read < len(payload); {
+		n, err := c.reader.Read(payload[read:])
+		if err != nil {
+			t.Fatalf("Failed to receive: %v", err)
+		}
+		read += n
+	}
+	return header[0] & 0x0f, string(payload)
+}
+
+func TestWebSocketMocks(t *testing.T) {
+	db, err := database.NewDatabase(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	handler := NewJSONHandler(db, events.NewBus(), nil)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/json/{id}/ws", handler.ServeWebSocket)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if !handler.ServeRoute(w, r) {
+			http.NotFound(w, r)
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	defer handler.CloseWebSockets()
+
+	id := createTestJSON(t, handler, map[string]interface{}{
+		"json":     `{"price": 42}`,
+		"password": "test123",
+		"route":    "/feed",
+		"websocket": map[string]interface{}{
+			"messages": []map[string]interface{}{
+				{"on": "connect"},
+				{"on": "interval", "intervalMs": 10, "repeat": 2, "data": "tick"},
+				{"on": "message", "match": `^subscribe (\w+)$`, "data": `{"subscribed": "$1"}`},
+				{"on": "message", "match": `^bye$`, "data": "goodbye", "close": true},
+			},
+		},
+	})
+
+	t.Run("ConnectAndIntervalMessages", func(t *testing.T) {
+		client := dialTestWebSocket(t, server.URL, "/api/json/"+id+"/ws")
+		defer client.conn.Close()
+
+		if _, payload := client.receive(t); payload != `{"price": 42}` {
+			t.Errorf("Expected the mock content on connect, got %q", payload)
+		}
+		for i := 0; i < 2; i++ {
+			if _, payload := client.receive(t); payload != "tick" {
+				t.Errorf("Expected tick, got %q", payload)
+			}
+		}
+	})
+
+	t.Run("RepliesOnRoute", func(t *testing.T) {
+		client := dialTestWebSocket(t, server.URL, "/feed")
+		defer client.conn.Close()
+		client.receive(t)
+
+		client.send(t, "subscribe prices")
+		for {
+			if _, payload := client.receive(t); payload != "tick" {
+				if payload != `{"subscribed": "prices"}` {
+					t.Errorf("Unexpected reply %q", payload)
+				}
+				break
+			}
+		}
+
+		client.send(t, "bye")
+		for {
+			opcode, payload := client.receive(t)
+			if opcode == 8 {
+				break
+			}
+			if payload != "tick" && payload != "goodbye" {
+				t.Errorf("Unexpected message %q before close", payload)
+			}
+		}
+	})
+
+	t.Run("AdminEndpoints", func(t *testing.T) {
+		client := dialTestWebSocket(t, server.URL, "/api/json/"+id+"/ws")
+		defer client.conn.Close()
+		client.receive(t)
+
+		var connections []websocket.ConnectionInfo
+		deadline := time.Now().Add(time.Second)
+		for len(connections) == 0 && time.Now().Before(deadline) {
+			w := httptest.NewRecorder()
+			handler.ListWebSocketConnections(w, httptest.NewRequest("GET", "/api/websockets?mockId="+id, nil))
+			var response struct {
+				Data []websocket.ConnectionInfo `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			connections = response.Data
+		}
+		if len(connections) != 1 || connections[0].MockID != id || connections[0].MessagesSent == 0 {
+			t.Fatalf("Expected one open connection with sent messages, got %+v", connections)
+		}
+
+		closeRequest := func(password string) *httptest.ResponseRecorder {
+			body, _ := json.Marshal(PasswordRequest{Password: password})
+			w := httptest.NewRecorder()
+			handler.CloseWebSocketConnection(w, httptest.NewRequest("DELETE", "/api/websockets/"+connections[0].ID, bytes.NewReader(body)))
+			return w
+		}
+		if w := closeRequest("wrong"); w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status %d, got %d", http.StatusUnauthorized, w.Code)
+		}
+		if w := closeRequest("test123"); w.Code != http.StatusOK {
+			t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+		}
+		for {
+			if opcode, _ := client.receive(t); opcode == 8 {
+				break
+			}
+		}
+	})
+
+	t.Run("InvalidScriptRejected", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]interface{}{
+			"json":      `{}`,
+			"password":  "test123",
+			"websocket": map[string]interface{}{"messages": []map[string]interface{}{{"on": "interval", "intervalMs": 1}}},
+		})
+		w := httptest.NewRecorder()
+		handler.CreateJSON(w, httptest.NewRequest("POST", "/api/json", bytes.NewReader(body)))
+		if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "invalid_websocket") {
+			t.Errorf("Expected invalid_websocket, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+}
@@ -0,0 +1,44 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"mockj-go/internal/config"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("JSONWithRequestID", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := New(&buf, config.LogConfig{Level: "info", Format: "json"})
+
+		ctx := WithRequestID(context.Background(), "req-1")
+		logger.With("component", "test").InfoContext(ctx, "served", "status", 200)
+
+		var record map[string]interface{}
+		if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+			t.Fatalf("Expected a JSON line, got %q: %v", buf.String(), err)
+		}
+		if record["msg"] != "served" || record[RequestIDKey] != "req-1" || record["status"] != float64(200) || record["component"] != "test" {
+			t.Errorf("Unexpected record %v", record)
+		}
+	})
+
+	t.Run("TextFiltersLevel", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := New(&buf, config.LogConfig{Level: "warn", Format: "text"})
+
+		logger.Info("hidden")
+		logger.Warn("shown")
+
+		if strings.Contains(buf.String(), "hidden") || !strings.Contains(buf.String(), "level=WARN msg=shown") {
+			t.Errorf("Expected only the warning, got %q", buf.String())
+		}
+		if strings.Contains(buf.String(), RequestIDKey) {
+			t.Errorf("Expected no request ID outside a request, got %q", buf.String())
+		}
+	})
+}
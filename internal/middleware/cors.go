@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"mockj-go/internal/config"
+	"mockj-go/internal/models"
+)
+
+// CORSLookup returns the CORS policy overriding the default for a request, or nil
+type CORSLookup func(r *http.Request) *models.CORS
+
+// CORS returns the middleware applying the policy configured by cfg, or the one lookup
+// returns for a request. Requests from disallowed origins get no CORS headers, so browsers
+// fail them just like a misconfigured production server. OPTIONS requests are answered here.
+func CORS(cfg config.CORSConfig, lookup CORSLookup) (func(http.Handler) http.Handler, error) {
+	policy := &models.CORS{
+		AllowedOrigins:   cfg.AllowedOrigins,
+		AllowedMethods:   cfg.AllowedMethods,
+		AllowedHeaders:   cfg.AllowedHeaders,
+		ExposedHeaders:   cfg.ExposedHeaders,
+		AllowCredentials: cfg.AllowCredentials,
+		MaxAge:           int(cfg.MaxAge.Seconds()),
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid CORS configuration: %w", err)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			applied := policy
+			if lookup != nil && r.Header.Get("Origin") != "" {
+				if override := lookup(r); override != nil {
+					applied = override
+				}
+			}
+			applyCORS(w, r, applied)
+
+			if r.Method == "OPTIONS" {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}, nil
+}
+
+// isPreflight reports whether a request is a CORS preflight request
+func isPreflight(r *http.Request) bool {
+	return r.Method == "OPTIONS" && r.Header.Get("Origin") != "" && r.Header.Get("Access-Control-Request-Method") != ""
+}
+
+// applyCORS sets the response headers of the policy for the request's origin
+func applyCORS(w http.ResponseWriter, r *http.Request, policy *models.CORS) {
+	header := w.Header()
+	origin := r.Header.Get("Origin")
+
+	anyOrigin := policy.AllowsAnyOrigin()
+	if !anyOrigin {
+		header.Add("Vary", "Origin")
+	}
+
+	switch {
+	case anyOrigin:
+		header.Set("Access-Control-Allow-Origin", "*")
+	case origin != "" && policy.AllowsOrigin(origin):
+		header.Set("Access-Control-Allow-Origin", origin)
+	default:
+		return
+	}
+
+	// Browsers ignore credentials allowed for *, so they only go with a listed origin
+	if policy.AllowCredentials && !anyOrigin {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+
+	if !isPreflight(r) {
+		if len(policy.ExposedHeaders) > 0 {
+			header.Set("Access-Control-Expose-Headers", strings.Join(policy.ExposedHeaders, ", "))
+		}
+		return
+	}
+
+	if len(policy.AllowedMethods) > 0 {
+		header.Set("Access-Control-Allow-Methods", strings.Join(policy.AllowedMethods, ", "))
+	}
+	if policy.AllowsAnyHeader() {
+		// Echoed, since browsers ignore * on requests with credentials
+		if requested := r.Header.Get("Access-Control-Request-Headers"); requested != "" {
+			header.Set("Access-Control-Allow-Headers", requested)
+		}
+		header.Add("Vary", "Access-Control-Request-Headers")
+	} else if len(policy.AllowedHeaders) > 0 {
+		header.Set("Access-Control-Allow-Headers", strings.Join(policy.AllowedHeaders, ", "))
+	}
+	if policy.MaxAge > 0 {
+		header.Set("Access-Control-Max-Age", strconv.Itoa(policy.MaxAge))
+	}
+}
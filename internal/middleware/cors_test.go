@@ -0,0 +1,162 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"mockj-go/internal/config"
+	"mockj-go/internal/models"
+)
+
+func corsHandler(t *testing.T, cfg config.CORSConfig, lookup CORSLookup) http.Handler {
+	t.Helper()
+	cors, err := CORS(cfg, lookup)
+	if err != nil {
+		t.Fatalf("Failed to create CORS middleware: %v", err)
+	}
+	return cors(ok)
+}
+
+func preflight(origin, method, headers string) http.Header {
+	return http.Header{
+		"Origin":                         {origin},
+		"Access-Control-Request-Method":  {method},
+		"Access-Control-Request-Headers": {headers},
+	}
+}
+
+func TestCORSOrigins(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         config.CORSConfig
+		origin      string
+		allowOrigin string
+	}{
+		{"AnyOrigin", config.CORSConfig{AllowedOrigins: []string{"*"}}, "https://app.example", "*"},
+		{"NoOriginHeader", config.CORSConfig{AllowedOrigins: []string{"*"}}, "", "*"},
+		{"Exact", config.CORSConfig{AllowedOrigins: []string{"https://app.example"}}, "https://app.example", "https://app.example"},
+		{"ExactCaseInsensitive", config.CORSConfig{AllowedOrigins: []string{"https://App.example"}}, "https://app.example", "https://app.example"},
+		{"Wildcard", config.CORSConfig{AllowedOrigins: []string{"https://*.example.com"}}, "https://a.b.example.com", "https://a.b.example.com"},
+		{"WildcardPort", config.CORSConfig{AllowedOrigins: []string{"http://localhost:*"}}, "http://localhost:5173", "http://localhost:5173"},
+		{"WildcardMismatch", config.CORSConfig{AllowedOrigins: []string{"https://*.example.com"}}, "https://example.com.evil", ""},
+		{"WildcardPieces", config.CORSConfig{AllowedOrigins: []string{"https://*-a*-b.example"}}, "https://x-a-y-b.example", "https://x-a-y-b.example"},
+		{"WildcardPiecesOutOfOrder", config.CORSConfig{AllowedOrigins: []string{"https://*-a*-b.example"}}, "https://x-b-a.example", ""},
+		{"WildcardManyStars", config.CORSConfig{AllowedOrigins: []string{"https://*a*a*a*a*a*b"}}, "https://" + strings.Repeat("a", 100), ""},
+		{"Disallowed", config.CORSConfig{AllowedOrigins: []string{"https://app.example"}}, "https://other.example", ""},
+		{"CredentialsEchoOrigin", config.CORSConfig{AllowedOrigins: []string{"https://*.example"}, AllowCredentials: true}, "https://app.example", "https://app.example"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.origin != "" {
+				header.Set("Origin", tt.origin)
+			}
+			w := hit(corsHandler(t, tt.cfg, nil), "GET", "/api/json/abc", "10.0.0.1:1", header)
+			if w.Code != http.StatusOK {
+				t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.allowOrigin {
+				t.Errorf("Expected Access-Control-Allow-Origin %q, got %q", tt.allowOrigin, got)
+			}
+		})
+	}
+}
+
+func TestCORSHeaders(t *testing.T) {
+	cfg := config.CORSConfig{
+		AllowedOrigins:   []string{"https://app.example"},
+		AllowedMethods:   []string{"GET", "PATCH"},
+		AllowedHeaders:   []string{"Content-Type", "X-Custom"},
+		ExposedHeaders:   []string{"X-Total-Count"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	}
+	handler := corsHandler(t, cfg, nil)
+
+	t.Run("Preflight", func(t *testing.T) {
+		w := hit(handler, "OPTIONS", "/api/json/abc", "10.0.0.1:1", preflight("https://app.example", "PATCH", "x-custom"))
+		if w.Code != http.StatusNoContent {
+			t.Errorf("Expected status %d, got %d", http.StatusNoContent, w.Code)
+		}
+		expected := map[string]string{
+			"Access-Control-Allow-Origin":      "https://app.example",
+			"Access-Control-Allow-Methods":     "GET, PATCH",
+			"Access-Control-Allow-Headers":     "Content-Type, X-Custom",
+			"Access-Control-Allow-Credentials": "true",
+			"Access-Control-Max-Age":           "600",
+			"Access-Control-Expose-Headers":    "",
+			"Vary":                             "Origin",
+		}
+		for name, value := range expected {
+			if got := w.Header().Get(name); got != value {
+				t.Errorf("Expected %s %q, got %q", name, value, got)
+			}
+		}
+	})
+
+	t.Run("ActualRequest", func(t *testing.T) {
+		w := hit(handler, "PATCH", "/api/json/abc", "10.0.0.1:1", http.Header{"Origin": {"https://app.example"}})
+		if got := w.Header().Get("Access-Control-Expose-Headers"); got != "X-Total-Count" {
+			t.Errorf("Expected exposed headers, got %q", got)
+		}
+		if got := w.Header().Get("Access-Control-Allow-Methods"); got != "" {
+			t.Errorf("Expected no preflight headers, got methods %q", got)
+		}
+	})
+
+	t.Run("DisallowedPreflight", func(t *testing.T) {
+		w := hit(handler, "OPTIONS", "/api/json/abc", "10.0.0.1:1", preflight("https://other.example", "PATCH", ""))
+		for _, name := range []string{"Access-Control-Allow-Origin", "Access-Control-Allow-Methods", "Access-Control-Allow-Credentials"} {
+			if got := w.Header().Get(name); got != "" {
+				t.Errorf("Expected no %s, got %q", name, got)
+			}
+		}
+	})
+
+	t.Run("AnyHeaderEchoesRequest", func(t *testing.T) {
+		handler := corsHandler(t, config.CORSConfig{AllowedOrigins: []string{"*"}, AllowedHeaders: []string{"*"}}, nil)
+		w := hit(handler, "OPTIONS", "/", "10.0.0.1:1", preflight("https://app.example", "POST", "x-a, x-b"))
+		if got := w.Header().Get("Access-Control-Allow-Headers"); got != "x-a, x-b" {
+			t.Errorf("Expected the requested headers, got %q", got)
+		}
+	})
+}
+
+func TestCORSOverride(t *testing.T) {
+	override := &models.CORS{AllowedOrigins: []string{"https://legacy.example"}, AllowedMethods: []string{"GET"}}
+	lookup := func(r *http.Request) *models.CORS {
+		if r.URL.Path == "/legacy" {
+			return override
+		}
+		return nil
+	}
+	handler := corsHandler(t, config.CORSConfig{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET", "POST"}}, lookup)
+
+	w := hit(handler, "OPTIONS", "/legacy", "10.0.0.1:1", preflight("https://app.example", "GET", ""))
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("Expected the override to reject the origin, got %q", got)
+	}
+	w = hit(handler, "OPTIONS", "/legacy", "10.0.0.1:1", preflight("https://legacy.example", "GET", ""))
+	if got := w.Header().Get("Access-Control-Allow-Methods"); got != "GET" {
+		t.Errorf("Expected the override's methods, got %q", got)
+	}
+	w = hit(handler, "OPTIONS", "/other", "10.0.0.1:1", preflight("https://app.example", "GET", ""))
+	if got := w.Header().Get("Access-Control-Allow-Methods"); got != "GET, POST" {
+		t.Errorf("Expected the default methods, got %q", got)
+	}
+}
+
+func TestCORSInvalidConfig(t *testing.T) {
+	for name, cfg := range map[string]config.CORSConfig{
+		"NoOrigins":      {},
+		"OriginNoScheme": {AllowedOrigins: []string{"app.example"}},
+		"BadMethod":      {AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET POST"}},
+		"AnyOriginCreds": {AllowedOrigins: []string{"*"}, AllowCredentials: true},
+	} {
+		if _, err := CORS(cfg, nil); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
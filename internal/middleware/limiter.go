@@ -0,0 +1,143 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+
+	"mockj-go/internal/config"
+	"mockj-go/internal/database"
+)
+
+// Limiter stores
+const (
+	LimiterMemory = "memory"
+	LimiterSQLite = "sqlite"
+	LimiterRedis  = "redis"
+)
+
+// evictInterval is how often buckets that have refilled completely are dropped
+const evictInterval = time.Minute
+
+// Limiter keeps the token buckets of the RateLimit middleware
+type Limiter interface {
+	// Take removes a token from the bucket of key, which holds up to limit.Burst tokens
+	Take(ctx context.Context, key string, limit Limit) (Decision, error)
+}
+
+// NewLimiter returns the limiter selected by the configuration
+func NewLimiter(db *database.Database, cfg config.RateLimitConfig) (Limiter, error) {
+	switch cfg.Store {
+	case LimiterSQLite:
+		return NewSQLiteLimiter(db), nil
+	case LimiterRedis:
+		return NewRedisLimiter(cfg.RedisURL)
+	default:
+		return NewMemoryLimiter(), nil
+	}
+}
+
+// decide turns the tokens left in a bucket into a decision
+func decide(tokens float64, allowed bool, limit Limit) Decision {
+	decision := Decision{Allowed: allowed, Remaining: int(tokens)}
+	if !allowed {
+		decision.RetryAfter = time.Duration((1 - tokens) / limit.rate() * float64(time.Second))
+	}
+	decision.Reset = time.Duration((float64(limit.Burst) - tokens) / limit.rate() * float64(time.Second))
+	return decision
+}
+
+// MemoryLimiter keeps buckets in this process, so each replica limits on its own
+type MemoryLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastEvict time.Time
+	now       func() time.Time
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	limit   Limit
+}
+
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{
+		buckets: map[string]*bucket{},
+		now:     time.Now,
+	}
+}
+
+func (m *MemoryLimiter) Take(ctx context.Context, key string, limit Limit) (Decision, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	if now.Sub(m.lastEvict) >= evictInterval {
+		m.evict(now)
+	}
+
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), updated: now}
+		m.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+now.Sub(b.updated).Seconds()*limit.rate())
+	b.updated = now
+	b.limit = limit
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	return decide(b.tokens, allowed, limit), nil
+}
+
+// evict drops buckets that have refilled since their last use; they equal a new bucket
+func (m *MemoryLimiter) evict(now time.Time) {
+	for key, b := range m.buckets {
+		if b.tokens+now.Sub(b.updated).Seconds()*b.limit.rate() >= float64(b.limit.Burst) {
+			delete(m.buckets, key)
+		}
+	}
+	m.lastEvict = now
+}
+
+// SQLiteLimiter keeps buckets in the database, shared by the replicas using it. SQLite
+// locking is only reliable on a local disk, so those replicas must run on the same host;
+// replicas on several hosts share a RedisLimiter instead.
+type SQLiteLimiter struct {
+	db  *database.Database
+	now func() time.Time
+
+	mu        sync.Mutex
+	lastEvict time.Time
+}
+
+func NewSQLiteLimiter(db *database.Database) *SQLiteLimiter {
+	return &SQLiteLimiter{db: db, now: time.Now}
+}
+
+func (s *SQLiteLimiter) Take(ctx context.Context, key string, limit Limit) (Decision, error) {
+	now := s.now()
+
+	s.mu.Lock()
+	evict := now.Sub(s.lastEvict) >= evictInterval
+	if evict {
+		s.lastEvict = now
+	}
+	s.mu.Unlock()
+	if evict {
+		if err := s.db.CleanupRateLimits(ctx, now); err != nil {
+			slog.ErrorContext(ctx, "Failed to evict rate limit buckets", "error", err)
+		}
+	}
+
+	tokens, allowed, err := s.db.TakeRateLimitToken(ctx, key, limit.Burst, limit.rate(), now)
+	if err != nil {
+		return Decision{}, err
+	}
+	return decide(tokens, allowed, limit), nil
+}
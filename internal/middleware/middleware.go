@@ -1,102 +1,100 @@
 package middleware
 
 import (
-	"log"
+	"log/slog"
+	"mime"
+	"net"
 	"net/http"
 	"time"
+
+	"mockj-go/internal/capture"
+	"mockj-go/internal/logging"
+
+	"github.com/google/uuid"
 )
 
-// CORS middleware
-func CORS(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+// RequestIDHeader carries the ID correlating a request with its log lines
+const RequestIDHeader = "X-Request-ID"
 
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
-			return
+// maxRequestIDLength bounds the client-provided request IDs that are kept
+const maxRequestIDLength = 128
+
+// RequestID middleware keeps the client's X-Request-ID, or generates one, and adds it to the
+// request's context for logging. It is also set on the request, so the journal records it and
+// proxies forward it, and on the response.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.New().String()
+			r.Header.Set(RequestIDHeader, id)
 		}
+		w.Header().Set(RequestIDHeader, id)
 
-		next.ServeHTTP(w, r)
+		next.ServeHTTP(w, r.WithContext(logging.WithRequestID(r.Context(), id)))
 	})
 }
 
-// Logging middleware
+// validRequestID reports whether a client-provided ID is short printable ASCII without spaces
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// Logging middleware logs each request with its status, response size and duration. Server
+// errors are logged at error level and client errors at warn level.
 func Logging(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 
-		// Create a response writer wrapper to capture status code
-		wrapped := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		wrapped := capture.NewWriter(w)
 
 		next.ServeHTTP(wrapped, r)
+		status := wrapped.StatusCode()
+
+		level := slog.LevelInfo
+		switch {
+		case status >= 500:
+			level = slog.LevelError
+		case status >= 400:
+			level = slog.LevelWarn
+		}
 
-		duration := time.Since(start)
-		log.Printf("%s %s %d %v", r.Method, r.URL.Path, wrapped.statusCode, duration)
-	})
-}
-
-// ContentType middleware
-func ContentType(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.Method == "POST" || r.Method == "PUT" {
-			contentType := r.Header.Get("Content-Type")
-			if contentType != "application/json" {
-				http.Error(w, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
-				return
-			}
+		remoteIP := r.RemoteAddr
+		if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+			remoteIP = host
 		}
 
-		next.ServeHTTP(w, r)
+		slog.LogAttrs(r.Context(), level, "request",
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", status),
+			slog.Int64("bytes", wrapped.Bytes),
+			slog.Duration("duration", time.Since(start)),
+			slog.String("remote_ip", remoteIP),
+			slog.String("user_agent", r.UserAgent()),
+		)
 	})
 }
 
-// RateLimit middleware (simple implementation)
-func RateLimit(requests int, window time.Duration) func(http.Handler) http.Handler {
-	type client struct {
-		requests int
-		window   time.Time
-	}
+// JSONBody rejects requests whose body is not declared as JSON. The router applies it to the
+// API routes that decode a JSON body, so mocked and proxied routes, raw content and attachment
+// uploads, GraphQL queries and bulk imports accept or check other body types.
+func JSONBody(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil || mediaType != "application/json" {
+			http.Error(w, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
+			return
+		}
 
-	clients := make(map[string]*client)
-
-	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			clientIP := r.RemoteAddr
-
-			c, exists := clients[clientIP]
-			if !exists {
-				clients[clientIP] = &client{requests: 1, window: time.Now()}
-				next.ServeHTTP(w, r)
-				return
-			}
-
-			if time.Since(c.window) > window {
-				c.requests = 1
-				c.window = time.Now()
-				next.ServeHTTP(w, r)
-				return
-			}
-
-			if c.requests >= requests {
-				http.Error(w, "Rate limit exceeded", http.StatusTooManyRequests)
-				return
-			}
-
-			c.requests++
-			next.ServeHTTP(w, r)
-		})
+		next(w, r)
 	}
 }
-
-// responseWriter is a wrapper to capture the status code
-type responseWriter struct {
-	http.ResponseWriter
-	statusCode int
-}
-
-func (rw *responseWriter) WriteHeader(code int) {
-	rw.statusCode = code
-	rw.ResponseWriter.WriteHeader(code)
-}
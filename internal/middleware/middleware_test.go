@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"mockj-go/internal/config"
+	"mockj-go/internal/logging"
+)
+
+func TestRequestID(t *testing.T) {
+	var seen string
+	handler := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = logging.RequestID(r.Context())
+		if r.Header.Get(RequestIDHeader) != seen {
+			t.Errorf("Expected the request header to carry %q, got %q", seen, r.Header.Get(RequestIDHeader))
+		}
+	}))
+
+	tests := []struct {
+		name     string
+		incoming string
+		kept     bool
+	}{
+		{"Honoured", "abc-123", true},
+		{"Generated", "", false},
+		{"TooLong", strings.Repeat("a", maxRequestIDLength+1), false},
+		{"Spaces", "a b", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := hit(handler, "GET", "/", "10.0.0.1:1", http.Header{RequestIDHeader: {tt.incoming}})
+			if seen == "" || w.Header().Get(RequestIDHeader) != seen {
+				t.Errorf("Expected the response to carry the request ID %q, got %q", seen, w.Header().Get(RequestIDHeader))
+			}
+			if kept := seen == tt.incoming; kept != tt.kept {
+				t.Errorf("Expected kept=%v for %q, got ID %q", tt.kept, tt.incoming, seen)
+			}
+		})
+	}
+}
+
+func TestJSONBody(t *testing.T) {
+	handler := JSONBody(ok)
+
+	tests := []struct {
+		name        string
+		contentType string
+		status      int
+	}{
+		{"JSON", "application/json", http.StatusOK},
+		{"JSONWithCharset", "application/json; charset=utf-8", http.StatusOK},
+		{"Text", "text/plain", http.StatusUnsupportedMediaType},
+		{"Missing", "", http.StatusUnsupportedMediaType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := hit(handler, "POST", "/api/json", "10.0.0.1:1", http.Header{"Content-Type": {tt.contentType}})
+			if w.Code != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, w.Code)
+			}
+		})
+	}
+}
+
+func TestLogging(t *testing.T) {
+	var buf bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(logging.New(&buf, config.LogConfig{Level: "info", Format: "json"}))
+
+	handler := RequestID(Logging(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte("not here"))
+	})))
+	hit(handler, "GET", "/missing", "203.0.113.9:1234", http.Header{
+		RequestIDHeader: {"req-7"},
+		"User-Agent":    {"tests/1.0"},
+	})
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("Expected a JSON log line, got %q: %v", buf.String(), err)
+	}
+	expected := map[string]interface{}{
+		"level":              "WARN",
+		"msg":                "request",
+		"method":             "GET",
+		"path":               "/missing",
+		"status":             float64(404),
+		"bytes":              float64(8),
+		"remote_ip":          "203.0.113.9",
+		"user_agent":         "tests/1.0",
+		logging.RequestIDKey: "req-7",
+	}
+	for key, value := range expected {
+		if record[key] != value {
+			t.Errorf("Expected %s %v, got %v", key, value, record[key])
+		}
+	}
+	if _, ok := record["duration"]; !ok {
+		t.Error("Expected the duration to be logged")
+	}
+}
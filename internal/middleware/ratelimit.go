@@ -0,0 +1,276 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"mockj-go/internal/config"
+)
+
+// Limit allows Requests per Window on average and up to Burst at once
+type Limit struct {
+	Requests int
+	Window   time.Duration
+	Burst    int
+}
+
+// rate returns the refill rate in tokens per second
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Window.Seconds()
+}
+
+// Decision is the outcome of taking a token from a bucket
+type Decision struct {
+	Allowed    bool
+	Remaining  int
+	Reset      time.Duration // Until the bucket is full again
+	RetryAfter time.Duration // Until the next token, when not allowed
+}
+
+// routeLimit applies a limit to requests whose path starts with Prefix
+type routeLimit struct {
+	Method string // Empty matches every method
+	Prefix string
+	Limit  Limit
+}
+
+// RateLimiter limits requests per client with token buckets. Clients are keyed by their
+// bearer token when it has a configured limit, otherwise by IP address. Route limits apply
+// in addition to the client's limit, in a bucket of their own.
+type RateLimiter struct {
+	limit    Limit
+	routes   []routeLimit // Longest prefix first
+	tokens   map[string]Limit
+	trusted  []*net.IPNet
+	buckets  Limiter
+	failOpen bool // Let requests through when the buckets cannot be reached
+}
+
+// RateLimit returns the rate limiting middleware configured by cfg, keeping its buckets
+// in limiter
+func RateLimit(cfg config.RateLimitConfig, limiter Limiter) (func(http.Handler) http.Handler, error) {
+	rateLimiter, err := NewRateLimiter(cfg, limiter)
+	if err != nil {
+		return nil, err
+	}
+	return rateLimiter.Middleware, nil
+}
+
+func NewRateLimiter(cfg config.RateLimitConfig, limiter Limiter) (*RateLimiter, error) {
+	if cfg.Requests < 1 || cfg.Window <= 0 {
+		return nil, fmt.Errorf("rate limit needs a positive number of requests and window")
+	}
+	l := &RateLimiter{
+		limit:    Limit{Requests: cfg.Requests, Window: cfg.Window, Burst: cfg.Burst},
+		tokens:   map[string]Limit{},
+		buckets:  limiter,
+		failOpen: cfg.FailOpen,
+	}
+	if l.limit.Burst <= 0 {
+		l.limit.Burst = l.limit.Requests
+	}
+
+	for _, proxy := range cfg.TrustedProxies {
+		if !strings.Contains(proxy, "/") {
+			if ip := net.ParseIP(proxy); ip != nil && ip.To4() != nil {
+				proxy += "/32"
+			} else {
+				proxy += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q", proxy)
+		}
+		l.trusted = append(l.trusted, network)
+	}
+
+	for route, spec := range cfg.Routes {
+		limit, err := parseLimit(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid limit for route %q: %w", route, err)
+		}
+		method, prefix, ok := strings.Cut(route, " ")
+		if !ok {
+			method, prefix = "", route
+		}
+		if !strings.HasPrefix(prefix, "/") {
+			return nil, fmt.Errorf("invalid route %q: must be [METHOD ]/path", route)
+		}
+		l.routes = append(l.routes, routeLimit{Method: strings.ToUpper(method), Prefix: prefix, Limit: limit})
+	}
+	sort.Slice(l.routes, func(i, j int) bool {
+		if len(l.routes[i].Prefix) != len(l.routes[j].Prefix) {
+			return len(l.routes[i].Prefix) > len(l.routes[j].Prefix)
+		}
+		// Method-specific rules win over rules for every method
+		return l.routes[i].Method > l.routes[j].Method
+	})
+
+	for token, spec := range cfg.Tokens {
+		limit, err := parseLimit(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid limit for a token: %w", err)
+		}
+		l.tokens[token] = limit
+	}
+
+	return l, nil
+}
+
+// parseLimit reads "requests/window", e.g. 10/1m
+func parseLimit(spec string) (Limit, error) {
+	requests, window, ok := strings.Cut(spec, "/")
+	n, err := strconv.Atoi(requests)
+	if !ok || err != nil || n < 1 {
+		return Limit{}, fmt.Errorf("%q must be requests/window, e.g. 10/1m", spec)
+	}
+	d, err := time.ParseDuration(window)
+	if err != nil || d <= 0 {
+		return Limit{}, fmt.Errorf("%q must be requests/window, e.g. 10/1m", spec)
+	}
+	return Limit{Requests: n, Window: d, Burst: n}, nil
+}
+
+// bucketCheck is one bucket a request takes a token from
+type bucketCheck struct {
+	kind  string // client or route, for logs; keys may derive from secrets
+	key   string
+	limit Limit
+}
+
+// Middleware rejects requests over the limit with 429 and reports the client's remaining
+// quota in RateLimit-* headers
+func (l *RateLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client, limit := l.client(r)
+
+		// A route bucket only ever tightens the client's limit
+		checks := []bucketCheck{{"client", client, limit}}
+		if route, ok := l.route(r); ok {
+			checks = append(checks, bucketCheck{"route", route.Method + " " + route.Prefix + "|" + client, route.Limit})
+		}
+
+		var (
+			decision Decision
+			applied  Limit
+		)
+		for i := len(checks) - 1; i >= 0; i-- {
+			d, err := l.buckets.Take(r.Context(), checks[i].key, checks[i].limit)
+			if err != nil {
+				slog.ErrorContext(r.Context(), "Rate limiting failed", "bucket", checks[i].kind, "fail_open", l.failOpen, "error", err)
+				if l.failOpen {
+					next.ServeHTTP(w, r)
+				} else {
+					http.Error(w, "Rate limiting unavailable", http.StatusServiceUnavailable)
+				}
+				return
+			}
+			if i == len(checks)-1 || !d.Allowed || d.Remaining < decision.Remaining {
+				decision, applied = d, checks[i].limit
+			}
+			if !d.Allowed {
+				break
+			}
+		}
+
+		setRateLimitHeaders(w, applied, decision)
+		if !decision.Allowed {
+			w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(decision.RetryAfter)))
+			http.Error(w, "Rate limit exceeded", http.StatusTooManyRequests)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func setRateLimitHeaders(w http.ResponseWriter, limit Limit, decision Decision) {
+	w.Header().Set("RateLimit-Limit", strconv.Itoa(limit.Requests))
+	w.Header().Set("RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+	w.Header().Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(decision.Reset)))
+	w.Header().Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d;burst=%d", limit.Requests, ceilSeconds(limit.Window), limit.Burst))
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// client returns the bucket key and limit of the request's client
+func (l *RateLimiter) client(r *http.Request) (string, Limit) {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		if limit, ok := l.tokens[token]; ok {
+			return tokenKey(token), limit
+		}
+	}
+	return "ip:" + l.clientIP(r), l.limit
+}
+
+// tokenKey returns the bucket key of a bearer token. Keys are stored, by the SQLite limiter
+// in the database, so they hold the token's SHA-256 rather than the token itself.
+func tokenKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return "token:" + hex.EncodeToString(sum[:])
+}
+
+// clientIP returns the remote address without its port. Behind trusted proxies it is the
+// last X-Forwarded-For entry that is not itself a trusted proxy.
+func (l *RateLimiter) clientIP(r *http.Request) string {
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		ip = host
+	}
+	if !l.isTrusted(ip) {
+		return ip
+	}
+
+	var forwarded []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		for _, entry := range strings.Split(header, ",") {
+			forwarded = append(forwarded, strings.TrimSpace(entry))
+		}
+	}
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		if net.ParseIP(forwarded[i]) == nil {
+			// Anything further left was not written by a proxy we trust
+			break
+		}
+		ip = forwarded[i]
+		if !l.isTrusted(ip) {
+			break
+		}
+	}
+	return ip
+}
+
+func (l *RateLimiter) isTrusted(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, network := range l.trusted {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// route returns the most specific route limit matching the request
+func (l *RateLimiter) route(r *http.Request) (routeLimit, bool) {
+	for _, route := range l.routes {
+		if (route.Method == "" || route.Method == r.Method) && strings.HasPrefix(r.URL.Path, route.Prefix) {
+			return route, true
+		}
+	}
+	return routeLimit{}, false
+}
@@ -0,0 +1,362 @@
+package middleware
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"mockj-go/internal/config"
+	"mockj-go/internal/database"
+)
+
+// clock returns a time that only moves when advanced
+func clock() (func() time.Time, func(time.Duration)) {
+	var mu sync.Mutex
+	now := time.Unix(1700000000, 0)
+	return func() time.Time {
+			mu.Lock()
+			defer mu.Unlock()
+			return now
+		}, func(d time.Duration) {
+			mu.Lock()
+			defer mu.Unlock()
+			now = now.Add(d)
+		}
+}
+
+// testLimiters returns a constructor of rate limiters for each limiter store, all on the
+// same clock. Rate limiters made by one constructor share their buckets, like replicas.
+func testLimiters(t *testing.T) (map[string]func(config.RateLimitConfig) *RateLimiter, func(time.Duration)) {
+	t.Helper()
+	now, advance := clock()
+
+	memory := NewMemoryLimiter()
+	memory.now = now
+
+	db, err := database.NewDatabase(filepath.Join(t.TempDir(), "mockj.db"))
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	sqlite := NewSQLiteLimiter(db)
+	sqlite.now = now
+
+	_, url := startRedisStandIn(t, "")
+	redis, err := NewRedisLimiter(url)
+	if err != nil {
+		t.Fatalf("Failed to create limiter: %v", err)
+	}
+	redis.now = now
+
+	constructors := map[string]func(config.RateLimitConfig) *RateLimiter{}
+	for name, limiter := range map[string]Limiter{LimiterMemory: memory, LimiterSQLite: sqlite, LimiterRedis: redis} {
+		constructors[name] = func(cfg config.RateLimitConfig) *RateLimiter {
+			rateLimiter, err := NewRateLimiter(cfg, limiter)
+			if err != nil {
+				t.Fatalf("Failed to create limiter: %v", err)
+			}
+			return rateLimiter
+		}
+	}
+	return constructors, advance
+}
+
+// testLimiter returns an in-memory rate limiter on a clock that only moves when advanced
+func testLimiter(t *testing.T, cfg config.RateLimitConfig) (*RateLimiter, func(time.Duration)) {
+	t.Helper()
+	now, advance := clock()
+	memory := NewMemoryLimiter()
+	memory.now = now
+	limiter, err := NewRateLimiter(cfg, memory)
+	if err != nil {
+		t.Fatalf("Failed to create limiter: %v", err)
+	}
+	return limiter, advance
+}
+
+func hit(handler http.Handler, method, path, remoteAddr string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	req.RemoteAddr = remoteAddr
+	for name, values := range header {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+var ok = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+func TestRateLimitBurstAndRefill(t *testing.T) {
+	constructors, advance := testLimiters(t)
+	for name, newLimiter := range constructors {
+		t.Run(name, func(t *testing.T) {
+			testBurstAndRefill(t, newLimiter, advance)
+		})
+	}
+}
+
+func testBurstAndRefill(t *testing.T, newLimiter func(config.RateLimitConfig) *RateLimiter, advance func(time.Duration)) {
+	cfg := config.RateLimitConfig{Requests: 2, Window: 2 * time.Second, Burst: 3}
+	handler := newLimiter(cfg).Middleware(ok)
+	// A second replica sharing the buckets
+	replica := newLimiter(cfg).Middleware(ok)
+
+	for i, h := range []http.Handler{handler, replica, handler} {
+		if w := hit(h, "GET", "/", "10.0.0.1:1234", nil); w.Code != http.StatusOK {
+			t.Fatalf("Request %d: expected status %d, got %d", i, http.StatusOK, w.Code)
+		}
+	}
+
+	w := hit(replica, "GET", "/", "10.0.0.1:1234", nil)
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected status %d after the burst, got %d", http.StatusTooManyRequests, w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "1" {
+		t.Errorf("Expected Retry-After 1, got %q", got)
+	}
+	expected := map[string]string{
+		"RateLimit-Limit":     "2",
+		"RateLimit-Remaining": "0",
+		"RateLimit-Reset":     "3",
+		"RateLimit-Policy":    "2;w=2;burst=3",
+	}
+	for name, value := range expected {
+		if got := w.Header().Get(name); got != value {
+			t.Errorf("Expected %s %q, got %q", name, value, got)
+		}
+	}
+
+	// Other clients have buckets of their own
+	if w := hit(handler, "GET", "/", "10.0.0.2:1234", nil); w.Code != http.StatusOK {
+		t.Errorf("Expected another client to be allowed, got %d", w.Code)
+	}
+
+	advance(time.Second)
+	if w := hit(handler, "GET", "/", "10.0.0.1:1234", nil); w.Code != http.StatusOK {
+		t.Errorf("Expected a refilled token to be allowed, got %d", w.Code)
+	}
+	if w := hit(handler, "GET", "/", "10.0.0.1:1234", nil); w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected the refilled token to be used up, got %d", w.Code)
+	}
+}
+
+func TestRateLimitConcurrent(t *testing.T) {
+	constructors, _ := testLimiters(t)
+	for name, newLimiter := range constructors {
+		t.Run(name, func(t *testing.T) {
+			testConcurrent(t, newLimiter(config.RateLimitConfig{Requests: 50, Window: time.Hour}).Middleware(ok))
+		})
+	}
+}
+
+func testConcurrent(t *testing.T, handler http.Handler) {
+	var allowed atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 200; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if hit(handler, "GET", "/", "10.0.0.1:1234", nil).Code == http.StatusOK {
+				allowed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if allowed.Load() != 50 {
+		t.Errorf("Expected 50 requests to be allowed, got %d", allowed.Load())
+	}
+}
+
+func TestRateLimitForwardedFor(t *testing.T) {
+	limiter, _ := testLimiter(t, config.RateLimitConfig{
+		Requests:       1,
+		Window:         time.Hour,
+		TrustedProxies: []string{"10.0.0.1", "192.168.0.0/16"},
+	})
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  string
+		expected   string
+	}{
+		{"Direct", "203.0.113.9:1234", "", "203.0.113.9"},
+		{"UntrustedProxy", "203.0.113.9:1234", "198.51.100.1", "203.0.113.9"},
+		{"TrustedProxy", "10.0.0.1:1234", "198.51.100.1", "198.51.100.1"},
+		{"ProxyChain", "10.0.0.1:1234", "1.1.1.1, 198.51.100.1, 192.168.1.5", "198.51.100.1"},
+		{"SpoofedLeftmost", "10.0.0.1:1234", "garbage, 198.51.100.1", "198.51.100.1"},
+		{"OnlyProxies", "10.0.0.1:1234", "192.168.1.5", "192.168.1.5"},
+		{"NoHeader", "10.0.0.1:1234", "", "10.0.0.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.forwarded != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			if got := limiter.clientIP(req); got != tt.expected {
+				t.Errorf("Expected client %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRateLimitRoutesAndTokens(t *testing.T) {
+	limiter, _ := testLimiter(t, config.RateLimitConfig{
+		Requests: 5,
+		Window:   time.Hour,
+		Routes: map[string]string{
+			"/api/json":      "3/1h",
+			"POST /api/json": "1/1h",
+		},
+		Tokens: map[string]string{"ci": "10/1h"},
+	})
+	handler := limiter.Middleware(ok)
+
+	if w := hit(handler, "POST", "/api/json", "10.0.0.1:1", nil); w.Code != http.StatusOK || w.Header().Get("RateLimit-Limit") != "1" {
+		t.Fatalf("Expected the POST route limit to apply, got %d with limit %q", w.Code, w.Header().Get("RateLimit-Limit"))
+	}
+	if w := hit(handler, "POST", "/api/json", "10.0.0.1:1", nil); w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected the second POST to be limited, got %d", w.Code)
+	}
+	for i := 0; i < 3; i++ {
+		if w := hit(handler, "GET", "/api/json/abc", "10.0.0.1:1", nil); w.Code != http.StatusOK {
+			t.Fatalf("GET %d: expected status %d, got %d", i, http.StatusOK, w.Code)
+		}
+	}
+	if w := hit(handler, "GET", "/api/json/abc", "10.0.0.1:1", nil); w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected the prefix route limit to apply, got %d", w.Code)
+	}
+
+	// The four allowed requests also counted against the client's own limit of 5
+	if w := hit(handler, "GET", "/health", "10.0.0.1:1", nil); w.Code != http.StatusOK || w.Header().Get("RateLimit-Remaining") != "0" {
+		t.Errorf("Expected the last token of the client limit, got %d with %q remaining", w.Code, w.Header().Get("RateLimit-Remaining"))
+	}
+	if w := hit(handler, "GET", "/health", "10.0.0.1:1", nil); w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected the client limit to be used up, got %d", w.Code)
+	}
+
+	token := http.Header{"Authorization": {"Bearer ci"}}
+	for i := 0; i < 10; i++ {
+		if w := hit(handler, "GET", "/health", "10.0.0.1:1", token); w.Code != http.StatusOK {
+			t.Fatalf("Token request %d: expected status %d, got %d", i, http.StatusOK, w.Code)
+		}
+	}
+	if w := hit(handler, "GET", "/health", "10.0.0.1:1", token); w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected the token limit to be used up, got %d", w.Code)
+	}
+
+	memory := limiter.buckets.(*MemoryLimiter)
+	memory.mu.Lock()
+	for key := range memory.buckets {
+		if strings.HasPrefix(key, "token:") && key != tokenKey("ci") {
+			t.Errorf("Expected the token to be keyed by its hash, got %q", key)
+		}
+	}
+	memory.mu.Unlock()
+
+	unknown := http.Header{"Authorization": {"Bearer other"}}
+	if w := hit(handler, "GET", "/health", "10.0.0.1:1", unknown); w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected an unknown token to share the IP's limit, got %d", w.Code)
+	}
+}
+
+func TestRateLimitEvictsIdleClients(t *testing.T) {
+	limiter, advance := testLimiter(t, config.RateLimitConfig{Requests: 10, Window: time.Minute})
+	handler := limiter.Middleware(ok)
+
+	hit(handler, "GET", "/", "10.0.0.1:1", nil)
+	advance(30 * time.Second)
+	for i := 0; i < 10; i++ {
+		hit(handler, "GET", "/", "10.0.0.2:1", nil)
+	}
+
+	// The first client has refilled by now, the second has not
+	advance(45 * time.Second)
+	hit(handler, "GET", "/", "10.0.0.3:1", nil)
+
+	memory := limiter.buckets.(*MemoryLimiter)
+	memory.mu.Lock()
+	defer memory.mu.Unlock()
+	if _, ok := memory.buckets["ip:10.0.0.1"]; ok {
+		t.Error("Expected the idle client to be evicted")
+	}
+	if _, ok := memory.buckets["ip:10.0.0.2"]; !ok {
+		t.Error("Expected the client still refilling to be kept")
+	}
+}
+
+func TestRateLimitInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.RateLimitConfig
+	}{
+		{"NoRequests", config.RateLimitConfig{Window: time.Minute}},
+		{"BadProxy", config.RateLimitConfig{Requests: 1, Window: time.Minute, TrustedProxies: []string{"proxy"}}},
+		{"BadRouteLimit", config.RateLimitConfig{Requests: 1, Window: time.Minute, Routes: map[string]string{"/api": "many"}}},
+		{"BadRoute", config.RateLimitConfig{Requests: 1, Window: time.Minute, Routes: map[string]string{"api": "1/1m"}}},
+		{"BadTokenLimit", config.RateLimitConfig{Requests: 1, Window: time.Minute, Tokens: map[string]string{"ci": "1/soon"}}},
+	}
+	for _, tt := range tests {
+		if _, err := RateLimit(tt.cfg, NewMemoryLimiter()); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
+
+func TestRateLimitStoreFailure(t *testing.T) {
+	db, err := database.NewDatabase(filepath.Join(t.TempDir(), "mockj.db"))
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	db.Close()
+
+	tests := []struct {
+		name     string
+		failOpen bool
+		status   int
+	}{
+		{"FailOpen", true, http.StatusOK},
+		{"FailClosed", false, http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.RateLimitConfig{Requests: 1, Window: time.Hour, FailOpen: tt.failOpen}
+			rateLimit, err := RateLimit(cfg, NewSQLiteLimiter(db))
+			if err != nil {
+				t.Fatalf("Failed to create limiter: %v", err)
+			}
+			if w := hit(rateLimit(ok), "GET", "/", "10.0.0.1:1", nil); w.Code != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, w.Code)
+			}
+		})
+	}
+
+	t.Run("LogsNoToken", func(t *testing.T) {
+		var logs bytes.Buffer
+		defer slog.SetDefault(slog.Default())
+		slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+
+		cfg := config.RateLimitConfig{Requests: 1, Window: time.Hour, Tokens: map[string]string{"s3cret-token": "1/1h"}}
+		rateLimit, err := RateLimit(cfg, NewSQLiteLimiter(db))
+		if err != nil {
+			t.Fatalf("Failed to create limiter: %v", err)
+		}
+		hit(rateLimit(ok), "GET", "/", "10.0.0.1:1", http.Header{"Authorization": {"Bearer s3cret-token"}})
+		if !strings.Contains(logs.String(), "Rate limiting failed") || strings.Contains(logs.String(), "s3cret-token") {
+			t.Errorf("Expected the failure to be logged without the token, got %s", logs.String())
+		}
+	})
+}
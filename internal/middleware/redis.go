@@ -0,0 +1,281 @@
+package middleware
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// redisTimeout bounds connecting and each command, so a hung server fails requests
+// instead of holding them
+const redisTimeout = 2 * time.Second
+
+// redisIdleConns is the number of idle connections kept for reuse
+const redisIdleConns = 16
+
+// redisKeyPrefix namespaces the buckets in a server shared with other applications
+const redisKeyPrefix = "mockj:ratelimit:"
+
+// takeScript refills and takes from a bucket in one step, so replicas sharing the server
+// share the bucket. Buckets expire once they have refilled; they equal a new bucket then.
+// Like the SQLite store, a replica whose clock is behind never moves a bucket back in time.
+const takeScript = `
+local burst = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'updated')
+local tokens = tonumber(bucket[1])
+local updated = tonumber(bucket[2])
+if tokens == nil or updated == nil then
+	tokens, updated = burst, now
+end
+tokens = math.min(burst, tokens + math.max(0, now - updated) * rate)
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'updated', tostring(math.max(updated, now)))
+redis.call('PEXPIRE', KEYS[1], math.ceil((burst - tokens) / rate * 1000) + 1000)
+return {allowed, tostring(tokens)}
+`
+
+// RedisLimiter keeps buckets in a Redis server, or any server speaking its protocol and
+// running Lua scripts, so replicas on every host using it share their limits
+type RedisLimiter struct {
+	addr     string
+	username string
+	password string
+	db       int
+	now      func() time.Time
+
+	idle chan *redisConn
+}
+
+// NewRedisLimiter returns a limiter for the server at redis://[user:password@]host[:port][/db].
+// Connections are made on first use.
+func NewRedisLimiter(rawURL string) (*RedisLimiter, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "redis" || u.Hostname() == "" {
+		return nil, fmt.Errorf("invalid redis URL: must be redis://[user:password@]host[:port][/db]")
+	}
+
+	l := &RedisLimiter{
+		addr: u.Host,
+		now:  time.Now,
+		idle: make(chan *redisConn, redisIdleConns),
+	}
+	if u.Port() == "" {
+		l.addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	if u.User != nil {
+		l.username = u.User.Username()
+		l.password, _ = u.User.Password()
+	}
+	if db := strings.TrimPrefix(u.Path, "/"); db != "" {
+		if l.db, err = strconv.Atoi(db); err != nil || l.db < 0 {
+			return nil, fmt.Errorf("invalid redis database %q", db)
+		}
+	}
+	return l, nil
+}
+
+func (l *RedisLimiter) Take(ctx context.Context, key string, limit Limit) (Decision, error) {
+	reply, err := l.do(ctx, "EVAL", takeScript, "1", redisKeyPrefix+key,
+		strconv.Itoa(limit.Burst), formatFloat(limit.rate()), formatFloat(unixSeconds(l.now())))
+	if err != nil {
+		return Decision{}, fmt.Errorf("failed to take rate limit token: %w", err)
+	}
+
+	values, ok := reply.([]interface{})
+	if !ok || len(values) != 2 {
+		return Decision{}, fmt.Errorf("failed to take rate limit token: unexpected reply %v", reply)
+	}
+	allowed, ok := values[0].(int64)
+	if !ok {
+		return Decision{}, fmt.Errorf("failed to take rate limit token: unexpected reply %v", reply)
+	}
+	text, _ := values[1].(string)
+	tokens, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return Decision{}, fmt.Errorf("failed to take rate limit token: unexpected reply %v", reply)
+	}
+	return decide(tokens, allowed == 1, limit), nil
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func unixSeconds(t time.Time) float64 {
+	return float64(t.UnixNano()) / float64(time.Second)
+}
+
+// do sends one command and returns its reply. Error replies come back as redisError and
+// leave the connection usable; any other failure closes it.
+func (l *RedisLimiter) do(ctx context.Context, args ...string) (interface{}, error) {
+	c, err := l.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := c.do(ctx, args...)
+	var replyErr redisError
+	if err != nil && !errors.As(err, &replyErr) {
+		c.Close()
+		return nil, err
+	}
+
+	select {
+	case l.idle <- c:
+	default:
+		c.Close()
+	}
+	return reply, err
+}
+
+// conn returns an idle connection or dials a new one
+func (l *RedisLimiter) conn(ctx context.Context) (*redisConn, error) {
+	select {
+	case c := <-l.idle:
+		return c, nil
+	default:
+	}
+
+	dialer := net.Dialer{Timeout: redisTimeout}
+	netConn, err := dialer.DialContext(ctx, "tcp", l.addr)
+	if err != nil {
+		return nil, err
+	}
+	c := &redisConn{Conn: netConn, reader: bufio.NewReader(netConn)}
+
+	var setup [][]string
+	if l.password != "" {
+		if l.username != "" {
+			setup = append(setup, []string{"AUTH", l.username, l.password})
+		} else {
+			setup = append(setup, []string{"AUTH", l.password})
+		}
+	}
+	if l.db != 0 {
+		setup = append(setup, []string{"SELECT", strconv.Itoa(l.db)})
+	}
+	for _, args := range setup {
+		if _, err := c.do(ctx, args...); err != nil {
+			c.Close()
+			return nil, fmt.Errorf("failed to %s: %w", strings.ToLower(args[0]), err)
+		}
+	}
+	return c, nil
+}
+
+// redisError is an error reply of the server
+type redisError string
+
+func (e redisError) Error() string {
+	return string(e)
+}
+
+// redisConn speaks RESP, the protocol of Redis, over one connection
+type redisConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *redisConn) do(ctx context.Context, args ...string) (interface{}, error) {
+	deadline := time.Now().Add(redisTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err := c.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	if _, err := c.Write(encodeCommand(args)); err != nil {
+		return nil, err
+	}
+	return readReply(c.reader)
+}
+
+// encodeCommand writes a command as an array of bulk strings
+func encodeCommand(args []string) []byte {
+	buf := fmt.Appendf(nil, "*%d\r\n", len(args))
+	for _, arg := range args {
+		buf = fmt.Appendf(buf, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	return buf
+}
+
+// readReply reads one reply: strings, integers and nil as themselves, arrays as
+// []interface{} and error replies as a redisError
+func readReply(r *bufio.Reader) (interface{}, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || !strings.HasSuffix(line, "\r\n") {
+		return nil, fmt.Errorf("invalid reply %q", line)
+	}
+	kind, body := line[0], line[1:len(line)-2]
+
+	switch kind {
+	case '+':
+		return body, nil
+	case '-':
+		return nil, redisError(body)
+	case ':':
+		n, err := strconv.ParseInt(body, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer reply %q", body)
+		}
+		return n, nil
+	case '$':
+		n, err := strconv.Atoi(body)
+		if err != nil || n < -1 {
+			return nil, fmt.Errorf("invalid bulk string length %q", body)
+		}
+		if n == -1 {
+			return nil, nil
+		}
+		data := make([]byte, n+2)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+		return string(data[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(body)
+		if err != nil || n < -1 {
+			return nil, fmt.Errorf("invalid array length %q", body)
+		}
+		if n == -1 {
+			return nil, nil
+		}
+		// An error reply inside the array fails the command once the rest is read
+		values := make([]interface{}, n)
+		var replyErr error
+		for i := range values {
+			values[i], err = readReply(r)
+			var elementErr redisError
+			if errors.As(err, &elementErr) {
+				if replyErr == nil {
+					replyErr = err
+				}
+			} else if err != nil {
+				return nil, err
+			}
+		}
+		if replyErr != nil {
+			return nil, replyErr
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("invalid reply %q", line)
+	}
+}
@@ -0,0 +1,192 @@
+package middleware
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// redisStandIn speaks enough of the Redis protocol for RedisLimiter, running the token
+// bucket of takeScript in Go
+type redisStandIn struct {
+	password string
+
+	mu       sync.Mutex
+	buckets  map[string][2]float64 // Tokens and last update
+	selected []string              // Databases selected, one per connection
+	fail     bool                  // Answer EVAL with an error
+}
+
+// startRedisStandIn serves a stand-in until the test ends and returns its URL, with the
+// password if there is one
+func startRedisStandIn(t *testing.T, password string) (*redisStandIn, string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	s := &redisStandIn{password: password, buckets: map[string][2]float64{}}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+
+	if password != "" {
+		return s, "redis://:" + password + "@" + listener.Addr().String() + "/2"
+	}
+	return s, "redis://" + listener.Addr().String()
+}
+
+func (s *redisStandIn) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	authenticated := s.password == ""
+
+	for {
+		command, err := readReply(reader)
+		if err != nil {
+			return
+		}
+		values, _ := command.([]interface{})
+		args := make([]string, len(values))
+		for i, value := range values {
+			args[i], _ = value.(string)
+		}
+
+		var reply string
+		switch {
+		case len(args) == 2 && args[0] == "AUTH":
+			authenticated = args[1] == s.password
+			reply = "+OK\r\n"
+			if !authenticated {
+				reply = "-WRONGPASS invalid password\r\n"
+			}
+		case !authenticated:
+			reply = "-NOAUTH Authentication required\r\n"
+		case len(args) == 2 && args[0] == "SELECT":
+			s.mu.Lock()
+			s.selected = append(s.selected, args[1])
+			s.mu.Unlock()
+			reply = "+OK\r\n"
+		case len(args) == 7 && args[0] == "EVAL" && args[1] == takeScript:
+			reply = s.take(args[3], args[4], args[5], args[6])
+		default:
+			reply = "-ERR unknown command\r\n"
+		}
+		if _, err := conn.Write([]byte(reply)); err != nil {
+			return
+		}
+	}
+}
+
+func (s *redisStandIn) take(key, burstArg, rateArg, nowArg string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fail {
+		return "-ERR script failed\r\n"
+	}
+
+	burst, _ := strconv.ParseFloat(burstArg, 64)
+	rate, _ := strconv.ParseFloat(rateArg, 64)
+	now, _ := strconv.ParseFloat(nowArg, 64)
+	bucket, ok := s.buckets[key]
+	if !ok {
+		bucket = [2]float64{burst, now}
+	}
+	tokens := math.Min(burst, bucket[0]+math.Max(0, now-bucket[1])*rate)
+	allowed := 0
+	if tokens >= 1 {
+		tokens--
+		allowed = 1
+	}
+	s.buckets[key] = [2]float64{tokens, math.Max(bucket[1], now)}
+
+	text := strconv.FormatFloat(tokens, 'g', 14, 64)
+	return fmt.Sprintf("*2\r\n:%d\r\n$%d\r\n%s\r\n", allowed, len(text), text)
+}
+
+func TestRedisLimiter(t *testing.T) {
+	t.Run("AuthenticatesAndSelects", func(t *testing.T) {
+		standIn, url := startRedisStandIn(t, "s3cret")
+		limiter, err := NewRedisLimiter(url)
+		if err != nil {
+			t.Fatalf("Failed to create limiter: %v", err)
+		}
+
+		limit := Limit{Requests: 2, Window: time.Minute, Burst: 2}
+		for i, allowed := range []bool{true, true, false} {
+			decision, err := limiter.Take(t.Context(), "ip:10.0.0.1", limit)
+			if err != nil {
+				t.Fatalf("Take %d: %v", i, err)
+			}
+			if decision.Allowed != allowed {
+				t.Errorf("Take %d: expected allowed %v, got %+v", i, allowed, decision)
+			}
+		}
+
+		standIn.mu.Lock()
+		defer standIn.mu.Unlock()
+		if _, ok := standIn.buckets[redisKeyPrefix+"ip:10.0.0.1"]; !ok {
+			t.Errorf("Expected a namespaced bucket, got %v", standIn.buckets)
+		}
+		if strings.Join(standIn.selected, ",") != "2" {
+			t.Errorf("Expected one connection selecting database 2, got %v", standIn.selected)
+		}
+	})
+
+	t.Run("ErrorReplies", func(t *testing.T) {
+		standIn, url := startRedisStandIn(t, "")
+		limiter, err := NewRedisLimiter(url)
+		if err != nil {
+			t.Fatalf("Failed to create limiter: %v", err)
+		}
+		limit := Limit{Requests: 1, Window: time.Minute, Burst: 1}
+
+		standIn.mu.Lock()
+		standIn.fail = true
+		standIn.mu.Unlock()
+		if _, err := limiter.Take(t.Context(), "ip:10.0.0.1", limit); err == nil || !strings.Contains(err.Error(), "script failed") {
+			t.Errorf("Expected the error reply, got %v", err)
+		}
+
+		// The connection stays usable after an error reply
+		standIn.mu.Lock()
+		standIn.fail = false
+		standIn.mu.Unlock()
+		if decision, err := limiter.Take(t.Context(), "ip:10.0.0.1", limit); err != nil || !decision.Allowed {
+			t.Errorf("Expected a token, got %+v, %v", decision, err)
+		}
+	})
+
+	t.Run("WrongPassword", func(t *testing.T) {
+		_, url := startRedisStandIn(t, "s3cret")
+		limiter, err := NewRedisLimiter(strings.Replace(url, "s3cret", "wrong", 1))
+		if err != nil {
+			t.Fatalf("Failed to create limiter: %v", err)
+		}
+		if _, err := limiter.Take(t.Context(), "ip:10.0.0.1", Limit{Requests: 1, Window: time.Minute, Burst: 1}); err == nil {
+			t.Error("Expected authentication to fail")
+		}
+	})
+
+	t.Run("InvalidURL", func(t *testing.T) {
+		for _, url := range []string{"localhost:6379", "http://localhost", "redis://", "redis://localhost/db"} {
+			if _, err := NewRedisLimiter(url); err == nil {
+				t.Errorf("%q: expected an error", url)
+			}
+		}
+	})
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Attachment is a binary file attached to a mock. Its bytes live in the attachment store.
+type Attachment struct {
+	ID          string    `json:"id" db:"id"`
+	MockID      string    `json:"mockId" db:"json_id"`
+	Filename    string    `json:"filename" db:"filename"`
+	ContentType string    `json:"contentType" db:"content_type"`
+	Size        int64     `json:"size" db:"size"`
+	SHA256      string    `json:"sha256" db:"sha256"`
+	CreatedAt   time.Time `json:"createdAt" db:"created_at"`
+}
+
+// NewAttachment creates attachment metadata with a new ID
+func NewAttachment(mockID, filename, contentType string, size int64, sha256 string) *Attachment {
+	return &Attachment{
+		ID:          uuid.New().String(),
+		MockID:      mockID,
+		Filename:    filename,
+		ContentType: contentType,
+		Size:        size,
+		SHA256:      sha256,
+		CreatedAt:   time.Now(),
+	}
+}
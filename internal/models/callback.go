@@ -0,0 +1,128 @@
+package models
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Limits that keep callbacks from piling up
+const (
+	MaxCallbacks       = 10
+	MaxCallbackDelayMs = 5 * 60 * 1000
+)
+
+// CallbackPlaceholder matches the {{name}} placeholders of callback templates
+var CallbackPlaceholder = regexp.MustCompile(`\{\{\s*([^{}\s]+)\s*\}\}`)
+
+// Callback is a request a mock sends after serving a hit, such as a payment provider
+// calling the merchant back. URL, Body and header values are templates whose placeholders
+// are filled from the hit: {{method}}, {{path}}, {{mockId}}, {{params.name}} for route
+// parameters, {{query.name}}, {{header.Name}}, and {{body}} or {{body.field.0.name}} for
+// the request body or a value inside its JSON.
+type Callback struct {
+	URL     string            `json:"url"`
+	Method  string            `json:"method,omitempty"` // POST when empty
+	Body    string            `json:"body,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	DelayMs int               `json:"delayMs,omitempty"`
+}
+
+// RequestMethod returns the callback's method, POST when unset
+func (c *Callback) RequestMethod() string {
+	if c.Method == "" {
+		return http.MethodPost
+	}
+	return strings.ToUpper(c.Method)
+}
+
+// ValidateCallbacks checks the callbacks of a mock
+func ValidateCallbacks(callbacks []Callback) error {
+	if len(callbacks) > MaxCallbacks {
+		return fmt.Errorf("a mock has at most %d callbacks", MaxCallbacks)
+	}
+
+	for i, callback := range callbacks {
+		if err := validateCallbackURL(callback.URL); err != nil {
+			return fmt.Errorf("callback %d: %w", i, err)
+		}
+		if err := ValidateMethod(strings.ToUpper(callback.Method)); err != nil {
+			return fmt.Errorf("callback %d: %w", i, err)
+		}
+		if callback.DelayMs < 0 || callback.DelayMs > MaxCallbackDelayMs {
+			return fmt.Errorf("callback %d: delayMs must be between 0 and %d", i, MaxCallbackDelayMs)
+		}
+
+		templates := []string{callback.URL, callback.Body}
+		for _, value := range callback.Headers {
+			templates = append(templates, value)
+		}
+		for _, template := range templates {
+			if err := validatePlaceholders(template); err != nil {
+				return fmt.Errorf("callback %d: %w", i, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateCallbackURL checks that a callback URL is absolute http or https with a fixed
+// host. Placeholders may only fill in the path and query, where their values are escaped.
+func validateCallbackURL(template string) error {
+	scheme, rest, ok := strings.Cut(template, "://")
+	if !ok || (scheme != "http" && scheme != "https") {
+		return fmt.Errorf("url must be an absolute http or https URL")
+	}
+	authority := rest
+	if end := strings.IndexAny(rest, "/?#"); end >= 0 {
+		authority = rest[:end]
+	}
+	if strings.Contains(authority, "{{") {
+		return fmt.Errorf("url placeholders may not fill in the host")
+	}
+
+	parsed, err := url.Parse(CallbackPlaceholder.ReplaceAllString(template, "x"))
+	if err != nil {
+		return fmt.Errorf("invalid url: %w", err)
+	}
+	if parsed.Host == "" {
+		return fmt.Errorf("url must have a host")
+	}
+	return nil
+}
+
+func validatePlaceholders(template string) error {
+	for _, match := range CallbackPlaceholder.FindAllStringSubmatch(template, -1) {
+		name := match[1]
+		switch root, key, _ := strings.Cut(name, "."); root {
+		case "method", "path", "mockId":
+			if key == "" {
+				continue
+			}
+		case "body":
+			continue
+		case "params", "query", "header":
+			if key != "" {
+				continue
+			}
+		}
+		return fmt.Errorf("unknown placeholder {{%s}}", name)
+	}
+	return nil
+}
+
+// CallbackResult logs the outcome of one callback request
+type CallbackResult struct {
+	ID         int64     `json:"id" db:"id"`
+	MockID     string    `json:"mockId" db:"json_id"`
+	Method     string    `json:"method" db:"method"`
+	URL        string    `json:"url" db:"url"`
+	Status     int       `json:"status,omitempty" db:"status"` // 0 when no response arrived
+	Error      string    `json:"error,omitempty" db:"error"`
+	DurationMs int64     `json:"durationMs" db:"duration_ms"`
+	Timestamp  time.Time `json:"timestamp" db:"created_at"`
+}
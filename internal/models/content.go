@@ -0,0 +1,153 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Content types of a mock. Binary content is stored base64-encoded.
+const (
+	ContentTypeJSON   = "json"
+	ContentTypeYAML   = "yaml"
+	ContentTypeXML    = "xml"
+	ContentTypeText   = "text"
+	ContentTypeCSV    = "csv"
+	ContentTypeBinary = "binary"
+)
+
+// mediaTypes maps content types to the Content-Type they are served with
+var mediaTypes = map[string]string{
+	ContentTypeJSON:   "application/json",
+	ContentTypeYAML:   "application/yaml",
+	ContentTypeXML:    "application/xml",
+	ContentTypeText:   "text/plain; charset=utf-8",
+	ContentTypeCSV:    "text/csv; charset=utf-8",
+	ContentTypeBinary: "application/octet-stream",
+}
+
+// ValidateContent checks that content is well-formed for its content type, empty meaning JSON
+func ValidateContent(contentType, content string) error {
+	switch contentType {
+	case "", ContentTypeJSON:
+		if !json.Valid([]byte(content)) {
+			return fmt.Errorf("content is not valid JSON")
+		}
+	case ContentTypeYAML:
+		var value interface{}
+		if err := yaml.Unmarshal([]byte(content), &value); err != nil {
+			return fmt.Errorf("content is not valid YAML: %v", err)
+		}
+	case ContentTypeXML:
+		if err := validateXML(content); err != nil {
+			return fmt.Errorf("content is not valid XML: %v", err)
+		}
+	case ContentTypeText:
+		if !utf8.ValidString(content) {
+			return fmt.Errorf("text content must be valid UTF-8")
+		}
+	case ContentTypeCSV:
+		reader := csv.NewReader(strings.NewReader(content))
+		if _, err := reader.ReadAll(); err != nil {
+			return fmt.Errorf("content is not valid CSV: %v", err)
+		}
+	case ContentTypeBinary:
+		if _, err := base64.StdEncoding.DecodeString(content); err != nil {
+			return fmt.Errorf("binary content must be base64-encoded")
+		}
+	default:
+		return fmt.Errorf("invalid content type %q: must be json, yaml, xml, text, csv or binary", contentType)
+	}
+	return nil
+}
+
+// validateXML checks that content is a single well-formed XML document
+func validateXML(content string) error {
+	decoder := xml.NewDecoder(strings.NewReader(content))
+	roots := 0
+	depth := 0
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if depth == 0 {
+				roots++
+			}
+			depth++
+		case xml.EndElement:
+			depth--
+		case xml.CharData:
+			if depth == 0 && strings.TrimSpace(string(t)) != "" {
+				return fmt.Errorf("text outside the root element")
+			}
+		}
+	}
+
+	if roots != 1 {
+		return fmt.Errorf("expected one root element, found %d", roots)
+	}
+	return nil
+}
+
+// ContentTypeOf maps a media type such as "text/csv; charset=utf-8" to a content type.
+// Unknown media types are binary.
+func ContentTypeOf(mediaType string) string {
+	mediaType, _, err := mime.ParseMediaType(mediaType)
+	if err != nil {
+		return ContentTypeBinary
+	}
+
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		return ContentTypeJSON
+	case mediaType == "application/yaml" || mediaType == "application/x-yaml" || mediaType == "text/yaml":
+		return ContentTypeYAML
+	case mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml"):
+		return ContentTypeXML
+	case mediaType == "text/csv":
+		return ContentTypeCSV
+	case strings.HasPrefix(mediaType, "text/"):
+		return ContentTypeText
+	}
+	return ContentTypeBinary
+}
+
+// EncodeContent stores a raw body as content of the given content type, base64-encoding
+// binary bodies and text bodies that are not valid UTF-8
+func EncodeContent(body []byte, contentType string) (content, storedType string) {
+	if contentType != ContentTypeBinary && utf8.Valid(body) {
+		return string(body), contentType
+	}
+	return base64.StdEncoding.EncodeToString(body), ContentTypeBinary
+}
+
+// MediaType returns the Content-Type the mock is served with by default
+func (j *JSON) MediaType() string {
+	if mediaType, ok := mediaTypes[j.ContentType]; ok {
+		return mediaType
+	}
+	return mediaTypes[ContentTypeJSON]
+}
+
+// Body returns the bytes served for the mock, decoding binary content
+func (j *JSON) Body() ([]byte, error) {
+	if j.ContentType == ContentTypeBinary {
+		return base64.StdEncoding.DecodeString(j.Content)
+	}
+	return []byte(j.Content), nil
+}
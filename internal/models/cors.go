@@ -0,0 +1,103 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CORS is a cross-origin resource sharing policy, either the server's default or the
+// override of a single mock
+type CORS struct {
+	AllowedOrigins   []string `json:"allowedOrigins"`           // Exact origins or patterns such as https://*.example.com; * allows any
+	AllowedMethods   []string `json:"allowedMethods,omitempty"` // Methods allowed by preflight responses
+	AllowedHeaders   []string `json:"allowedHeaders,omitempty"` // Request headers allowed by preflight responses; * allows any
+	ExposedHeaders   []string `json:"exposedHeaders,omitempty"` // Response headers scripts may read
+	AllowCredentials bool     `json:"allowCredentials,omitempty"`
+	MaxAge           int      `json:"maxAge,omitempty"` // Seconds browsers may cache preflight responses
+}
+
+// Validate checks that the policy is usable
+func (c *CORS) Validate() error {
+	if len(c.AllowedOrigins) == 0 {
+		return fmt.Errorf("cors allowedOrigins must not be empty")
+	}
+	for _, origin := range c.AllowedOrigins {
+		if origin != "*" && !strings.Contains(origin, "://") {
+			return fmt.Errorf("cors origin %q must be * or include a scheme, e.g. https://example.com", origin)
+		}
+	}
+
+	if c.AllowCredentials && c.AllowsAnyOrigin() {
+		// Echoing every origin with credentials would let any site act as the user
+		return fmt.Errorf("cors allowCredentials cannot be combined with allowedOrigins *; list the origins instead")
+	}
+
+	for _, method := range c.AllowedMethods {
+		if method == "" || strings.ContainsAny(method, " ,") {
+			return fmt.Errorf("invalid cors method %q", method)
+		}
+	}
+
+	if c.MaxAge < 0 {
+		return fmt.Errorf("cors maxAge must not be negative")
+	}
+
+	return nil
+}
+
+// AllowsAnyOrigin reports whether the policy allows every origin
+func (c *CORS) AllowsAnyOrigin() bool {
+	for _, pattern := range c.AllowedOrigins {
+		if pattern == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsOrigin reports whether the Origin header value matches one of the allowed origins.
+// Origins are compared case-insensitively, and * in a pattern matches any run of characters.
+func (c *CORS) AllowsOrigin(origin string) bool {
+	origin = strings.ToLower(origin)
+	for _, pattern := range c.AllowedOrigins {
+		if matchWildcard(strings.ToLower(pattern), origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsAnyHeader reports whether the policy allows every request header
+func (c *CORS) AllowsAnyHeader() bool {
+	for _, header := range c.AllowedHeaders {
+		if header == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// matchWildcard matches s against a pattern in which * stands for any run of characters.
+// The literal pieces between stars are matched in order, each at its first occurrence,
+// which takes time linear in the input rather than backtracking.
+func matchWildcard(pattern, s string) bool {
+	pieces := strings.Split(pattern, "*")
+	if len(pieces) == 1 {
+		return pattern == s
+	}
+
+	first, last := pieces[0], pieces[len(pieces)-1]
+	if len(s) < len(first)+len(last) || !strings.HasPrefix(s, first) || !strings.HasSuffix(s, last) {
+		return false
+	}
+	s = s[len(first) : len(s)-len(last)]
+
+	for _, piece := range pieces[1 : len(pieces)-1] {
+		i := strings.Index(s, piece)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(piece):]
+	}
+	return true
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// Fault types supported when serving mock content
+const (
+	FaultConnectionReset = "connection_reset"
+	FaultEmptyResponse   = "empty_response"
+	FaultTruncatedBody   = "truncated_body"
+	FaultMalformedJSON   = "malformed_json"
+	FaultSlowBody        = "slow_body"
+)
+
+// DefaultBytesPerSecond is the trickle rate used by slow_body when none is set
+const DefaultBytesPerSecond = 16
+
+// Fault describes how a mock misbehaves when its content is served
+type Fault struct {
+	Type           string   `json:"type"`
+	BytesPerSecond int      `json:"bytesPerSecond,omitempty"`
+	Probability    *float64 `json:"probability,omitempty"` // Omitted means always, 0 never
+}
+
+// Validate checks that the fault configuration is usable
+func (f *Fault) Validate() error {
+	switch f.Type {
+	case FaultConnectionReset, FaultEmptyResponse, FaultTruncatedBody, FaultMalformedJSON, FaultSlowBody:
+	default:
+		return fmt.Errorf("unknown fault type %q", f.Type)
+	}
+
+	if f.Probability != nil && (*f.Probability < 0 || *f.Probability > 1) {
+		return fmt.Errorf("fault probability must be between 0 and 1")
+	}
+
+	if f.BytesPerSecond < 0 {
+		return fmt.Errorf("fault bytesPerSecond must not be negative")
+	}
+
+	return nil
+}
+
+// ShouldApply decides whether the fault triggers for the current request
+func (f *Fault) ShouldApply() bool {
+	if f.Probability == nil || *f.Probability >= 1 {
+		return true
+	}
+	return rand.Float64() < *f.Probability
+}
+
+// Rate returns the trickle rate in bytes per second for slow_body
+func (f *Fault) Rate() int {
+	if f.BytesPerSecond > 0 {
+		return f.BytesPerSecond
+	}
+	return DefaultBytesPerSecond
+}
@@ -10,12 +10,22 @@ import (
 
 // JSON represents a JSON entity in the database
 type JSON struct {
-	ID         string    `json:"id" db:"id"`
-	Content    string    `json:"json" db:"json"`
-	Password   string    `json:"-" db:"password"` // Never include password in JSON responses
-	CreatedAt  time.Time `json:"createdAt" db:"created_at"`
-	ModifiedAt time.Time `json:"modifiedAt" db:"modified_at"`
-	Expires    time.Time `json:"expires" db:"expires"`
+	ID            string            `json:"id" db:"id"`
+	Content       string            `json:"json" db:"json"`
+	ContentType   string            `json:"contentType,omitempty" db:"content_type"` // Empty means JSON
+	Password      string            `json:"-" db:"password"`                         // Never include password in JSON responses
+	CreatedAt     time.Time         `json:"createdAt" db:"created_at"`
+	ModifiedAt    time.Time         `json:"modifiedAt" db:"modified_at"`
+	Expires       time.Time         `json:"expires" db:"expires"`
+	Fault         *Fault            `json:"fault,omitempty" db:"fault"`
+	Method        string            `json:"method,omitempty" db:"method"` // Empty binds every method
+	Route         string            `json:"route,omitempty" db:"route"`   // Path pattern such as /users/{id}
+	Status        int               `json:"status,omitempty" db:"status"` // Response status, 200 when zero
+	Headers       map[string]string `json:"headers,omitempty" db:"headers"`
+	GraphQLSchema string            `json:"graphqlSchema,omitempty" db:"graphql_schema"` // SDL answered from Content
+	WebSocket     *WebSocketScript  `json:"websocket,omitempty" db:"websocket"`
+	Callbacks     []Callback        `json:"callbacks,omitempty" db:"callbacks"` // Requests sent after each hit
+	CORS          *CORS             `json:"cors,omitempty" db:"cors"`           // Overrides the server's CORS policy
 }
 
 // JSONData represents the JSON content with proper validation
@@ -54,11 +64,27 @@ func NewJSON(content, password string) *JSON {
 		Password:   password,
 		CreatedAt:  now,
 		ModifiedAt: now,
-		Expires:    now.AddDate(0, 0, 60), // Default 60 days
+		Expires:    DefaultExpires(now),
 	}
 }
 
+// ResponseStatus returns the status code the mock responds with
+func (j *JSON) ResponseStatus() int {
+	if j.Status == 0 {
+		return 200
+	}
+	return j.Status
+}
+
 // IsExpired checks if the JSON entity has expired
 func (j *JSON) IsExpired() bool {
 	return time.Now().After(j.Expires)
 }
+
+// DefaultExpires returns when a mock created at now expires unless told otherwise, 60 days later
+func DefaultExpires(now time.Time) time.Time {
+	return now.AddDate(0, 0, 60)
+}
+
+// NeverExpires is the expiry of mocks that stay until they are deleted, such as seeded ones
+var NeverExpires = time.Date(9999, 12, 31, 23, 59, 59, 0, time.UTC)
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"net/http"
+	"time"
+)
+
+// RequestLog is a journal entry recording one request served by a mock
+type RequestLog struct {
+	ID        int64       `json:"id" db:"id"`
+	MockID    string      `json:"mockId" db:"json_id"`
+	Method    string      `json:"method" db:"method"`
+	Path      string      `json:"path" db:"path"`
+	Query     string      `json:"query" db:"query"`
+	Headers   http.Header `json:"headers" db:"headers"`
+	Body      string      `json:"body" db:"body"`
+	Status    int         `json:"status" db:"status"` // 0 when the connection was dropped without a response
+	Timestamp time.Time   `json:"timestamp" db:"created_at"`
+}
+
+// RequestFilter narrows down journal queries; zero values match everything
+type RequestFilter struct {
+	MockID string
+	Method string
+	Status int
+	Since  time.Time
+	Until  time.Time
+	Limit  int
+}
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ValidateRoute checks that a route pattern is an absolute path whose parameters
+// are whole segments like {id}, with an optional trailing * wildcard segment
+func ValidateRoute(route string) error {
+	if !strings.HasPrefix(route, "/") {
+		return fmt.Errorf("route must start with /")
+	}
+
+	segments := strings.Split(route[1:], "/")
+	for i, segment := range segments {
+		if segment == "*" {
+			if i != len(segments)-1 {
+				return fmt.Errorf("wildcard * must be the last route segment")
+			}
+			continue
+		}
+
+		if strings.ContainsAny(segment, "{}") && !isRouteParam(segment) {
+			return fmt.Errorf("invalid route segment %q", segment)
+		}
+	}
+
+	return nil
+}
+
+// ValidateMethod checks that method is empty or a known HTTP method
+func ValidateMethod(method string) error {
+	switch method {
+	case "", http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodOptions:
+		return nil
+	}
+	return fmt.Errorf("unsupported method %q", method)
+}
+
+// MatchRoute reports whether path matches the route pattern. The score counts literal
+// segments times two so that more specific routes win over parameterized ones.
+func MatchRoute(route, path string) (int, bool) {
+	if route == "" {
+		return 0, false
+	}
+
+	routeSegments := strings.Split(strings.TrimPrefix(route, "/"), "/")
+	pathSegments := strings.Split(strings.TrimPrefix(path, "/"), "/")
+
+	score := 0
+	for i, segment := range routeSegments {
+		if segment == "*" && i == len(routeSegments)-1 {
+			return score, true
+		}
+		if i >= len(pathSegments) {
+			return 0, false
+		}
+		if isRouteParam(segment) {
+			if pathSegments[i] == "" {
+				return 0, false
+			}
+			continue
+		}
+		if segment != pathSegments[i] {
+			return 0, false
+		}
+		score += 2
+	}
+
+	if len(pathSegments) != len(routeSegments) {
+		return 0, false
+	}
+
+	return score, true
+}
+
+// RouteParams returns the values of the {name} parameters of a route pattern matching path
+func RouteParams(route, path string) map[string]string {
+	params := map[string]string{}
+	pathSegments := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	for i, segment := range strings.Split(strings.TrimPrefix(route, "/"), "/") {
+		if i < len(pathSegments) && isRouteParam(segment) {
+			params[segment[1:len(segment)-1]] = pathSegments[i]
+		}
+	}
+	return params
+}
+
+func isRouteParam(segment string) bool {
+	return len(segment) > 2 && strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
@@ -0,0 +1,207 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// StringMatcher matches a recorded string value; every condition that is set must hold
+type StringMatcher struct {
+	EqualTo  *string `json:"equalTo,omitempty"`
+	Contains string  `json:"contains,omitempty"`
+	Matches  string  `json:"matches,omitempty"`
+
+	re *regexp.Regexp
+}
+
+// Compile prepares the regular expression, if any
+func (m *StringMatcher) Compile() error {
+	if m.Matches == "" {
+		return nil
+	}
+
+	re, err := regexp.Compile(m.Matches)
+	if err != nil {
+		return fmt.Errorf("invalid pattern %q: %w", m.Matches, err)
+	}
+	m.re = re
+	return nil
+}
+
+// Match reports whether value satisfies the matcher
+func (m *StringMatcher) Match(value string) bool {
+	if m.EqualTo != nil && value != *m.EqualTo {
+		return false
+	}
+	if m.Contains != "" && !strings.Contains(value, m.Contains) {
+		return false
+	}
+	if m.re != nil && !m.re.MatchString(value) {
+		return false
+	}
+	return true
+}
+
+// String describes the matcher for mismatch reports
+func (m *StringMatcher) String() string {
+	var parts []string
+	if m.EqualTo != nil {
+		parts = append(parts, fmt.Sprintf("equal to %q", *m.EqualTo))
+	}
+	if m.Contains != "" {
+		parts = append(parts, fmt.Sprintf("containing %q", m.Contains))
+	}
+	if m.Matches != "" {
+		parts = append(parts, fmt.Sprintf("matching %q", m.Matches))
+	}
+	if len(parts) == 0 {
+		return "present"
+	}
+	return strings.Join(parts, " and ")
+}
+
+// RequestPattern describes which journaled requests a verification counts
+type RequestPattern struct {
+	MockID  string                    `json:"mockId,omitempty"`
+	Method  string                    `json:"method,omitempty"`
+	Path    *StringMatcher            `json:"path,omitempty"`
+	Headers map[string]*StringMatcher `json:"headers,omitempty"`
+	Query   map[string]*StringMatcher `json:"query,omitempty"`
+	Body    *StringMatcher            `json:"body,omitempty"`
+}
+
+// Compile prepares all matchers of the pattern
+func (p *RequestPattern) Compile() error {
+	for _, m := range p.matchers() {
+		if err := m.Compile(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *RequestPattern) matchers() []*StringMatcher {
+	var matchers []*StringMatcher
+	for _, m := range []*StringMatcher{p.Path, p.Body} {
+		if m != nil {
+			matchers = append(matchers, m)
+		}
+	}
+	for _, m := range p.Headers {
+		if m != nil {
+			matchers = append(matchers, m)
+		}
+	}
+	for _, m := range p.Query {
+		if m != nil {
+			matchers = append(matchers, m)
+		}
+	}
+	return matchers
+}
+
+// Mismatches lists why entry does not match the pattern; it is empty on a match
+func (p *RequestPattern) Mismatches(entry *RequestLog) []string {
+	var mismatches []string
+
+	if p.MockID != "" && entry.MockID != p.MockID {
+		mismatches = append(mismatches, fmt.Sprintf("mock: expected %s, got %s", p.MockID, entry.MockID))
+	}
+
+	if p.Method != "" && !strings.EqualFold(entry.Method, p.Method) {
+		mismatches = append(mismatches, fmt.Sprintf("method: expected %s, got %s", strings.ToUpper(p.Method), entry.Method))
+	}
+
+	if p.Path != nil && !p.Path.Match(entry.Path) {
+		mismatches = append(mismatches, fmt.Sprintf("path: expected %s, got %q", p.Path, entry.Path))
+	}
+
+	for _, name := range sortedKeys(p.Headers) {
+		matcher := p.Headers[name]
+		if matcher == nil {
+			matcher = &StringMatcher{}
+		}
+		if !matchAny(matcher, entry.Headers.Values(name)) {
+			mismatches = append(mismatches, fmt.Sprintf("header %s: expected %s, got %q", name, matcher, entry.Headers.Values(name)))
+		}
+	}
+
+	query, _ := url.ParseQuery(entry.Query)
+	for _, name := range sortedKeys(p.Query) {
+		matcher := p.Query[name]
+		if matcher == nil {
+			matcher = &StringMatcher{}
+		}
+		if !matchAny(matcher, query[name]) {
+			mismatches = append(mismatches, fmt.Sprintf("query %s: expected %s, got %q", name, matcher, query[name]))
+		}
+	}
+
+	if p.Body != nil && !p.Body.Match(entry.Body) {
+		mismatches = append(mismatches, fmt.Sprintf("body: expected %s", p.Body))
+	}
+
+	return mismatches
+}
+
+// matchAny reports whether any of the values satisfies the matcher
+func matchAny(matcher *StringMatcher, values []string) bool {
+	for _, value := range values {
+		if matcher.Match(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(m map[string]*StringMatcher) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// CountExpectation constrains how many requests must match; empty means at least once
+type CountExpectation struct {
+	Exactly *int `json:"exactly,omitempty"`
+	AtLeast *int `json:"atLeast,omitempty"`
+	AtMost  *int `json:"atMost,omitempty"`
+}
+
+// Satisfied reports whether count meets the expectation
+func (c CountExpectation) Satisfied(count int) bool {
+	if c.Exactly != nil {
+		return count == *c.Exactly
+	}
+	if c.AtLeast == nil && c.AtMost == nil {
+		return count >= 1
+	}
+	if c.AtLeast != nil && count < *c.AtLeast {
+		return false
+	}
+	if c.AtMost != nil && count > *c.AtMost {
+		return false
+	}
+	return true
+}
+
+// String describes the expectation, e.g. "exactly 2"
+func (c CountExpectation) String() string {
+	switch {
+	case c.Exactly != nil:
+		return fmt.Sprintf("exactly %d", *c.Exactly)
+	case c.AtLeast != nil && c.AtMost != nil:
+		return fmt.Sprintf("between %d and %d", *c.AtLeast, *c.AtMost)
+	case c.AtLeast != nil:
+		return fmt.Sprintf("at least %d", *c.AtLeast)
+	case c.AtMost != nil:
+		return fmt.Sprintf("at most %d", *c.AtMost)
+	default:
+		return "at least 1"
+	}
+}
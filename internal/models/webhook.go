@@ -0,0 +1,88 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+
+	"mockj-go/internal/events"
+
+	"github.com/google/uuid"
+)
+
+// WebhookEvents are the mock lifecycle events a webhook can subscribe to
+var WebhookEvents = []string{events.Created, events.Updated, events.Deleted, events.Expired}
+
+// Webhook delivery states
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// Webhook is a subscription that POSTs mock lifecycle events to a URL, signed with its secret
+type Webhook struct {
+	ID        string    `json:"id" db:"id"`
+	URL       string    `json:"url" db:"url"`
+	Events    []string  `json:"events,omitempty" db:"events"`  // Empty subscribes to every event
+	MockID    string    `json:"mockId,omitempty" db:"json_id"` // The only mock whose events are delivered, empty for every mock
+	Secret    string    `json:"-" db:"secret"`                 // Never include the signing secret in responses
+	Password  string    `json:"-" db:"password"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+}
+
+// NewWebhook creates a webhook with a new ID
+func NewWebhook(url string, events []string, mockID, secret, password string) *Webhook {
+	return &Webhook{
+		ID:        uuid.New().String(),
+		URL:       url,
+		Events:    events,
+		MockID:    mockID,
+		Secret:    secret,
+		Password:  password,
+		CreatedAt: time.Now(),
+	}
+}
+
+// Validate checks the URL, mock and event filter
+func (w *Webhook) Validate() error {
+	target, err := url.Parse(w.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return fmt.Errorf("url must be an absolute http or https URL")
+	}
+	for _, event := range w.Events {
+		if !slices.Contains(WebhookEvents, event) {
+			return fmt.Errorf("unknown event %q: must be one of %v", event, WebhookEvents)
+		}
+	}
+	if w.Secret == "" {
+		return fmt.Errorf("secret is required to sign deliveries")
+	}
+	return nil
+}
+
+// Matches reports whether the webhook subscribes to an event of a mock
+func (w *Webhook) Matches(eventType, mockID string) bool {
+	if w.MockID != "" && w.MockID != mockID {
+		return false
+	}
+	return len(w.Events) == 0 || slices.Contains(w.Events, eventType)
+}
+
+// WebhookDelivery is one event queued for, or delivered to, a webhook
+type WebhookDelivery struct {
+	ID             int64     `json:"id" db:"id"`
+	WebhookID      string    `json:"webhookId" db:"webhook_id"`
+	EventID        uint64    `json:"eventId" db:"event_id"`
+	EventType      string    `json:"eventType" db:"event_type"`
+	MockID         string    `json:"mockId,omitempty" db:"json_id"`
+	Payload        string    `json:"payload" db:"payload"`
+	State          string    `json:"state" db:"state"`
+	Attempts       int       `json:"attempts" db:"attempts"`
+	ResponseStatus int       `json:"responseStatus,omitempty" db:"response_status"` // Of the last attempt
+	Error          string    `json:"error,omitempty" db:"error"`                    // Of the last attempt
+	NextAttemptAt  time.Time `json:"nextAttemptAt" db:"next_attempt_at"`
+	CreatedAt      time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt      time.Time `json:"updatedAt" db:"updated_at"`
+}
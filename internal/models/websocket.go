@@ -0,0 +1,75 @@
+package models
+
+import (
+	"encoding/base64"
+	"fmt"
+	"regexp"
+)
+
+// Triggers of scripted WebSocket messages
+const (
+	WebSocketOnConnect  = "connect"
+	WebSocketOnInterval = "interval"
+	WebSocketOnMessage  = "message"
+)
+
+// Limits that keep a script from flooding clients
+const (
+	MaxWebSocketMessages   = 100
+	MinWebSocketIntervalMs = 10
+)
+
+// WebSocketScript lists the messages a mock sends to WebSocket clients
+type WebSocketScript struct {
+	Messages []WebSocketMessage `json:"messages"`
+}
+
+// WebSocketMessage is one scripted message. Connect messages are sent once the client
+// connects, interval messages every IntervalMs, and message replies whenever an incoming
+// frame matches Match. An empty Data sends the mock's content.
+type WebSocketMessage struct {
+	On         string `json:"on"`
+	Data       string `json:"data,omitempty"`   // Replies may use $1 or ${name} for groups of Match
+	Binary     bool   `json:"binary,omitempty"` // Data is base64 and sent as a binary frame
+	Match      string `json:"match,omitempty"`  // Regular expression; empty matches every frame
+	DelayMs    int    `json:"delayMs,omitempty"`
+	IntervalMs int    `json:"intervalMs,omitempty"`
+	Repeat     int    `json:"repeat,omitempty"` // Number of interval sends, 0 means until disconnect
+	Close      bool   `json:"close,omitempty"`  // Close the connection after sending
+}
+
+// Validate checks that the script is usable
+func (s *WebSocketScript) Validate() error {
+	if len(s.Messages) == 0 {
+		return fmt.Errorf("websocket script needs at least one message")
+	}
+	if len(s.Messages) > MaxWebSocketMessages {
+		return fmt.Errorf("websocket script has more than %d messages", MaxWebSocketMessages)
+	}
+
+	for i, message := range s.Messages {
+		switch message.On {
+		case WebSocketOnConnect, WebSocketOnMessage:
+		case WebSocketOnInterval:
+			if message.IntervalMs < MinWebSocketIntervalMs {
+				return fmt.Errorf("websocket message %d: intervalMs must be at least %d", i, MinWebSocketIntervalMs)
+			}
+		default:
+			return fmt.Errorf("websocket message %d: unknown trigger %q", i, message.On)
+		}
+
+		if message.DelayMs < 0 || message.Repeat < 0 {
+			return fmt.Errorf("websocket message %d: delayMs and repeat must not be negative", i)
+		}
+		if _, err := regexp.Compile(message.Match); err != nil {
+			return fmt.Errorf("websocket message %d: invalid match: %w", i, err)
+		}
+		if message.Binary {
+			if _, err := base64.StdEncoding.DecodeString(message.Data); err != nil {
+				return fmt.Errorf("websocket message %d: binary data must be base64", i)
+			}
+		}
+	}
+
+	return nil
+}
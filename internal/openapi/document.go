@@ -0,0 +1,262 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Operation describes one endpoint of the API in terms of its Go types
+type Operation struct {
+	Method    string
+	Path      string
+	Summary   string
+	Query     []string    // Names of optional string query parameters
+	Headers   []string    // Names of required string header parameters
+	Request   interface{} // Zero value of the request body type, nil when there is none
+	Responses []Response
+
+	RequestContentType string // Defaults to application/json
+}
+
+// Response describes one possible response of an operation
+type Response struct {
+	Status      int
+	Description string
+	Body        interface{} // Zero value of the envelope type, nil for an empty body
+	Data        interface{} // Zero value of the envelope's data field type, if any
+	ContentType string      // Defaults to application/json
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+	headerType     = reflect.TypeOf(http.Header{})
+)
+
+// Document builds an OpenAPI 3.1 document for the operations. Schemas are
+// derived from the Go types by reflection, following their json tags.
+func Document(title, version string, operations []Operation) map[string]interface{} {
+	b := &builder{schemas: map[string]interface{}{}}
+
+	paths := map[string]interface{}{}
+	for _, op := range operations {
+		item, _ := paths[op.Path].(map[string]interface{})
+		if item == nil {
+			item = map[string]interface{}{}
+			paths[op.Path] = item
+		}
+		item[strings.ToLower(op.Method)] = b.operation(op)
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.1.0",
+		"info": map[string]interface{}{
+			"title":   title,
+			"version": version,
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": b.schemas,
+		},
+	}
+}
+
+type builder struct {
+	schemas map[string]interface{}
+}
+
+func (b *builder) operation(op Operation) map[string]interface{} {
+	operation := map[string]interface{}{
+		"summary": op.Summary,
+	}
+
+	params := pathParameters(op.Path)
+	for _, name := range op.Query {
+		params = append(params, map[string]interface{}{
+			"name":   name,
+			"in":     "query",
+			"schema": map[string]interface{}{"type": "string"},
+		})
+	}
+	for _, name := range op.Headers {
+		params = append(params, map[string]interface{}{
+			"name":     name,
+			"in":       "header",
+			"required": true,
+			"schema":   map[string]interface{}{"type": "string"},
+		})
+	}
+	if len(params) > 0 {
+		operation["parameters"] = params
+	}
+
+	if op.Request != nil {
+		contentType := op.RequestContentType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		operation["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content": map[string]interface{}{
+				contentType: map[string]interface{}{
+					"schema": b.schema(reflect.TypeOf(op.Request)),
+				},
+			},
+		}
+	}
+
+	responses := map[string]interface{}{}
+	for _, resp := range op.Responses {
+		response := map[string]interface{}{
+			"description": resp.Description,
+		}
+
+		if resp.Body != nil {
+			bodySchema := b.schema(reflect.TypeOf(resp.Body))
+			if resp.Data != nil {
+				bodySchema = map[string]interface{}{
+					"allOf": []interface{}{
+						bodySchema,
+						map[string]interface{}{
+							"properties": map[string]interface{}{
+								"data": b.schema(reflect.TypeOf(resp.Data)),
+							},
+						},
+					},
+				}
+			}
+
+			contentType := resp.ContentType
+			if contentType == "" {
+				contentType = "application/json"
+			}
+			response["content"] = map[string]interface{}{
+				contentType: map[string]interface{}{"schema": bodySchema},
+			}
+		}
+
+		responses[strconv.Itoa(resp.Status)] = response
+	}
+	operation["responses"] = responses
+
+	return operation
+}
+
+// schema returns the JSON Schema of t, registering named structs as components
+func (b *builder) schema(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case rawMessageType:
+		return map[string]interface{}{}
+	case headerType:
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.structSchema(t)
+		}
+		if _, ok := b.schemas[t.Name()]; !ok {
+			b.schemas[t.Name()] = map[string]interface{}{} // Placeholder for recursive types
+			b.schemas[t.Name()] = b.structSchema(t)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + t.Name()}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "contentEncoding": "base64"}
+		}
+		return map[string]interface{}{"type": "array", "items": b.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": b.schema(t.Elem())}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	default:
+		// interface{} and anything else accept any JSON value
+		return map[string]interface{}{}
+	}
+}
+
+// structSchema describes the exported, JSON-visible fields of a struct
+func (b *builder) structSchema(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{}
+	var required []string
+
+	b.collectFields(t, properties, &required)
+
+	s := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		sort.Strings(required)
+		s["required"] = required
+	}
+	return s
+}
+
+func (b *builder) collectFields(t reflect.Type, properties map[string]interface{}, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		// Untagged embedded structs are flattened like encoding/json does
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			b.collectFields(field.Type, properties, required)
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		properties[name] = b.schema(field.Type)
+
+		if !strings.Contains(options, "omitempty") && field.Type.Kind() != reflect.Pointer {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// pathParameters declares the {name} segments of a path
+func pathParameters(path string) []interface{} {
+	var params []interface{}
+	for _, segment := range strings.Split(path, "/") {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			params = append(params, map[string]interface{}{
+				"name":     strings.Trim(segment, "{}"),
+				"in":       "path",
+				"required": true,
+				"schema":   map[string]interface{}{"type": "string"},
+			})
+		}
+	}
+	return params
+}
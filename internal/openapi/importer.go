@@ -0,0 +1,248 @@
+// Package openapi turns OpenAPI 3.x documents into stored mocks and describes mockj-go's own API.
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"mockj-go/internal/convert"
+	"mockj-go/internal/schema"
+)
+
+// methods lists the operation keys of a path item in the order they are imported
+var methods = []string{"get", "put", "post", "delete", "options", "head", "patch"}
+
+// Spec is a parsed OpenAPI document
+type Spec struct {
+	doc     map[string]interface{}
+	resolve schema.Resolver
+}
+
+// MockResponse is one response of an operation, ready to be stored as a mock
+type MockResponse struct {
+	Method      string `json:"method"`
+	Path        string `json:"path"`
+	OperationID string `json:"operationId,omitempty"`
+	Status      int    `json:"status"`
+	Content     string `json:"-"`
+	ContentType string `json:"-"`
+	Primary     bool   `json:"primary"` // The response served on the operation's route
+}
+
+// Skipped reports a response or operation that could not be imported
+type Skipped struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Status string `json:"status,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// Parse reads an OpenAPI 3.x document in YAML or JSON
+func Parse(data []byte) (*Spec, error) {
+	raw, err := convert.DecodeYAML(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid spec: %w", err)
+	}
+
+	doc, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid spec: document must be an object")
+	}
+
+	version, _ := doc["openapi"].(string)
+	if !strings.HasPrefix(version, "3.") {
+		return nil, fmt.Errorf("unsupported spec: only OpenAPI 3.x documents are supported")
+	}
+
+	return &Spec{doc: doc, resolve: schema.PointerResolver(doc)}, nil
+}
+
+// Responses walks every operation and returns its responses with sample content,
+// along with the responses that were skipped
+func (s *Spec) Responses() ([]MockResponse, []Skipped) {
+	var (
+		responses []MockResponse
+		skipped   []Skipped
+	)
+
+	paths, _ := s.doc["paths"].(map[string]interface{})
+	for _, path := range sortedKeys(paths) {
+		pathItem, _ := s.deref(paths[path])
+
+		for _, method := range methods {
+			operation, ok := pathItem[method].(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			method := strings.ToUpper(method)
+			operationID, _ := operation["operationId"].(string)
+
+			opResponses, opSkipped := s.operationResponses(method, path, operationID, operation)
+			responses = append(responses, opResponses...)
+			skipped = append(skipped, opSkipped...)
+
+			if len(opResponses) == 0 && len(opSkipped) == 0 {
+				skipped = append(skipped, Skipped{Method: method, Path: path, Reason: "operation has no responses"})
+			}
+		}
+	}
+
+	return responses, skipped
+}
+
+// operationResponses converts the responses of one operation, marking the lowest
+// success status (or the lowest status if none succeeds) as primary
+func (s *Spec) operationResponses(method, path, operationID string, operation map[string]interface{}) ([]MockResponse, []Skipped) {
+	var (
+		responses []MockResponse
+		skipped   []Skipped
+	)
+
+	rawResponses, _ := operation["responses"].(map[string]interface{})
+	for _, code := range sortedKeys(rawResponses) {
+		status, err := parseStatus(code)
+		if err != nil {
+			skipped = append(skipped, Skipped{Method: method, Path: path, Status: code, Reason: err.Error()})
+			continue
+		}
+
+		response, _ := s.deref(rawResponses[code])
+		content, contentType, err := s.responseContent(response)
+		if err != nil {
+			skipped = append(skipped, Skipped{Method: method, Path: path, Status: code, Reason: err.Error()})
+			continue
+		}
+
+		responses = append(responses, MockResponse{
+			Method:      method,
+			Path:        path,
+			OperationID: operationID,
+			Status:      status,
+			Content:     content,
+			ContentType: contentType,
+		})
+	}
+
+	primary := -1
+	for i, response := range responses {
+		if primary == -1 || preferred(response.Status, responses[primary].Status) {
+			primary = i
+		}
+	}
+	if primary >= 0 {
+		responses[primary].Primary = true
+	}
+
+	return responses, skipped
+}
+
+// responseContent picks the JSON body of a response from example, examples or schema
+func (s *Spec) responseContent(response map[string]interface{}) (string, string, error) {
+	content, _ := response["content"].(map[string]interface{})
+	if len(content) == 0 {
+		return "", "", nil
+	}
+
+	for _, mediaType := range sortedKeys(content) {
+		if !isJSONMediaType(mediaType) {
+			continue
+		}
+
+		media, _ := content[mediaType].(map[string]interface{})
+		value, ok := s.mediaExample(media)
+		if !ok {
+			return "", "", fmt.Errorf("no example or schema for %s", mediaType)
+		}
+
+		data, err := json.Marshal(value)
+		if err != nil {
+			return "", "", fmt.Errorf("example is not valid JSON: %w", err)
+		}
+		return string(data), mediaType, nil
+	}
+
+	return "", "", fmt.Errorf("no JSON content")
+}
+
+// mediaExample returns the explicit example, the first named example or a schema sample
+func (s *Spec) mediaExample(media map[string]interface{}) (interface{}, bool) {
+	if example, ok := media["example"]; ok {
+		return example, true
+	}
+
+	if examples, ok := media["examples"].(map[string]interface{}); ok && len(examples) > 0 {
+		example, _ := s.deref(examples[sortedKeys(examples)[0]])
+		if value, ok := example["value"]; ok {
+			return value, true
+		}
+	}
+
+	if mediaSchema, ok := media["schema"].(map[string]interface{}); ok {
+		return schema.Sample(mediaSchema, s.resolve), true
+	}
+
+	return nil, false
+}
+
+// deref follows a $ref to a component, returning the node itself otherwise
+func (s *Spec) deref(node interface{}) (map[string]interface{}, bool) {
+	object, ok := node.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+
+	for depth := 0; depth < 8; depth++ {
+		ref, ok := object["$ref"].(string)
+		if !ok {
+			return object, true
+		}
+		if object, ok = s.resolve(ref); !ok {
+			return nil, false
+		}
+	}
+
+	return nil, false
+}
+
+// parseStatus converts a response key such as "200" or "2XX" into a status code
+func parseStatus(code string) (int, error) {
+	if len(code) == 3 && strings.HasSuffix(strings.ToUpper(code), "XX") {
+		code = code[:1] + "00"
+	}
+
+	status, err := strconv.Atoi(code)
+	if err != nil || status < 100 || status > 599 {
+		return 0, fmt.Errorf("unsupported response status %q", code)
+	}
+	return status, nil
+}
+
+// preferred reports whether status a should be served instead of b: success first, then lowest
+func preferred(a, b int) bool {
+	if isSuccess(a) != isSuccess(b) {
+		return isSuccess(a)
+	}
+	return a < b
+}
+
+func isSuccess(status int) bool {
+	return status >= 200 && status < 300
+}
+
+func isJSONMediaType(mediaType string) bool {
+	mediaType = strings.ToLower(mediaType)
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") || mediaType == "*/*"
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
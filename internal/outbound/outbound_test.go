@@ -0,0 +1,58 @@
+package outbound
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestBlocked(t *testing.T) {
+	for address, blocked := range map[string]bool{
+		"127.0.0.1":       true,
+		"::1":             true,
+		"10.1.2.3":        true,
+		"172.16.0.1":      true,
+		"192.168.1.1":     true,
+		"169.254.169.254": true,
+		"fe80::1":         true,
+		"fd00::1":         true,
+		"0.0.0.0":         true,
+		"100.100.100.200": true,
+		"203.0.113.7":     false,
+		"2001:db8::1":     false,
+	} {
+		if got := Blocked(net.ParseIP(address)); got != blocked {
+			t.Errorf("%s: expected blocked=%v, got %v", address, blocked, got)
+		}
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "/target", http.StatusFound)
+		}
+	}))
+	defer server.Close()
+
+	t.Run("RefusesLoopback", func(t *testing.T) {
+		_, err := NewClient(time.Second, false).Get(server.URL)
+		if !errors.Is(err, ErrBlockedAddress) {
+			t.Errorf("Expected the loopback address to be refused, got %v", err)
+		}
+	})
+
+	t.Run("AllowPrivateDoesNotFollowRedirects", func(t *testing.T) {
+		resp, err := NewClient(time.Second, true).Get(server.URL + "/redirect")
+		if err != nil {
+			t.Fatalf("Failed to send request: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusFound {
+			t.Errorf("Expected the redirect itself, got status %d", resp.StatusCode)
+		}
+	})
+}
@@ -0,0 +1,388 @@
+package schema
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Caps on generated data, whatever the schema asks for. Schemas nest arrays, so the
+// output of a generator as a whole is bounded too: once it is spent, arrays stop growing
+// and further values are null.
+const (
+	maxGeneratedItems  = 1000
+	maxGeneratedLength = 10000
+	maxGeneratedSize   = 4 << 20 // Roughly in bytes of JSON
+	valueSize          = 8       // What a value other than a string counts against maxGeneratedSize
+)
+
+// sizeKeywords are the schema keywords that must be non-negative integers
+var sizeKeywords = []string{"minItems", "maxItems", "minLength", "maxLength"}
+
+// Generator produces random data matching a JSON Schema. The same seed always
+// yields the same data for the same schema.
+type Generator struct {
+	rand    *rand.Rand
+	resolve Resolver
+	budget  int // Of maxGeneratedSize, left to generate
+}
+
+// NewGenerator creates a generator seeded with seed, resolving $refs with resolve
+func NewGenerator(seed int64, resolve Resolver) *Generator {
+	return &Generator{rand: rand.New(rand.NewSource(seed)), resolve: resolve, budget: maxGeneratedSize}
+}
+
+// CheckSizes reports a schema, or a schema nested in it, whose minItems, maxItems,
+// minLength or maxLength is negative
+func CheckSizes(s interface{}) error {
+	switch value := s.(type) {
+	case map[string]interface{}:
+		for _, keyword := range sizeKeywords {
+			if n, ok := number(value[keyword]); ok && n < 0 {
+				return fmt.Errorf("%s must not be negative", keyword)
+			}
+		}
+		for _, nested := range value {
+			if err := CheckSizes(nested); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, nested := range value {
+			if err := CheckSizes(nested); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Generate produces one value for the schema
+func (g *Generator) Generate(s map[string]interface{}) interface{} {
+	return g.generate(s, 0)
+}
+
+func (g *Generator) generate(s map[string]interface{}, depth int) interface{} {
+	if s == nil || depth > maxDepth || g.budget <= 0 {
+		return nil
+	}
+	g.budget -= valueSize
+
+	if ref, ok := s["$ref"].(string); ok && g.resolve != nil {
+		resolved, ok := g.resolve(ref)
+		if !ok {
+			return nil
+		}
+		return g.generate(resolved, depth+1)
+	}
+
+	if value, ok := s["const"]; ok {
+		return value
+	}
+	if enum, ok := s["enum"].([]interface{}); ok && len(enum) > 0 {
+		return enum[g.rand.Intn(len(enum))]
+	}
+	if hint, ok := s["x-faker"].(string); ok {
+		if value, ok := g.fake(hint); ok {
+			return value
+		}
+	}
+
+	if allOf, ok := s["allOf"].([]interface{}); ok {
+		merged := map[string]interface{}{}
+		for _, part := range allOf {
+			partSchema, _ := part.(map[string]interface{})
+			if object, ok := g.generate(partSchema, depth+1).(map[string]interface{}); ok {
+				for key, value := range object {
+					merged[key] = value
+				}
+			}
+		}
+		return merged
+	}
+	for _, key := range []string{"oneOf", "anyOf"} {
+		if options, ok := s[key].([]interface{}); ok && len(options) > 0 {
+			option, _ := options[g.rand.Intn(len(options))].(map[string]interface{})
+			return g.generate(option, depth+1)
+		}
+	}
+
+	switch Type(s) {
+	case "object":
+		return g.object(s, depth)
+	case "array":
+		return g.array(s, depth)
+	case "string":
+		return g.string(s)
+	case "integer":
+		low, high := g.bounds(s)
+		lowInt, highInt := toInt64(math.Ceil(low)), toInt64(math.Floor(high))
+		if highInt < lowInt {
+			return lowInt
+		}
+		return g.intBetween(lowInt, highInt)
+	case "number":
+		low, high := g.bounds(s)
+		// Interpolated rather than low+r*(high-low), which overflows for the widest ranges
+		r := g.rand.Float64()
+		value := low*(1-r) + high*r
+		if math.Abs(value) < 1e15 {
+			value = math.Round(value*100) / 100
+		}
+		return value
+	case "boolean":
+		return g.rand.Intn(2) == 1
+	default:
+		return nil
+	}
+}
+
+// object generates required properties always and optional ones most of the time
+func (g *Generator) object(s map[string]interface{}, depth int) map[string]interface{} {
+	required := map[string]bool{}
+	if names, ok := s["required"].([]interface{}); ok {
+		for _, name := range names {
+			if name, ok := name.(string); ok {
+				required[name] = true
+			}
+		}
+	}
+
+	object := map[string]interface{}{}
+	properties, _ := s["properties"].(map[string]interface{})
+	for _, name := range sortedNames(properties) {
+		if !required[name] && g.rand.Intn(5) == 0 {
+			continue
+		}
+		property, _ := properties[name].(map[string]interface{})
+		object[name] = g.generate(property, depth+1)
+	}
+	return object
+}
+
+func (g *Generator) array(s map[string]interface{}, depth int) []interface{} {
+	minItems, maxItems := sizes(s, "minItems", "maxItems", 1, 5, maxGeneratedItems)
+
+	items, _ := s["items"].(map[string]interface{})
+	n := minItems + g.rand.Intn(maxItems-minItems+1)
+	values := make([]interface{}, 0, n)
+	for len(values) < n && g.budget > 0 {
+		values = append(values, g.generate(items, depth+1))
+	}
+	return values
+}
+
+// sizes reads a pair of size keywords, clamped to 0 through limit, with max at least min
+func sizes(s map[string]interface{}, minKeyword, maxKeyword string, minDefault, maxDefault, limit int) (int, int) {
+	low, high := minDefault, maxDefault
+	if n, ok := number(s[minKeyword]); ok {
+		low = int(max(0, min(n, float64(limit))))
+	}
+	if n, ok := number(s[maxKeyword]); ok {
+		high = int(max(0, min(n, float64(limit))))
+	}
+	if high < low {
+		high = low
+	}
+	return low, high
+}
+
+func (g *Generator) string(s map[string]interface{}) string {
+	if format, ok := s["format"].(string); ok {
+		if value, ok := g.format(format); ok {
+			return value
+		}
+	}
+
+	minLength, maxLength := sizes(s, "minLength", "maxLength", 5, 12, maxGeneratedLength)
+	g.budget -= maxLength
+
+	text := g.words(1 + maxLength/6)
+	for len(text) < minLength {
+		text += " " + g.pick(loremWords)
+	}
+	if len(text) > maxLength {
+		text = strings.TrimSpace(text[:maxLength])
+		for len(text) < minLength {
+			text += "x"
+		}
+	}
+	return text
+}
+
+// intBetween returns a random integer from low through high, without overflowing for
+// bounds at the ends of the int64 range
+func (g *Generator) intBetween(low, high int64) int64 {
+	span := uint64(high) - uint64(low)
+	if span < math.MaxInt64 {
+		return low + g.rand.Int63n(int64(span)+1)
+	}
+	// The range covers at least half of int64, so each draw lands in it more often than not
+	for {
+		if n := g.rand.Uint64(); n <= span {
+			return int64(uint64(low) + n)
+		}
+	}
+}
+
+// toInt64 converts a whole float64, saturating at the ends of the int64 range
+func toInt64(f float64) int64 {
+	switch {
+	case f >= math.MaxInt64:
+		return math.MaxInt64
+	case f <= math.MinInt64:
+		return math.MinInt64
+	}
+	return int64(f)
+}
+
+// bounds reads minimum/maximum, including exclusive variants. Missing bounds
+// default to 0-1000, or a range of 1000 next to the one bound that is given.
+func (g *Generator) bounds(s map[string]interface{}) (float64, float64) {
+	low, hasLow := number(s["minimum"])
+	if n, ok := number(s["exclusiveMinimum"]); ok {
+		low, hasLow = n+1, true
+	}
+	high, hasHigh := number(s["maximum"])
+	if n, ok := number(s["exclusiveMaximum"]); ok {
+		high, hasHigh = n-1, true
+	}
+
+	switch {
+	case !hasLow && !hasHigh:
+		low, high = 0, 1000
+	case !hasHigh:
+		high = low + 1000
+	case !hasLow && high >= 0:
+		low = 0
+	case !hasLow:
+		low = high - 1000
+	}
+	if high < low {
+		high = low
+	}
+	return low, high
+}
+
+// baseTime anchors generated dates so they do not depend on the current time
+var baseTime = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func (g *Generator) format(format string) (string, bool) {
+	switch format {
+	case "date-time":
+		return g.date().Format(time.RFC3339), true
+	case "date":
+		return g.date().Format(time.DateOnly), true
+	case "time":
+		return g.date().Format("15:04:05Z"), true
+	case "email":
+		return g.email(), true
+	case "uuid":
+		return g.uuid(), true
+	case "uri", "url":
+		return fmt.Sprintf("https://%s/%s", g.domain(), g.pick(loremWords)), true
+	case "hostname":
+		return g.domain(), true
+	case "ipv4":
+		return fmt.Sprintf("%d.%d.%d.%d", 1+g.rand.Intn(223), g.rand.Intn(256), g.rand.Intn(256), 1+g.rand.Intn(254)), true
+	case "ipv6":
+		return fmt.Sprintf("2001:db8::%x:%x", g.rand.Intn(0x10000), g.rand.Intn(0x10000)), true
+	}
+	return "", false
+}
+
+// fake produces values for x-faker hints such as "name.firstName"
+func (g *Generator) fake(hint string) (interface{}, bool) {
+	switch strings.ToLower(hint) {
+	case "name.firstname":
+		return g.pick(firstNames), true
+	case "name.lastname":
+		return g.pick(lastNames), true
+	case "name.fullname", "name.findname":
+		return g.pick(firstNames) + " " + g.pick(lastNames), true
+	case "internet.email":
+		return g.email(), true
+	case "internet.username":
+		return strings.ToLower(g.pick(firstNames)) + fmt.Sprint(g.rand.Intn(100)), true
+	case "internet.url":
+		return "https://" + g.domain(), true
+	case "internet.domainname":
+		return g.domain(), true
+	case "phone.number", "phone.phonenumber":
+		return fmt.Sprintf("+1-555-%03d-%04d", g.rand.Intn(1000), g.rand.Intn(10000)), true
+	case "address.city", "location.city":
+		return g.pick(cities), true
+	case "address.country", "location.country":
+		return g.pick(countries), true
+	case "address.streetaddress", "location.streetaddress":
+		return fmt.Sprintf("%d %s Street", 1+g.rand.Intn(999), g.pick(lastNames)), true
+	case "address.zipcode", "location.zipcode":
+		return fmt.Sprintf("%05d", g.rand.Intn(100000)), true
+	case "company.name", "company.companyname":
+		return g.pick(lastNames) + " " + g.pick(companySuffixes), true
+	case "lorem.word":
+		return g.pick(loremWords), true
+	case "lorem.sentence":
+		sentence := g.words(6 + g.rand.Intn(6))
+		return strings.ToUpper(sentence[:1]) + sentence[1:] + ".", true
+	case "finance.amount", "commerce.price":
+		return math.Round(g.rand.Float64()*100000) / 100, true
+	case "date.past":
+		return baseTime.Add(-time.Duration(g.rand.Int63n(int64(365 * 24 * time.Hour)))).Format(time.RFC3339), true
+	case "date.future":
+		return baseTime.Add(time.Duration(g.rand.Int63n(int64(365 * 24 * time.Hour)))).Format(time.RFC3339), true
+	case "string.uuid", "datatype.uuid":
+		return g.uuid(), true
+	case "image.url", "image.avatar":
+		return fmt.Sprintf("https://picsum.photos/seed/%d/200/200", g.rand.Intn(1000)), true
+	}
+	return nil, false
+}
+
+func (g *Generator) date() time.Time {
+	return baseTime.Add(time.Duration(g.rand.Int63n(int64(2 * 365 * 24 * time.Hour)))).Truncate(time.Second)
+}
+
+func (g *Generator) email() string {
+	return fmt.Sprintf("%s.%s@%s", strings.ToLower(g.pick(firstNames)), strings.ToLower(g.pick(lastNames)), g.pick(emailDomains))
+}
+
+func (g *Generator) uuid() string {
+	var b [16]byte
+	g.rand.Read(b[:])
+	id, _ := uuid.FromBytes(b[:])
+	// Mark as a version 4, RFC 4122 UUID
+	id[6] = (id[6] & 0x0f) | 0x40
+	id[8] = (id[8] & 0x3f) | 0x80
+	return id.String()
+}
+
+func (g *Generator) domain() string {
+	return strings.ToLower(g.pick(lastNames)) + ".example"
+}
+
+func (g *Generator) words(n int) string {
+	words := make([]string, n)
+	for i := range words {
+		words[i] = g.pick(loremWords)
+	}
+	return strings.Join(words, " ")
+}
+
+func (g *Generator) pick(values []string) string {
+	return values[g.rand.Intn(len(values))]
+}
+
+var (
+	firstNames      = []string{"Alice", "Bob", "Carol", "David", "Emma", "Frank", "Grace", "Henry", "Iris", "Jack", "Karen", "Liam", "Mia", "Noah", "Olivia", "Paul"}
+	lastNames       = []string{"Smith", "Johnson", "Brown", "Garcia", "Miller", "Davis", "Wilson", "Moore", "Taylor", "Anderson", "Thomas", "Martin", "Lee", "Clark", "Lewis", "Walker"}
+	cities          = []string{"Amsterdam", "Berlin", "Chicago", "Dublin", "Hanoi", "Lisbon", "London", "Madrid", "Osaka", "Paris", "Seoul", "Toronto"}
+	countries       = []string{"Australia", "Brazil", "Canada", "France", "Germany", "Japan", "Netherlands", "Spain", "United Kingdom", "United States", "Vietnam"}
+	companySuffixes = []string{"Inc", "LLC", "Group", "Labs", "Systems", "Partners"}
+	emailDomains    = []string{"example.com", "example.org", "example.net"}
+	loremWords      = []string{"lorem", "ipsum", "dolor", "sit", "amet", "consectetur", "adipiscing", "elit", "sed", "do", "eiusmod", "tempor", "incididunt", "ut", "labore", "magna", "aliqua"}
+)
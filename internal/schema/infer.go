@@ -0,0 +1,210 @@
+package schema
+
+import (
+	"encoding/json"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Draft is the JSON Schema dialect of inferred schemas
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// typeOrder fixes the order of types in unions
+var typeOrder = []string{"object", "array", "string", "integer", "number", "boolean", "null"}
+
+// shape accumulates what has been observed at one position across samples
+type shape struct {
+	types map[string]bool
+
+	objects    int
+	properties map[string]*shape
+	seen       map[string]int
+
+	items *shape
+
+	formats map[string]bool
+}
+
+func newShape() *shape {
+	return &shape{types: map[string]bool{}}
+}
+
+// Infer derives a JSON Schema describing every sample. Samples must be decoded
+// with json.Decoder.UseNumber so integers can be told apart from numbers.
+// Properties present in every sample object are required, array items of mixed
+// types become unions, and string formats are detected heuristically.
+func Infer(samples ...interface{}) map[string]interface{} {
+	root := newShape()
+	for _, sample := range samples {
+		root.add(sample)
+	}
+
+	s := root.schema()
+	s["$schema"] = Draft
+	return s
+}
+
+func (sh *shape) add(value interface{}) {
+	switch v := value.(type) {
+	case nil:
+		sh.types["null"] = true
+	case bool:
+		sh.types["boolean"] = true
+	case json.Number:
+		if strings.ContainsAny(v.String(), ".eE") {
+			sh.types["number"] = true
+		} else {
+			sh.types["integer"] = true
+		}
+	case float64:
+		if v == float64(int64(v)) {
+			sh.types["integer"] = true
+		} else {
+			sh.types["number"] = true
+		}
+	case string:
+		sh.types["string"] = true
+		if sh.formats == nil {
+			sh.formats = map[string]bool{}
+		}
+		sh.formats[detectFormat(v)] = true
+	case []interface{}:
+		sh.types["array"] = true
+		if sh.items == nil {
+			sh.items = newShape()
+		}
+		for _, item := range v {
+			sh.items.add(item)
+		}
+	case map[string]interface{}:
+		sh.types["object"] = true
+		if sh.properties == nil {
+			sh.properties = map[string]*shape{}
+			sh.seen = map[string]int{}
+		}
+		sh.objects++
+		for name, item := range v {
+			if sh.properties[name] == nil {
+				sh.properties[name] = newShape()
+			}
+			sh.properties[name].add(item)
+			sh.seen[name]++
+		}
+	}
+}
+
+// schema renders the accumulated shape, as a union when several types were seen
+func (sh *shape) schema() map[string]interface{} {
+	// Integers are numbers too, so a mix of both is just a number
+	if sh.types["integer"] && sh.types["number"] {
+		delete(sh.types, "integer")
+	}
+
+	var types []string
+	for _, t := range typeOrder {
+		if sh.types[t] {
+			types = append(types, t)
+		}
+	}
+
+	switch {
+	case len(types) == 0:
+		return map[string]interface{}{}
+	case len(types) == 1:
+		return sh.typeSchema(types[0])
+	case len(types) == 2 && types[1] == "null":
+		s := sh.typeSchema(types[0])
+		s["type"] = []interface{}{types[0], "null"}
+		return s
+	}
+
+	anyOf := make([]interface{}, len(types))
+	for i, t := range types {
+		anyOf[i] = sh.typeSchema(t)
+	}
+	return map[string]interface{}{"anyOf": anyOf}
+}
+
+func (sh *shape) typeSchema(t string) map[string]interface{} {
+	s := map[string]interface{}{"type": t}
+
+	switch t {
+	case "object":
+		properties := map[string]interface{}{}
+		var required []string
+		for name, property := range sh.properties {
+			properties[name] = property.schema()
+			if sh.seen[name] == sh.objects {
+				required = append(required, name)
+			}
+		}
+		s["properties"] = properties
+		if len(required) > 0 {
+			sort.Strings(required)
+			s["required"] = required
+		}
+	case "array":
+		if sh.items != nil && len(sh.items.types) > 0 {
+			s["items"] = sh.items.schema()
+		}
+	case "string":
+		// A format is only reported when every string agrees on it
+		if len(sh.formats) == 1 {
+			for format := range sh.formats {
+				if format != "" {
+					s["format"] = format
+				}
+			}
+		}
+	}
+
+	return s
+}
+
+// detectFormat guesses the JSON Schema format of a string, or returns ""
+func detectFormat(value string) string {
+	switch {
+	case uuidPattern.MatchString(value):
+		return "uuid"
+	case isTime(time.RFC3339Nano, value):
+		return "date-time"
+	case isTime(time.DateOnly, value):
+		return "date"
+	case strings.Contains(value, "@") && isEmail(value):
+		return "email"
+	case strings.Contains(value, "://") && isURI(value):
+		return "uri"
+	case isIP(value, true):
+		return "ipv4"
+	case strings.Contains(value, ":") && isIP(value, false):
+		return "ipv6"
+	}
+	return ""
+}
+
+func isTime(layout, value string) bool {
+	_, err := time.Parse(layout, value)
+	return err == nil
+}
+
+func isEmail(value string) bool {
+	address, err := mail.ParseAddress(value)
+	return err == nil && address.Address == value
+}
+
+func isURI(value string) bool {
+	u, err := url.Parse(value)
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+
+func isIP(value string, v4 bool) bool {
+	addr, err := netip.ParseAddr(value)
+	return err == nil && addr.Is4() == v4
+}
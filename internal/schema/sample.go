@@ -0,0 +1,199 @@
+package schema
+
+import (
+	"sort"
+	"strings"
+)
+
+// maxDepth stops sampling recursive schemas
+const maxDepth = 8
+
+// Resolver looks up the schema referenced by a $ref
+type Resolver func(ref string) (map[string]interface{}, bool)
+
+// PointerResolver resolves local "#/..." references against a root document
+func PointerResolver(root interface{}) Resolver {
+	return func(ref string) (map[string]interface{}, bool) {
+		if !strings.HasPrefix(ref, "#") {
+			return nil, false
+		}
+
+		node := root
+		for _, token := range strings.Split(strings.TrimPrefix(ref, "#"), "/") {
+			if token == "" {
+				continue
+			}
+			token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+
+			object, ok := node.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			if node, ok = object[token]; !ok {
+				return nil, false
+			}
+		}
+
+		resolved, ok := node.(map[string]interface{})
+		return resolved, ok
+	}
+}
+
+// Sample builds a deterministic example value for a JSON Schema, preferring
+// example, default, const and enum values declared in the schema itself
+func Sample(s map[string]interface{}, resolve Resolver) interface{} {
+	return sample(s, resolve, 0)
+}
+
+func sample(s map[string]interface{}, resolve Resolver, depth int) interface{} {
+	if s == nil || depth > maxDepth {
+		return nil
+	}
+
+	if ref, ok := s["$ref"].(string); ok && resolve != nil {
+		resolved, ok := resolve(ref)
+		if !ok {
+			return nil
+		}
+		return sample(resolved, resolve, depth+1)
+	}
+
+	for _, key := range []string{"example", "default", "const"} {
+		if value, ok := s[key]; ok {
+			return value
+		}
+	}
+	if examples, ok := s["examples"].([]interface{}); ok && len(examples) > 0 {
+		return examples[0]
+	}
+	if enum, ok := s["enum"].([]interface{}); ok && len(enum) > 0 {
+		return enum[0]
+	}
+
+	if allOf, ok := s["allOf"].([]interface{}); ok {
+		merged := map[string]interface{}{}
+		for _, part := range allOf {
+			partSchema, _ := part.(map[string]interface{})
+			if object, ok := sample(partSchema, resolve, depth+1).(map[string]interface{}); ok {
+				for key, value := range object {
+					merged[key] = value
+				}
+			}
+		}
+		return merged
+	}
+	for _, key := range []string{"oneOf", "anyOf"} {
+		if options, ok := s[key].([]interface{}); ok && len(options) > 0 {
+			option, _ := options[0].(map[string]interface{})
+			return sample(option, resolve, depth+1)
+		}
+	}
+
+	switch Type(s) {
+	case "object":
+		object := map[string]interface{}{}
+		properties, _ := s["properties"].(map[string]interface{})
+		for _, name := range sortedNames(properties) {
+			property, _ := properties[name].(map[string]interface{})
+			object[name] = sample(property, resolve, depth+1)
+		}
+		return object
+	case "array":
+		items, _ := s["items"].(map[string]interface{})
+		if items == nil {
+			return []interface{}{}
+		}
+		return []interface{}{sample(items, resolve, depth+1)}
+	case "string":
+		return sampleString(s)
+	case "integer":
+		if minimum, ok := number(s["minimum"]); ok {
+			return int64(minimum)
+		}
+		return 0
+	case "number":
+		if minimum, ok := number(s["minimum"]); ok {
+			return minimum
+		}
+		return 0.0
+	case "boolean":
+		return true
+	default:
+		return nil
+	}
+}
+
+// Type returns the schema's type, inferring object/array from their keywords.
+// For a list of types, as allowed by JSON Schema, the first non-null one is used.
+func Type(s map[string]interface{}) string {
+	switch t := s["type"].(type) {
+	case string:
+		return t
+	case []interface{}:
+		for _, item := range t {
+			if name, ok := item.(string); ok && name != "null" {
+				return name
+			}
+		}
+		return "null"
+	}
+
+	if _, ok := s["properties"]; ok {
+		return "object"
+	}
+	if _, ok := s["items"]; ok {
+		return "array"
+	}
+	return ""
+}
+
+// formatSamples are fixed values for well-known string formats
+var formatSamples = map[string]string{
+	"date-time": "2024-01-01T00:00:00Z",
+	"date":      "2024-01-01",
+	"time":      "00:00:00Z",
+	"email":     "user@example.com",
+	"uuid":      "00000000-0000-4000-8000-000000000000",
+	"uri":       "https://example.com",
+	"url":       "https://example.com",
+	"hostname":  "example.com",
+	"ipv4":      "192.0.2.1",
+	"ipv6":      "2001:db8::1",
+	"byte":      "ZXhhbXBsZQ==",
+	"password":  "********",
+}
+
+func sampleString(s map[string]interface{}) string {
+	if format, ok := s["format"].(string); ok {
+		if value, ok := formatSamples[format]; ok {
+			return value
+		}
+	}
+	return "string"
+}
+
+// number converts JSON and YAML numeric values to float64
+func number(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+func sortedNames(m map[string]interface{}) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
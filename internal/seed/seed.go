@@ -0,0 +1,397 @@
+// Package seed loads mocks from a directory of JSON and YAML files into the store
+// and keeps them in sync with the files on disk.
+package seed
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"mockj-go/internal/config"
+	"mockj-go/internal/convert"
+	"mockj-go/internal/database"
+	"mockj-go/internal/events"
+	"mockj-go/internal/graphql"
+	"mockj-go/internal/models"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// namespace derives stable mock IDs from fixture paths, so a reloaded file updates its mock
+var namespace = uuid.NewSHA1(uuid.NameSpaceURL, []byte("mockj-go/seed"))
+
+// metaSuffixes mark sidecar metadata files, e.g. users.meta.yaml for users.json
+var metaSuffixes = []string{".meta.json", ".meta.yaml", ".meta.yml"}
+
+// Meta holds the optional sidecar metadata of a fixture
+type Meta struct {
+	Method        string                  `json:"method,omitempty"`
+	Route         string                  `json:"route,omitempty"`
+	Status        int                     `json:"status,omitempty"`
+	Headers       map[string]string       `json:"headers,omitempty"`
+	Fault         *models.Fault           `json:"fault,omitempty"`
+	GraphQLSchema string                  `json:"graphqlSchema,omitempty"` // SDL answered from the fixture's data
+	WebSocket     *models.WebSocketScript `json:"websocket,omitempty"`
+	Callbacks     []models.Callback       `json:"callbacks,omitempty"`
+	CORS          *models.CORS            `json:"cors,omitempty"`
+}
+
+// fileState identifies a version of a fixture and its sidecar on disk
+type fileState struct {
+	hash string // SHA-256 of the fixture and its sidecar
+	meta string
+}
+
+// Seeder loads the fixtures of a directory as mocks
+type Seeder struct {
+	db       *database.Database
+	events   *events.Bus
+	cfg      config.SeedConfig
+	password string
+
+	// files holds the state of every fixture at the last Sync, keyed by slash-separated relative
+	// path. The fixtures owning a mock are recorded in the database, so mocks of fixtures
+	// removed while the server was down are deleted too.
+	files map[string]fileState
+}
+
+func NewSeeder(db *database.Database, bus *events.Bus, cfg config.SeedConfig) (*Seeder, error) {
+	info, err := os.Stat(cfg.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open seed directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("seed path %s is not a directory", cfg.Dir)
+	}
+
+	password, err := bcrypt.GenerateFromPassword([]byte(cfg.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash seed password: %w", err)
+	}
+
+	return &Seeder{
+		db:       db,
+		events:   bus,
+		cfg:      cfg,
+		password: string(password),
+		files:    map[string]fileState{},
+	}, nil
+}
+
+// ID returns the mock ID of the fixture at the slash-separated path relative to the seed directory
+func ID(path string) string {
+	return uuid.NewSHA1(namespace, []byte(path)).String()
+}
+
+// Sync loads fixtures that are new or changed since the last call and deletes the mocks
+// of removed fixtures. Invalid fixtures do not stop the others from loading; their errors
+// are joined into the result and they are retried once they change again.
+func (s *Seeder) Sync(ctx context.Context) error {
+	current, err := s.scan()
+	if err != nil {
+		return err
+	}
+
+	seeded, err := s.db.ListSeeds(ctx)
+	if err != nil {
+		return err
+	}
+
+	paths := make([]string, 0, len(current))
+	for path := range current {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var errs []error
+	for _, path := range paths {
+		if previous, ok := s.files[path]; ok && previous == current[path] {
+			continue
+		}
+		if err := s.load(ctx, path, current[path]); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+		}
+	}
+
+	for path, id := range seeded {
+		if _, ok := current[path]; ok {
+			continue
+		}
+		deleted := false
+		err := s.db.RunInTx(ctx, func(tx *database.Database) error {
+			err := tx.DeleteJSON(ctx, id)
+			if err != nil && err.Error() != "json not found" {
+				return err
+			}
+			deleted = err == nil
+			return tx.DeleteSeed(ctx, path)
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+			continue
+		}
+		if deleted {
+			s.events.Publish(events.Deleted, id, nil)
+		}
+		slog.InfoContext(ctx, "Removed seeded mock", "mock_id", id, "path", path)
+	}
+
+	s.files = current
+	return errors.Join(errs...)
+}
+
+// scan lists the fixtures of the seed directory with their sidecars
+func (s *Seeder) scan() (map[string]fileState, error) {
+	files := map[string]fileState{}
+
+	err := filepath.WalkDir(s.cfg.Dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || !isFixture(entry.Name()) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(s.cfg.Dir, path)
+		if err != nil {
+			return err
+		}
+
+		// Hashing the contents catches every edit, whatever its size and modification time.
+		// Files removed since they were listed are left to the next scan.
+		hash := sha256.New()
+		if err := hashFile(hash, path); err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		state := fileState{meta: findMeta(path)}
+		if state.meta != "" {
+			hash.Write([]byte{0})
+			if err := hashFile(hash, state.meta); errors.Is(err, fs.ErrNotExist) {
+				state.meta = ""
+			} else if err != nil {
+				return err
+			}
+		}
+		state.hash = hex.EncodeToString(hash.Sum(nil))
+
+		files[filepath.ToSlash(rel)] = state
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan seed directory: %w", err)
+	}
+
+	return files, nil
+}
+
+func hashFile(hash io.Writer, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = io.Copy(hash, file)
+	return err
+}
+
+// load creates or updates the mock of one fixture
+func (s *Seeder) load(ctx context.Context, path string, state fileState) error {
+	data, err := os.ReadFile(filepath.Join(s.cfg.Dir, filepath.FromSlash(path)))
+	if err != nil {
+		return err
+	}
+
+	content, err := decodeFixture(path, data)
+	if err != nil {
+		return err
+	}
+
+	var meta Meta
+	if state.meta != "" {
+		if meta, err = readMeta(state.meta); err != nil {
+			return fmt.Errorf("invalid metadata %s: %w", filepath.Base(state.meta), err)
+		}
+	}
+
+	jsonModel := models.NewJSON(content, s.password)
+	jsonModel.ID = ID(path)
+	jsonModel.Expires = models.NeverExpires // Fixtures stay for as long as their files do
+	jsonModel.Method = meta.Method
+	jsonModel.Route = meta.Route
+	jsonModel.Status = meta.Status
+	jsonModel.Headers = meta.Headers
+	jsonModel.Fault = meta.Fault
+	jsonModel.GraphQLSchema = meta.GraphQLSchema
+	jsonModel.WebSocket = meta.WebSocket
+	jsonModel.Callbacks = meta.Callbacks
+	jsonModel.CORS = meta.CORS
+
+	if meta.GraphQLSchema != "" {
+		if _, err := graphql.ParseSchema(meta.GraphQLSchema); err != nil {
+			return fmt.Errorf("invalid metadata %s: %w", filepath.Base(state.meta), err)
+		}
+	}
+	if meta.WebSocket != nil {
+		if err := meta.WebSocket.Validate(); err != nil {
+			return fmt.Errorf("invalid metadata %s: %w", filepath.Base(state.meta), err)
+		}
+	}
+	if err := models.ValidateCallbacks(meta.Callbacks); err != nil {
+		return fmt.Errorf("invalid metadata %s: %w", filepath.Base(state.meta), err)
+	}
+	if meta.CORS != nil {
+		if err := meta.CORS.Validate(); err != nil {
+			return fmt.Errorf("invalid metadata %s: %w", filepath.Base(state.meta), err)
+		}
+	}
+
+	eventType := events.Created
+	err = s.db.RunInTx(ctx, func(tx *database.Database) error {
+		exists, err := tx.JSONExists(ctx, jsonModel.ID)
+		if err != nil {
+			return err
+		}
+		if exists {
+			eventType = events.Updated
+			err = tx.UpdateJSON(ctx, jsonModel)
+		} else {
+			err = tx.CreateJSON(ctx, jsonModel)
+		}
+		if err != nil {
+			return err
+		}
+		return tx.SaveSeed(ctx, path, jsonModel.ID)
+	})
+	if err != nil {
+		return err
+	}
+
+	// Published like the changes made through the API, without the password
+	mock := *jsonModel
+	mock.Password = ""
+	s.events.Publish(eventType, mock.ID, &mock)
+
+	if meta.Route != "" {
+		slog.InfoContext(ctx, "Seeded", "path", path, "mock_id", jsonModel.ID, "method", methodOrAny(meta.Method), "route", meta.Route)
+	} else {
+		slog.InfoContext(ctx, "Seeded", "path", path, "mock_id", jsonModel.ID)
+	}
+	return nil
+}
+
+// decodeFixture returns the JSON content of a fixture. JSON files are kept verbatim,
+// YAML files are converted.
+func decodeFixture(path string, data []byte) (string, error) {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		if !json.Valid(data) {
+			return "", fmt.Errorf("invalid JSON")
+		}
+		return string(bytes.TrimSpace(data)), nil
+	}
+
+	data, err := yamlToJSON(data)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// readMeta decodes a JSON or YAML sidecar, rejecting unknown fields to catch typos
+func readMeta(path string) (Meta, error) {
+	var meta Meta
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return meta, err
+	}
+
+	// YAML is a superset of JSON, so both go through the YAML decoder
+	data, err = yamlToJSON(data)
+	if err != nil {
+		return meta, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&meta); err != nil {
+		return meta, err
+	}
+
+	meta.Method = strings.ToUpper(meta.Method)
+	if err := models.ValidateMethod(meta.Method); err != nil {
+		return meta, err
+	}
+	if meta.Route != "" {
+		if err := models.ValidateRoute(meta.Route); err != nil {
+			return meta, err
+		}
+	}
+	if meta.Status != 0 && (meta.Status < 100 || meta.Status > 599) {
+		return meta, fmt.Errorf("status must be between 100 and 599")
+	}
+	if meta.Fault != nil {
+		if err := meta.Fault.Validate(); err != nil {
+			return meta, err
+		}
+	}
+
+	return meta, nil
+}
+
+func yamlToJSON(data []byte) ([]byte, error) {
+	value, err := convert.DecodeYAML(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid YAML: %w", err)
+	}
+	return json.Marshal(value)
+}
+
+// isFixture reports whether a file name is a JSON or YAML fixture rather than a sidecar
+func isFixture(name string) bool {
+	lower := strings.ToLower(name)
+	for _, suffix := range metaSuffixes {
+		if strings.HasSuffix(lower, suffix) {
+			return false
+		}
+	}
+
+	switch filepath.Ext(lower) {
+	case ".json", ".yaml", ".yml":
+		return !strings.HasPrefix(name, ".")
+	}
+	return false
+}
+
+// findMeta returns the path of the sidecar of a fixture, or "" when it has none
+func findMeta(path string) string {
+	base := strings.TrimSuffix(path, filepath.Ext(path))
+	for _, suffix := range metaSuffixes {
+		if _, err := os.Stat(base + suffix); err == nil {
+			return base + suffix
+		}
+	}
+	return ""
+}
+
+func methodOrAny(method string) string {
+	if method == "" {
+		return "*"
+	}
+	return method
+}
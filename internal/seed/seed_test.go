@@ -0,0 +1,161 @@
+package seed
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"mockj-go/internal/config"
+	"mockj-go/internal/database"
+	"mockj-go/internal/events"
+	"mockj-go/internal/models"
+)
+
+func TestSeeder(t *testing.T) {
+	db, err := database.NewDatabase(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	dir := t.TempDir()
+	write := func(t *testing.T, name, content string) {
+		t.Helper()
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	write(t, "users.json", `{"name": "John"}`)
+	write(t, "users.meta.yaml", "method: get\nroute: /users/{id}\nstatus: 201\nheaders:\n  X-Seed: yes\n")
+	write(t, "nested/orders.yaml", "- id: 1\n  total: 9.5\n")
+
+	bus := events.NewBus()
+	sub := bus.Subscribe(0, nil)
+	defer sub.Close()
+	published := func() []string {
+		var types []string
+		for {
+			select {
+			case event := <-sub.C:
+				types = append(types, event.Type+" "+event.MockID)
+			default:
+				return types
+			}
+		}
+	}
+
+	seeder, err := NewSeeder(db, bus, config.SeedConfig{Dir: dir, Password: "seed123"})
+	if err != nil {
+		t.Fatalf("Failed to create seeder: %v", err)
+	}
+
+	t.Run("InitialLoad", func(t *testing.T) {
+		if err := seeder.Sync(t.Context()); err != nil {
+			t.Fatalf("Expected fixtures to load, got %v", err)
+		}
+
+		users, err := db.GetJSONByRoute(t.Context(), "GET", "/users/{id}")
+		if err != nil {
+			t.Fatalf("Expected route-bound fixture, got %v", err)
+		}
+		if users.ID != ID("users.json") || users.Status != 201 || users.Headers["X-Seed"] != "yes" {
+			t.Errorf("Unexpected seeded mock: %+v", users)
+		}
+
+		orders, err := db.GetJSON(t.Context(), ID("nested/orders.yaml"))
+		if err != nil {
+			t.Fatalf("Expected YAML fixture, got %v", err)
+		}
+		if orders.Content != `[{"id":1,"total":9.5}]` {
+			t.Errorf("Expected YAML converted to JSON, got %s", orders.Content)
+		}
+		if !orders.Expires.Equal(models.NeverExpires) {
+			t.Errorf("Expected seeded mocks never to expire, got %v", orders.Expires)
+		}
+
+		want := []string{events.Created + " " + ID("nested/orders.yaml"), events.Created + " " + ID("users.json")}
+		if got := published(); strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("Expected events %v, got %v", want, got)
+		}
+	})
+
+	t.Run("Reload", func(t *testing.T) {
+		// The same size, written within the same modification time
+		write(t, "users.json", `{"name": "Jane"}`)
+		if err := seeder.Sync(t.Context()); err != nil {
+			t.Fatalf("Expected reload, got %v", err)
+		}
+
+		users, _ := db.GetJSON(t.Context(), ID("users.json"))
+		if users.Content != `{"name": "Jane"}` {
+			t.Errorf("Expected updated content, got %s", users.Content)
+		}
+		if got := published(); len(got) != 1 || got[0] != events.Updated+" "+ID("users.json") {
+			t.Errorf("Expected one updated event, got %v", got)
+		}
+	})
+
+	t.Run("InvalidFixture", func(t *testing.T) {
+		write(t, "broken.json", `{"name": `)
+		write(t, "extra.json", `[]`)
+
+		err := seeder.Sync(t.Context())
+		if err == nil || !strings.Contains(err.Error(), "broken.json") {
+			t.Fatalf("Expected an error naming broken.json, got %v", err)
+		}
+		if _, err := db.GetJSON(t.Context(), ID("extra.json")); err != nil {
+			t.Errorf("Expected valid fixtures to load despite the broken one, got %v", err)
+		}
+		if got := published(); len(got) != 1 || got[0] != events.Created+" "+ID("extra.json") {
+			t.Errorf("Expected one created event, got %v", got)
+		}
+
+		// Unchanged invalid fixtures are not retried on every poll
+		if err := seeder.Sync(t.Context()); err != nil {
+			t.Errorf("Expected no error for unchanged fixtures, got %v", err)
+		}
+	})
+
+	t.Run("Removal", func(t *testing.T) {
+		if err := os.Remove(filepath.Join(dir, "nested", "orders.yaml")); err != nil {
+			t.Fatalf("Failed to remove fixture: %v", err)
+		}
+		if err := seeder.Sync(t.Context()); err != nil {
+			t.Fatalf("Expected sync, got %v", err)
+		}
+
+		if _, err := db.GetJSON(t.Context(), ID("nested/orders.yaml")); err == nil {
+			t.Errorf("Expected the mock of a removed fixture to be deleted")
+		}
+		if got := published(); len(got) != 1 || got[0] != events.Deleted+" "+ID("nested/orders.yaml") {
+			t.Errorf("Expected one deleted event, got %v", got)
+		}
+	})
+	t.Run("RemovedWhileStopped", func(t *testing.T) {
+		if err := os.Remove(filepath.Join(dir, "extra.json")); err != nil {
+			t.Fatalf("Failed to remove fixture: %v", err)
+		}
+
+		// A new seeder knows nothing of the fixtures loaded before the restart
+		restarted, err := NewSeeder(db, bus, config.SeedConfig{Dir: dir, Password: "seed123"})
+		if err != nil {
+			t.Fatalf("Failed to create seeder: %v", err)
+		}
+		if err := restarted.Sync(t.Context()); err == nil || !strings.Contains(err.Error(), "broken.json") {
+			t.Fatalf("Expected only broken.json to fail, got %v", err)
+		}
+
+		if _, err := db.GetJSON(t.Context(), ID("extra.json")); err == nil {
+			t.Errorf("Expected the mock of a fixture removed while stopped to be deleted")
+		}
+		if _, err := db.GetJSON(t.Context(), ID("users.json")); err != nil {
+			t.Errorf("Expected the mocks of remaining fixtures to stay, got %v", err)
+		}
+	})
+}
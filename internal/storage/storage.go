@@ -0,0 +1,173 @@
+// Package storage keeps the bytes of mock attachments, either as SQLite blobs next to
+// their metadata or as files in a local directory.
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"mockj-go/internal/config"
+	"mockj-go/internal/database"
+	"mockj-go/internal/models"
+)
+
+// Storage backends
+const (
+	SQLite = "sqlite"
+	Dir    = "dir"
+)
+
+// Store saves attachments together with their metadata in the database
+type Store interface {
+	// Create stores a new attachment and its bytes. It fails with "attachment quota exceeded"
+	// when the configured quota has no room for them.
+	Create(ctx context.Context, attachment *models.Attachment, data []byte) error
+	// Open returns the bytes of an attachment
+	Open(ctx context.Context, id string) (io.ReadSeekCloser, error)
+	// Delete removes an attachment of a mock
+	Delete(ctx context.Context, mockID, id string) error
+	// Sweep removes bytes whose attachment no longer exists, e.g. after its mock expired
+	Sweep(ctx context.Context) error
+}
+
+// New returns the store selected by the configuration
+func New(db *database.Database, cfg config.AttachmentConfig) (Store, error) {
+	if cfg.Storage != Dir {
+		return &BlobStore{db: db, quota: cfg.Quota}, nil
+	}
+
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create attachment directory: %w", err)
+	}
+	return &DirStore{db: db, dir: cfg.Dir, quota: cfg.Quota}, nil
+}
+
+// BlobStore keeps attachment bytes in the attachments table
+type BlobStore struct {
+	db    *database.Database
+	quota int64
+}
+
+func (s *BlobStore) Create(ctx context.Context, attachment *models.Attachment, data []byte) error {
+	return s.db.CreateAttachment(ctx, attachment, data, s.quota)
+}
+
+func (s *BlobStore) Open(ctx context.Context, id string) (io.ReadSeekCloser, error) {
+	data, err := s.db.GetAttachmentData(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return nopCloser{bytes.NewReader(data)}, nil
+}
+
+func (s *BlobStore) Delete(ctx context.Context, mockID, id string) error {
+	return s.db.DeleteAttachment(ctx, mockID, id)
+}
+
+// Sweep has nothing to do: blobs are deleted with their rows
+func (s *BlobStore) Sweep(ctx context.Context) error {
+	return nil
+}
+
+// DirStore keeps attachment bytes in a directory, one file per attachment named by its ID
+type DirStore struct {
+	db    *database.Database
+	dir   string
+	quota int64
+}
+
+// Create writes the bytes to a temporary file, which Sweep skips, and inserts the row before
+// moving the file into place. Sweep therefore never sees a named file without its row; the
+// attachment is only briefly listed before its file can be opened.
+func (s *DirStore) Create(ctx context.Context, attachment *models.Attachment, data []byte) error {
+	file, err := os.CreateTemp(s.dir, ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create attachment file: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write attachment file: %w", err)
+	}
+
+	if err := s.db.CreateAttachment(ctx, attachment, nil, s.quota); err != nil {
+		return err
+	}
+
+	if err := os.Rename(file.Name(), s.path(attachment.ID)); err != nil {
+		if err := s.db.DeleteAttachment(ctx, attachment.MockID, attachment.ID); err != nil {
+			slog.ErrorContext(ctx, "Failed to remove attachment without a file", "attachment_id", attachment.ID, "error", err)
+		}
+		return fmt.Errorf("failed to write attachment file: %w", err)
+	}
+	return nil
+}
+
+func (s *DirStore) Open(ctx context.Context, id string) (io.ReadSeekCloser, error) {
+	file, err := os.Open(s.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("attachment not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open attachment file: %w", err)
+	}
+	return file, nil
+}
+
+func (s *DirStore) Delete(ctx context.Context, mockID, id string) error {
+	if err := s.db.DeleteAttachment(ctx, mockID, id); err != nil {
+		return err
+	}
+	if err := os.Remove(s.path(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		// The row is gone, so the next Sweep removes the file
+		slog.ErrorContext(ctx, "Failed to remove attachment file", "attachment_id", id, "error", err)
+	}
+	return nil
+}
+
+func (s *DirStore) Sweep(ctx context.Context) error {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read attachment directory: %w", err)
+	}
+
+	var errs []error
+	for _, entry := range entries {
+		// Skip uploads in progress and files the store did not write
+		if entry.IsDir() || filepath.Ext(entry.Name()) != "" || entry.Name()[0] == '.' {
+			continue
+		}
+
+		exists, err := s.db.AttachmentExists(ctx, entry.Name())
+		if err != nil {
+			return err
+		}
+		if !exists {
+			if err := os.Remove(filepath.Join(s.dir, entry.Name())); err != nil && !errors.Is(err, os.ErrNotExist) {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// path returns the file of an attachment. IDs are generated UUIDs, never user input.
+func (s *DirStore) path(id string) string {
+	return filepath.Join(s.dir, filepath.Base(id))
+}
+
+type nopCloser struct {
+	io.ReadSeeker
+}
+
+func (nopCloser) Close() error { return nil }
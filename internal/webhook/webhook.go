@@ -0,0 +1,223 @@
+// Package webhook delivers mock lifecycle events to subscribed URLs. Deliveries are queued
+// in the database, so they survive restarts, and retried with exponential backoff.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"mockj-go/internal/config"
+	"mockj-go/internal/database"
+	"mockj-go/internal/events"
+	"mockj-go/internal/models"
+	"mockj-go/internal/outbound"
+)
+
+// Headers sent with every delivery
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+)
+
+// batchSize is the number of due deliveries attempted concurrently
+const batchSize = 16
+
+// maxDrain caps how much of a response is read, so the connection can be reused
+const maxDrain = 64 << 10
+
+// Dispatcher queues events for matching webhooks and attempts due deliveries
+type Dispatcher struct {
+	db     *database.Database
+	cfg    config.WebhookConfig
+	client *http.Client
+	wake   chan struct{}
+}
+
+func NewDispatcher(db *database.Database, cfg config.WebhookConfig) *Dispatcher {
+	return &Dispatcher{
+		db:     db,
+		cfg:    cfg,
+		client: outbound.NewClient(cfg.Timeout, cfg.AllowPrivate),
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+// Sign returns the signature header value of a payload: the hex HMAC-SHA256 of the body
+// keyed with the webhook's secret, prefixed with sha256=
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Run queues the events published on bus and delivers them, retrying every poll interval.
+// It never returns.
+func (d *Dispatcher) Run(bus *events.Bus) {
+	ctx := context.Background()
+	go d.subscribe(ctx, bus)
+
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := d.DeliverDue(ctx); err != nil {
+			slog.ErrorContext(ctx, "Failed to deliver webhooks", "error", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-d.wake:
+		}
+	}
+}
+
+// subscribe queues every published event, resubscribing after falling behind
+func (d *Dispatcher) subscribe(ctx context.Context, bus *events.Bus) {
+	var last uint64
+	for {
+		sub := bus.Subscribe(last, nil)
+		for event := range sub.C {
+			last = event.ID
+			if err := d.Enqueue(ctx, event); err != nil {
+				slog.ErrorContext(ctx, "Failed to queue webhooks", "event_id", event.ID, "error", err)
+				continue
+			}
+			select {
+			case d.wake <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+// Enqueue queues a delivery of the event for each webhook subscribed to it
+func (d *Dispatcher) Enqueue(ctx context.Context, event events.Event) error {
+	webhooks, err := d.db.ListWebhooks(ctx)
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	now := time.Now()
+	for _, webhook := range webhooks {
+		if !webhook.Matches(event.Type, event.MockID) {
+			continue
+		}
+		delivery := &models.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			EventID:       event.ID,
+			EventType:     event.Type,
+			MockID:        event.MockID,
+			Payload:       string(payload),
+			State:         models.DeliveryPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		}
+		if err := d.db.CreateDelivery(ctx, delivery); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeliverDue attempts every pending delivery whose next attempt is due
+func (d *Dispatcher) DeliverDue(ctx context.Context) error {
+	for {
+		deliveries, err := d.db.DueDeliveries(ctx, time.Now(), batchSize)
+		if err != nil {
+			return err
+		}
+
+		var wg sync.WaitGroup
+		for _, delivery := range deliveries {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				d.attempt(ctx, delivery)
+			}()
+		}
+		wg.Wait()
+
+		if len(deliveries) < batchSize {
+			return nil
+		}
+	}
+}
+
+// attempt POSTs one delivery and records the outcome, scheduling a retry on failure
+func (d *Dispatcher) attempt(ctx context.Context, delivery *models.WebhookDelivery) {
+	delivery.Attempts++
+	delivery.UpdatedAt = time.Now()
+
+	webhook, err := d.db.GetWebhook(ctx, delivery.WebhookID)
+	if err == nil {
+		delivery.ResponseStatus, err = d.post(ctx, webhook, delivery)
+	}
+
+	switch {
+	case err == nil:
+		delivery.State = models.DeliveryDelivered
+		delivery.Error = ""
+	case delivery.Attempts >= d.cfg.MaxAttempts || err.Error() == "webhook not found":
+		delivery.State = models.DeliveryFailed
+		delivery.Error = err.Error()
+	default:
+		delivery.Error = err.Error()
+		delivery.NextAttemptAt = delivery.UpdatedAt.Add(d.backoff(delivery.Attempts))
+	}
+
+	if err := d.db.UpdateDelivery(ctx, delivery); err != nil {
+		slog.ErrorContext(ctx, "Failed to record webhook delivery", "delivery_id", delivery.ID, "error", err)
+	}
+}
+
+func (d *Dispatcher) post(ctx context.Context, webhook *models.Webhook, delivery *models.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(webhook.Secret, body))
+	req.Header.Set(EventHeader, delivery.EventType)
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// Only the status is logged, or the log would reveal what the URL answered
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrain))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("receiver responded %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff returns the delay after a failed attempt: RetryBase doubled for each earlier
+// attempt, at most RetryMax
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.cfg.RetryBase
+	for i := 1; i < attempts && delay < d.cfg.RetryMax; i++ {
+		delay *= 2
+	}
+	return min(delay, d.cfg.RetryMax)
+}
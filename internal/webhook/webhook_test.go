@@ -0,0 +1,259 @@
+package webhook
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"mockj-go/internal/config"
+	"mockj-go/internal/database"
+	"mockj-go/internal/events"
+	"mockj-go/internal/models"
+)
+
+// receiver records the deliveries it accepts and fails the first failures requests
+type receiver struct {
+	mu       sync.Mutex
+	failures int
+	requests []*http.Request
+	bodies   []string
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if rc.failures > 0 {
+		rc.failures--
+		http.Error(w, "try again", http.StatusServiceUnavailable)
+		return
+	}
+	rc.requests = append(rc.requests, r)
+	rc.bodies = append(rc.bodies, string(body))
+}
+
+func testConfig() config.WebhookConfig {
+	return config.WebhookConfig{
+		Timeout:      time.Second,
+		MaxAttempts:  3,
+		RetryBase:    10 * time.Millisecond,
+		RetryMax:     20 * time.Millisecond,
+		PollInterval: 10 * time.Millisecond,
+		AllowPrivate: true, // The test receivers listen on loopback
+	}
+}
+
+func openDatabase(t *testing.T, path string) *database.Database {
+	t.Helper()
+	db, err := database.NewDatabase(path)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	return db
+}
+
+func subscribe(t *testing.T, db *database.Database, url string, eventTypes []string, mockID string) *models.Webhook {
+	t.Helper()
+	webhook := models.NewWebhook(url, eventTypes, mockID, "s3cret", "hash")
+	if err := db.CreateWebhook(t.Context(), webhook); err != nil {
+		t.Fatalf("Failed to create webhook: %v", err)
+	}
+	return webhook
+}
+
+func deliveries(t *testing.T, db *database.Database, webhookID string) []*models.WebhookDelivery {
+	t.Helper()
+	list, err := db.ListDeliveries(t.Context(), webhookID, "", 100)
+	if err != nil {
+		t.Fatalf("Failed to list deliveries: %v", err)
+	}
+	return list
+}
+
+func TestDispatcher(t *testing.T) {
+	bus := events.NewBus()
+
+	t.Run("SignedDeliveriesMatchingFilters", func(t *testing.T) {
+		db := openDatabase(t, ":memory:")
+		defer db.Close()
+		rc := &receiver{}
+		server := httptest.NewServer(rc)
+		defer server.Close()
+
+		webhook := subscribe(t, db, server.URL, []string{events.Deleted}, "a")
+		dispatcher := NewDispatcher(db, testConfig())
+		for _, event := range []events.Event{
+			bus.Publish(events.Created, "a", nil),
+			bus.Publish(events.Deleted, "b", nil),
+			bus.Publish(events.Deleted, "a", nil),
+		} {
+			if err := dispatcher.Enqueue(t.Context(), event); err != nil {
+				t.Fatalf("Failed to enqueue: %v", err)
+			}
+		}
+		if err := dispatcher.DeliverDue(t.Context()); err != nil {
+			t.Fatalf("Failed to deliver: %v", err)
+		}
+
+		if len(rc.requests) != 1 {
+			t.Fatalf("Expected one delivery, got %d", len(rc.requests))
+		}
+		req := rc.requests[0]
+		if got := req.Header.Get(SignatureHeader); got != Sign("s3cret", []byte(rc.bodies[0])) {
+			t.Errorf("Signature %q does not match the body", got)
+		}
+		if req.Header.Get(EventHeader) != events.Deleted || req.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Unexpected headers %v", req.Header)
+		}
+
+		log := deliveries(t, db, webhook.ID)
+		if len(log) != 1 || log[0].State != models.DeliveryDelivered || log[0].ResponseStatus != http.StatusOK || log[0].Attempts != 1 {
+			t.Errorf("Expected one delivered entry, got %+v", log)
+		}
+	})
+
+	t.Run("EveryMock", func(t *testing.T) {
+		db := openDatabase(t, ":memory:")
+		defer db.Close()
+		rc := &receiver{}
+		server := httptest.NewServer(rc)
+		defer server.Close()
+
+		webhook := subscribe(t, db, server.URL, nil, "")
+		dispatcher := NewDispatcher(db, testConfig())
+		for _, event := range []events.Event{
+			bus.Publish(events.Created, "a", nil),
+			bus.Publish(events.Deleted, "b", nil),
+		} {
+			if err := dispatcher.Enqueue(t.Context(), event); err != nil {
+				t.Fatalf("Failed to enqueue: %v", err)
+			}
+		}
+		if err := dispatcher.DeliverDue(t.Context()); err != nil {
+			t.Fatalf("Failed to deliver: %v", err)
+		}
+
+		if log := deliveries(t, db, webhook.ID); len(log) != 2 {
+			t.Errorf("Expected the events of both mocks, got %+v", log)
+		}
+	})
+
+	t.Run("RetriesWithBackoffUntilFailed", func(t *testing.T) {
+		db := openDatabase(t, ":memory:")
+		defer db.Close()
+		rc := &receiver{failures: 100}
+		server := httptest.NewServer(rc)
+		defer server.Close()
+
+		webhook := subscribe(t, db, server.URL, nil, "a")
+		dispatcher := NewDispatcher(db, testConfig())
+		if err := dispatcher.Enqueue(t.Context(), bus.Publish(events.Updated, "a", nil)); err != nil {
+			t.Fatalf("Failed to enqueue: %v", err)
+		}
+
+		var attempts []time.Time
+		for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); {
+			_ = dispatcher.DeliverDue(t.Context())
+			log := deliveries(t, db, webhook.ID)
+			if len(attempts) < log[0].Attempts {
+				attempts = append(attempts, log[0].UpdatedAt)
+			}
+			if log[0].State == models.DeliveryFailed {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+
+		log := deliveries(t, db, webhook.ID)
+		if log[0].State != models.DeliveryFailed || log[0].Attempts != 3 || log[0].ResponseStatus != http.StatusServiceUnavailable {
+			t.Fatalf("Expected a failed delivery after 3 attempts, got %+v", log[0])
+		}
+		if log[0].Error != "receiver responded 503 Service Unavailable" {
+			t.Errorf("Expected the last status to be logged without the body, got %q", log[0].Error)
+		}
+		if len(attempts) == 3 && attempts[2].Sub(attempts[1]) < 20*time.Millisecond {
+			t.Errorf("Expected the retry delay to double, got %s", attempts[2].Sub(attempts[1]))
+		}
+	})
+
+	t.Run("RefusesPrivateAddresses", func(t *testing.T) {
+		db := openDatabase(t, ":memory:")
+		defer db.Close()
+		rc := &receiver{}
+		server := httptest.NewServer(rc)
+		defer server.Close()
+
+		webhook := subscribe(t, db, server.URL, nil, "a")
+		cfg := testConfig()
+		cfg.AllowPrivate = false
+		dispatcher := NewDispatcher(db, cfg)
+		if err := dispatcher.Enqueue(t.Context(), bus.Publish(events.Updated, "a", nil)); err != nil {
+			t.Fatalf("Failed to enqueue: %v", err)
+		}
+		if err := dispatcher.DeliverDue(t.Context()); err != nil {
+			t.Fatalf("Failed to deliver: %v", err)
+		}
+
+		log := deliveries(t, db, webhook.ID)
+		if len(rc.requests) != 0 || !strings.Contains(log[0].Error, "not allowed") {
+			t.Errorf("Expected the loopback receiver to be refused, got %d requests and %+v", len(rc.requests), log[0])
+		}
+	})
+
+	t.Run("QueueSurvivesRestart", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "mockj.db")
+		rc := &receiver{}
+		server := httptest.NewServer(rc)
+		defer server.Close()
+
+		db := openDatabase(t, path)
+		webhook := subscribe(t, db, server.URL, nil, "a")
+		if err := NewDispatcher(db, testConfig()).Enqueue(t.Context(), bus.Publish(events.Expired, "a", nil)); err != nil {
+			t.Fatalf("Failed to enqueue: %v", err)
+		}
+		db.Close()
+
+		db = openDatabase(t, path)
+		defer db.Close()
+		if err := NewDispatcher(db, testConfig()).DeliverDue(t.Context()); err != nil {
+			t.Fatalf("Failed to deliver: %v", err)
+		}
+		if len(rc.requests) != 1 || deliveries(t, db, webhook.ID)[0].State != models.DeliveryDelivered {
+			t.Errorf("Expected the queued delivery to be sent after reopening, got %d requests", len(rc.requests))
+		}
+	})
+
+	t.Run("RunDeliversPublishedEvents", func(t *testing.T) {
+		db := openDatabase(t, ":memory:")
+		defer db.Close()
+		delivered := make(chan string, 1)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case delivered <- r.Header.Get(EventHeader):
+			default:
+			}
+		}))
+		defer server.Close()
+
+		subscribe(t, db, server.URL, nil, "a")
+		go NewDispatcher(db, testConfig()).Run(bus)
+
+		// Run subscribes asynchronously, so publish until the first delivery arrives
+		for {
+			bus.Publish(events.Created, "a", nil)
+			select {
+			case event := <-delivered:
+				if event != events.Created {
+					t.Errorf("Expected a created event, got %q", event)
+				}
+				return
+			case <-time.After(50 * time.Millisecond):
+			}
+		}
+	})
+}
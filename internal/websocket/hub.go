@@ -0,0 +1,103 @@
+package websocket
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// ConnectionInfo describes an open connection
+type ConnectionInfo struct {
+	ID               string    `json:"id"`
+	MockID           string    `json:"mockId"`
+	RemoteAddr       string    `json:"remoteAddr"`
+	Path             string    `json:"path"`
+	ConnectedAt      time.Time `json:"connectedAt"`
+	MessagesSent     int64     `json:"messagesSent"`
+	MessagesReceived int64     `json:"messagesReceived"`
+}
+
+// Hub tracks the open connections so they can be listed and closed
+type Hub struct {
+	mu    sync.Mutex
+	conns map[string]*hubEntry
+}
+
+type hubEntry struct {
+	conn *Conn
+	path string
+}
+
+func NewHub() *Hub {
+	return &Hub{conns: map[string]*hubEntry{}}
+}
+
+// Add registers a connection opened on path
+func (h *Hub) Add(conn *Conn, path string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.conns[conn.ID] = &hubEntry{conn: conn, path: path}
+}
+
+// Remove forgets a connection once it is closed
+func (h *Hub) Remove(conn *Conn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.conns, conn.ID)
+}
+
+// Get returns an open connection by ID
+func (h *Hub) Get(id string) (*Conn, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	entry, ok := h.conns[id]
+	if !ok {
+		return nil, false
+	}
+	return entry.conn, true
+}
+
+// List describes the open connections, oldest first. A non-empty mockID keeps only
+// the connections of that mock.
+func (h *Hub) List(mockID string) []ConnectionInfo {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	infos := []ConnectionInfo{}
+	for _, entry := range h.conns {
+		conn := entry.conn
+		if mockID != "" && conn.MockID != mockID {
+			continue
+		}
+		infos = append(infos, ConnectionInfo{
+			ID:               conn.ID,
+			MockID:           conn.MockID,
+			RemoteAddr:       conn.RemoteAddr,
+			Path:             entry.path,
+			ConnectedAt:      conn.ConnectedAt,
+			MessagesSent:     conn.Sent(),
+			MessagesReceived: conn.Received(),
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].ConnectedAt.Equal(infos[j].ConnectedAt) {
+			return infos[i].ID < infos[j].ID
+		}
+		return infos[i].ConnectedAt.Before(infos[j].ConnectedAt)
+	})
+	return infos
+}
+
+// CloseAll closes every open connection, for server shutdown
+func (h *Hub) CloseAll() {
+	h.mu.Lock()
+	conns := make([]*Conn, 0, len(h.conns))
+	for _, entry := range h.conns {
+		conns = append(conns, entry.conn)
+	}
+	h.mu.Unlock()
+
+	for _, conn := range conns {
+		conn.Close(CloseGoingAway, "server shutting down")
+	}
+}
@@ -0,0 +1,162 @@
+package websocket
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// clientFrame encodes a masked frame as clients send them
+func clientFrame(fin bool, opcode byte, payload []byte) []byte {
+	first := opcode
+	if fin {
+		first |= 0x80
+	}
+	frame := []byte{first, 0x80 | byte(len(payload))}
+	mask := []byte{1, 2, 3, 4}
+	frame = append(frame, mask...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	return frame
+}
+
+// readServerFrame decodes an unmasked frame as the server sends them
+func readServerFrame(t *testing.T, r *bufio.Reader) (byte, []byte) {
+	t.Helper()
+	var header [2]byte
+	if _, err := r.Read(header[:1]); err != nil {
+		t.Fatalf("Failed to read frame: %v", err)
+	}
+	if _, err := r.Read(header[1:]); err != nil {
+		t.Fatalf("Failed to read frame: %v", err)
+	}
+	payload := make([]byte, header[1]&0x7f)
+	for read := 0; read < len(payload); {
+		n, err := r.Read(payload[read:])
+		if err != nil {
+			t.Fatalf("Failed to read payload: %v", err)
+		}
+		read += n
+	}
+	return header[0] & 0x0f, payload
+}
+
+func TestConn(t *testing.T) {
+	server, client := net.Pipe()
+	defer client.Close()
+	conn := newConn(server, bufio.NewReader(server))
+	clientReader := bufio.NewReader(client)
+
+	t.Run("FragmentedMessageWithPing", func(t *testing.T) {
+		go func() {
+			client.Write(clientFrame(false, TextMessage, []byte("hel")))
+			client.Write(clientFrame(true, opPing, []byte("p")))
+			client.Write(clientFrame(true, opContinuation, []byte("lo")))
+		}()
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			if opcode, payload := readServerFrame(t, clientReader); opcode != opPong || string(payload) != "p" {
+				t.Errorf("Expected pong with the ping payload, got %d %q", opcode, payload)
+			}
+		}()
+
+		messageType, message, err := conn.ReadMessage()
+		if err != nil || messageType != TextMessage || string(message) != "hello" {
+			t.Errorf("Expected text message hello, got %d %q %v", messageType, message, err)
+		}
+		<-done
+	})
+
+	t.Run("WriteMessage", func(t *testing.T) {
+		written := make(chan error)
+		go func() { written <- conn.WriteMessage(BinaryMessage, []byte{1, 2}) }()
+		if opcode, payload := readServerFrame(t, clientReader); opcode != BinaryMessage || len(payload) != 2 {
+			t.Errorf("Expected binary frame, got %d %v", opcode, payload)
+		}
+		if err := <-written; err != nil {
+			t.Fatalf("Failed to write message: %v", err)
+		}
+		if conn.Sent() != 1 || conn.Received() != 1 {
+			t.Errorf("Expected 1 message each way, got %d sent and %d received", conn.Sent(), conn.Received())
+		}
+	})
+
+	t.Run("CloseHandshake", func(t *testing.T) {
+		go client.Write(clientFrame(true, opClose, binary.BigEndian.AppendUint16(nil, CloseNormal)))
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			opcode, payload := readServerFrame(t, clientReader)
+			if opcode != opClose || binary.BigEndian.Uint16(payload) != CloseNormal {
+				t.Errorf("Expected the close to be echoed, got %d %v", opcode, payload)
+			}
+		}()
+
+		if _, _, err := conn.ReadMessage(); !errors.Is(err, ErrClosed) {
+			t.Errorf("Expected ErrClosed, got %v", err)
+		}
+		<-done
+	})
+}
+
+func TestUnmaskedFrameRejected(t *testing.T) {
+	server, client := net.Pipe()
+	defer client.Close()
+	conn := newConn(server, bufio.NewReader(server))
+
+	go client.Write([]byte{0x81, 0x01, 'x'})
+	go func() {
+		code, _ := readServerFrame(t, bufio.NewReader(client))
+		if code != opClose {
+			t.Errorf("Expected a close frame, got %d", code)
+		}
+	}()
+
+	if _, _, err := conn.ReadMessage(); err == nil || errors.Is(err, ErrClosed) {
+		t.Errorf("Expected a protocol error, got %v", err)
+	}
+}
+
+func TestCloseDuringBlockedWrite(t *testing.T) {
+	server, client := net.Pipe()
+	defer client.Close()
+	conn := newConn(server, bufio.NewReader(server))
+
+	// The client never reads, so the write blocks while holding the write lock
+	written := make(chan error, 1)
+	go func() { written <- conn.WriteMessage(TextMessage, []byte("stuck")) }()
+	time.Sleep(10 * time.Millisecond)
+
+	closed := make(chan struct{})
+	go func() {
+		conn.Close(CloseGoingAway, "")
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("Expected Close not to wait for the blocked write")
+	}
+	if err := <-written; err == nil {
+		t.Error("Expected the blocked write to fail once the connection closed")
+	}
+}
+
+func TestUpgradeRejectsPlainRequests(t *testing.T) {
+	w := httptest.NewRecorder()
+	if _, err := Upgrade(w, httptest.NewRequest("GET", "/ws", nil), "mock"); err == nil {
+		t.Fatal("Expected an error")
+	}
+	if w.Code != http.StatusUpgradeRequired {
+		t.Errorf("Expected status %d, got %d", http.StatusUpgradeRequired, w.Code)
+	}
+}